@@ -85,15 +85,37 @@ func init() {
 
 	serveCmd.PersistentFlags().StringVar(&serverArgs.GatewaySelectorKey, "gatewaySelectorKey", "higress", "gateway resource selector label key")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.GatewaySelectorValue, "gatewaySelectorValue", "higress-gateway", "gateway resource selector label value")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.NamespaceGatewayLabel, "namespaceGatewayLabel", "", "if not empty, the value of the label of the ingress namespace replaces the gateway selector label value, "+
+		"so that the ingresses are served by the gateway deployment of the namespace")
 	serveCmd.PersistentFlags().BoolVar(&serverArgs.EnableStatus, "enableStatus", false, "enable the ingress status syncer which use to update the ip in ingress's status")
+	serveCmd.PersistentFlags().BoolVar(&serverArgs.EnableHTTP3, "enableHttp3", false, "enable http3 for all https hosts by default, which is overridden per ingress by the annotation higress.io/enable-http3, "+
+		"the gateway service should expose the udp port of the https port as well")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.TCPServicesConfigMap, "tcpServicesConfigMap", "", "if not empty, expose the tcp services defined in the configmap (namespace/name), like the tcp-services of ingress-nginx")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.UDPServicesConfigMap, "udpServicesConfigMap", "", "if not empty, expose the udp services defined in the configmap (namespace/name), like the udp-services of ingress-nginx")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.GatewayOptionsConfigMap, "gatewayOptionsConfigMap", "", "if not empty, apply the gateway listener options defined in the configmap (namespace/name), e.g. use-proxy-protocol, xff-num-trusted-hops")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.AnnotationDefaultsConfigMap, "annotationDefaultsConfigMap", "", "if not empty, apply the default values of the annotations defined in the configmap (namespace/name) to the ingresses which don't set them, e.g. ssl-redirect, load-balance")
+	serveCmd.PersistentFlags().BoolVar(&serverArgs.EnableIngressClassParams, "enableIngressClassParams", false, "if true, apply the gateway selector, default annotations and allowed annotations of the IngressClassParams referenced by the ingress classes")
+	serveCmd.PersistentFlags().BoolVar(&serverArgs.EnableGatewayAPI, "enableGatewayAPI", false, "enable the gateway api resources of the GatewayClass whose controller name is higress.io/gateway-controller, the gateway api CRDs must be installed")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WebhookOptions.Address, "webhookAddress", "", "if not empty, serve the validating admission webhook of the ingresses at the address, "+
+		"which rejects the ingresses with invalid annotations, unresolvable services or secrets, or conflicting routes")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WebhookOptions.CertFile, "webhookCertFile", "", "the serving certificate file of the validating admission webhook")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WebhookOptions.KeyFile, "webhookKeyFile", "", "the serving private key file of the validating admission webhook")
+	serveCmd.PersistentFlags().BoolVar(&serverArgs.WebhookOptions.WarnOnly, "webhookWarnOnly", false, "if true, the validating admission webhook admits the invalid ingresses with warnings instead of rejecting them")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.IngressClass, "ingressClass", "", "if not empty, only watch the ingresses have the specified class, otherwise watch all ingresses")
-	serveCmd.PersistentFlags().StringVar(&serverArgs.WatchNamespace, "watchNamespace", "", "if not empty, only wath the ingresses in the specified namespace, otherwise watch in all namespacees")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WatchNamespace, "watchNamespace", "", "if not empty, only watch the ingresses in the specified namespaces (comma separated), otherwise watch in all namespaces")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.WatchNamespaceSelector, "watchNamespaceSelector", "", "if not empty, only watch the ingresses in the namespaces selected by the label selector, e.g. higress.io/tenant=enabled, "+
+		"which are also restricted to the watchNamespace if it's set")
 	serveCmd.PersistentFlags().BoolVar(&serverArgs.Debug, "debug", serverArgs.Debug, "if true, enables more debug http api")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.HttpAddress, "httpAddress", serverArgs.HttpAddress, "the http address")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.GrpcAddress, "grpcAddress", serverArgs.GrpcAddress, "the grpc address")
 	serveCmd.PersistentFlags().BoolVar(&serverArgs.KeepStaleWhenEmpty, "keepStaleWhenEmpty", false, "keep the stale service entry when there are no endpoints in the service")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.RegistryOptions.ClusterRegistriesNamespace, "clusterRegistriesNamespace",
-		serverArgs.RegistryOptions.ClusterRegistriesNamespace, "Namespace for ConfigMap which stores clusters configs")
+		serverArgs.RegistryOptions.ClusterRegistriesNamespace, "if not empty, watch the ingresses of the remote clusters in the kubeconfig secrets labeled istio/multiCluster=true in the namespace, "+
+			"the keys of the secrets are in the form of clusterId_ingressClass_watchNamespace[_enableStatus], "+
+			"the endpoints of the remote services are resolved into the service entries of their cluster local hosts, unless a service of the same host exists in the local cluster, "+
+			"the kubeconfig needs to list and watch the services and endpoints of the remote cluster")
+	serveCmd.PersistentFlags().StringVar(&serverArgs.RegistryOptions.FileDir, "fileDir", "",
+		"if not empty, load the Ingress, Service and Secret yaml files in the directory instead of watching a Kubernetes API server")
 	serveCmd.PersistentFlags().StringVar(&serverArgs.RegistryOptions.KubeConfig, "kubeconfig", "",
 		"Use a Kubernetes configuration file instead of in-cluster configuration")
 	// RegistryOptions Controller options
@@ -15,6 +15,7 @@
 package bootstrap
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -39,15 +40,19 @@ import (
 	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/keepalive"
 	kubelib "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/multicluster"
 	"istio.io/pkg/env"
 	"istio.io/pkg/ledger"
 	"istio.io/pkg/log"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 
 	ingressconfig "github.com/alibaba/higress/pkg/ingress/config"
 	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/file"
 	"github.com/alibaba/higress/pkg/ingress/mcp"
+	"github.com/alibaba/higress/pkg/ingress/webhook"
 )
 
 type XdsOptions struct {
@@ -63,8 +68,8 @@ type XdsOptions struct {
 }
 
 // RegistryOptions provide configuration options for the configuration controller. If FileDir is set, that directory will
-// be monitored for CRD yaml files and will update the controller as those files change (This is used for testing
-// purposes). Otherwise, a CRD client is created based on the configuration.
+// be monitored for the Ingress, Service and Secret yaml files and will update the controller as those files change, so
+// that higress runs without a Kubernetes API server. Otherwise, a kube client is created based on the configuration.
 type RegistryOptions struct {
 	// If FileDir is set, the below kubernetes options are ignored
 	FileDir string
@@ -85,21 +90,34 @@ type RegistryOptions struct {
 }
 
 type ServerArgs struct {
-	Debug                bool
-	MeshId               string
-	RegionId             string
-	NativeIstio          bool
-	HttpAddress          string
-	GrpcAddress          string
-	IngressClass         string
-	EnableStatus         bool
-	WatchNamespace       string
-	GrpcKeepAliveOptions *keepalive.Options
-	XdsOptions           XdsOptions
-	RegistryOptions      RegistryOptions
-	KeepStaleWhenEmpty   bool
-	GatewaySelectorKey   string
-	GatewaySelectorValue string
+	Debug                   bool
+	MeshId                  string
+	RegionId                string
+	NativeIstio             bool
+	HttpAddress             string
+	GrpcAddress             string
+	IngressClass            string
+	EnableStatus            bool
+	WatchNamespace          string
+	WatchNamespaceSelector  string
+	GrpcKeepAliveOptions    *keepalive.Options
+	XdsOptions              XdsOptions
+	RegistryOptions         RegistryOptions
+	KeepStaleWhenEmpty      bool
+	GatewaySelectorKey      string
+	GatewaySelectorValue    string
+	NamespaceGatewayLabel   string
+	EnableHTTP3             bool
+	TCPServicesConfigMap    string
+	UDPServicesConfigMap    string
+	GatewayOptionsConfigMap string
+	// AnnotationDefaultsConfigMap holds the default values of the annotations for all the ingresses.
+	AnnotationDefaultsConfigMap string
+	EnableIngressClassParams    bool
+	EnableGatewayAPI            bool
+	// WebhookOptions configures the validating admission webhook of the ingresses, which is
+	// disabled if the address is empty.
+	WebhookOptions webhook.Options
 }
 
 type readinessProbe func() (bool, error)
@@ -110,6 +128,8 @@ type Server struct {
 	kubeClient       kubelib.Client
 	configController model.ConfigStoreCache
 	configStores     []model.ConfigStoreCache
+	ingressConfig    *ingressconfig.IngressConfig
+	webhookServer    *webhook.Server
 	httpServer       *http.Server
 	httpMux          *http.ServeMux
 	grpcServer       *grpc.Server
@@ -204,21 +224,49 @@ func (s *Server) initRegistryEventHandlers() error {
 func (s *Server) initConfigController() error {
 	ns := PodNamespace
 	options := common.Options{
-		Enable:               true,
-		ClusterId:            string(s.RegistryOptions.KubeOptions.ClusterID),
-		IngressClass:         s.IngressClass,
-		WatchNamespace:       s.WatchNamespace,
-		EnableStatus:         s.EnableStatus,
-		SystemNamespace:      ns,
-		GatewaySelectorKey:   s.GatewaySelectorKey,
-		GatewaySelectorValue: s.GatewaySelectorValue,
+		Enable:                      true,
+		ClusterId:                   string(s.RegistryOptions.KubeOptions.ClusterID),
+		IngressClass:                s.IngressClass,
+		WatchNamespace:              s.WatchNamespace,
+		WatchNamespaceSelector:      s.WatchNamespaceSelector,
+		EnableStatus:                s.EnableStatus,
+		SystemNamespace:             ns,
+		GatewaySelectorKey:          s.GatewaySelectorKey,
+		GatewaySelectorValue:        s.GatewaySelectorValue,
+		NamespaceGatewayLabel:       s.NamespaceGatewayLabel,
+		EnableHTTP3:                 s.EnableHTTP3,
+		TCPServicesConfigMap:        s.TCPServicesConfigMap,
+		UDPServicesConfigMap:        s.UDPServicesConfigMap,
+		GatewayOptionsConfigMap:     s.GatewayOptionsConfigMap,
+		AnnotationDefaultsConfigMap: s.AnnotationDefaultsConfigMap,
+		EnableIngressClassParams:    s.EnableIngressClassParams,
+		EnableGatewayAPI:            s.EnableGatewayAPI,
 	}
 	if options.ClusterId == "Kubernetes" {
 		options.ClusterId = ""
 	}
+	if _, err := labels.Parse(options.WatchNamespaceSelector); err != nil {
+		return fmt.Errorf("invalid watch namespace selector %q: %v", options.WatchNamespaceSelector, err)
+	}
 	ingressConfig := ingressconfig.NewIngressConfig(s.kubeClient, s.xdsServer, ns, options.ClusterId)
-	ingressController := ingressConfig.AddLocalCluster(options)
+	var ingressController common.IngressController
+	if s.RegistryOptions.FileDir != "" {
+		// There is no ingress status to update without the api server.
+		options.EnableStatus = false
+		ingressController = ingressConfig.AddFileCluster(s.RegistryOptions.FileDir, options)
+	} else {
+		ingressController = ingressConfig.AddLocalCluster(options)
+	}
+	s.ingressConfig = ingressConfig
+	if s.WebhookOptions.Address != "" && s.RegistryOptions.FileDir == "" {
+		s.webhookServer = webhook.NewServer(s.WebhookOptions, ingressConfig)
+	}
 	s.configStores = append(s.configStores, ingressConfig)
+	// The services of the registries declared in McpBridges are converted to service entries, which
+	// are watched through the api server.
+	if s.RegistryOptions.FileDir == "" {
+		s.configStores = append(s.configStores, ingressconfig.NewMcpBridgeConfig(s.kubeClient, ns, options.ClusterId))
+	}
 	// Wrap the config controller with a cache.
 	aggregateConfigController, err := configaggregate.MakeCache(s.configStores)
 	if err != nil {
@@ -237,6 +285,14 @@ func (s *Server) initConfigController() error {
 		go s.configController.Run(stop)
 		return nil
 	})
+
+	// The ingresses of the remote clusters are watched through the kubeconfig secrets.
+	if s.RegistryOptions.ClusterRegistriesNamespace != "" && s.RegistryOptions.FileDir == "" {
+		multiclusterController := multicluster.NewController(s.kubeClient,
+			s.RegistryOptions.ClusterRegistriesNamespace, s.RegistryOptions.KubeOptions.ClusterID)
+		multiclusterController.AddHandler(ingressConfig)
+		s.server.RunComponent(multiclusterController.Run)
+	}
 	return nil
 }
 
@@ -249,6 +305,12 @@ func (s *Server) Start(stop <-chan struct{}) error {
 	}
 	// Inform Discovery Server so that it can start accepting connections.
 	s.xdsServer.CachesSynced()
+	// The ingresses are validated against the synced caches.
+	if s.webhookServer != nil {
+		if err := s.webhookServer.Run(stop); err != nil {
+			return err
+		}
+	}
 	grpcListener, err := net.Listen("tcp", s.GrpcAddress)
 	if err != nil {
 		return err
@@ -315,11 +377,16 @@ func (s *Server) initXdsServer() error {
 	s.xdsServer.McpGenerators[gvk.WasmPlugin.String()] = &mcp.WasmpluginGenerator{Server: s.xdsServer}
 	s.xdsServer.McpGenerators[gvk.DestinationRule.String()] = &mcp.DestinationRuleGenerator{Server: s.xdsServer}
 	s.xdsServer.McpGenerators[gvk.EnvoyFilter.String()] = &mcp.EnvoyFilterGenerator{Server: s.xdsServer}
-	s.xdsServer.McpGenerators[gvk.Gateway.String()] = &mcp.GatewayGenerator{Server: s.xdsServer}
-	s.xdsServer.McpGenerators[gvk.VirtualService.String()] = &mcp.VirtualServiceGenerator{Server: s.xdsServer}
-	s.xdsServer.ProxyNeedsPush = func(proxy *model.Proxy, req *model.PushRequest) bool {
-		return true
-	}
+	// The gateways and virtual services are scoped to the gateways served by the mcp clients.
+	gatewayScopes := mcp.NewGatewayScopes(s.kubeClient, s.xdsServer, PodNamespace)
+	s.xdsServer.McpGenerators[gvk.Gateway.String()] = &mcp.GatewayGenerator{Server: s.xdsServer, Scopes: gatewayScopes}
+	s.xdsServer.McpGenerators[gvk.VirtualService.String()] = &mcp.VirtualServiceGenerator{Server: s.xdsServer, Scopes: gatewayScopes}
+	s.xdsServer.McpGenerators[gvk.ServiceEntry.String()] = &mcp.ServiceEntryGenerator{Server: s.xdsServer}
+	s.xdsServer.ProxyNeedsPush = gatewayScopes.ProxyNeedsPush
+	s.server.RunComponent(func(stop <-chan struct{}) error {
+		go gatewayScopes.Run(stop)
+		return nil
+	})
 	s.server.RunComponent(func(stop <-chan struct{}) error {
 		log.Infof("Starting ADS server")
 		s.xdsServer.Start(stop)
@@ -346,6 +413,11 @@ func (s *Server) initKubeClient() error {
 		// Already initialized by startup arguments
 		return nil
 	}
+	if s.RegistryOptions.FileDir != "" {
+		// The objects loaded from the files are held by the in-memory client.
+		s.kubeClient = file.NewClient()
+		return nil
+	}
 	kubeRestConfig, err := kubelib.DefaultRestConfig(s.RegistryOptions.KubeConfig, "", func(config *rest.Config) {
 		config.QPS = s.RegistryOptions.KubeOptions.KubernetesAPIQPS
 		config.Burst = s.RegistryOptions.KubeOptions.KubernetesAPIBurst
@@ -369,9 +441,29 @@ func (s *Server) initHttpServer() error {
 	}
 	s.xdsServer.AddDebugHandlers(s.httpMux, nil, true, nil)
 	s.httpMux.HandleFunc("/ready", s.readyHandler)
+	s.httpMux.HandleFunc("/debug/ingressConflicts", s.ingressConflictsHandler)
 	return nil
 }
 
+// ingressConflictsHandler shows the host-scoped settings conflicts among ingresses.
+func (s *Server) ingressConflictsHandler(w http.ResponseWriter, _ *http.Request) {
+	var conflicts []common.HostConflict
+	if s.ingressConfig != nil {
+		conflicts = s.ingressConfig.GetHostConflicts()
+	}
+	if conflicts == nil {
+		conflicts = []common.HostConflict{}
+	}
+	out, err := json.MarshalIndent(conflicts, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
 func (s *Server) readyHandler(w http.ResponseWriter, _ *http.Request) {
 	for name, fn := range s.readinessProbes {
 		if ready, err := fn(); !ready {
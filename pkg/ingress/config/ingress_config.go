@@ -16,8 +16,11 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	wasm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/wasm/v3"
@@ -29,32 +32,45 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	networkingutil "istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pkg/cluster"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/schema/collection"
 	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/kube"
-	listersv1 "k8s.io/client-go/listers/core/v1"
+	"istio.io/istio/pkg/kube/multicluster"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/alibaba/higress/pkg/ingress/kube/annotationdefaults"
 	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
 	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/file"
+	"github.com/alibaba/higress/pkg/ingress/kube/gatewayapi"
+	"github.com/alibaba/higress/pkg/ingress/kube/gatewayoptions"
 	"github.com/alibaba/higress/pkg/ingress/kube/ingress"
+	"github.com/alibaba/higress/pkg/ingress/kube/ingressclassparams"
 	"github.com/alibaba/higress/pkg/ingress/kube/ingressv1"
+	"github.com/alibaba/higress/pkg/ingress/kube/secret"
 	secretkube "github.com/alibaba/higress/pkg/ingress/kube/secret/kube"
+	"github.com/alibaba/higress/pkg/ingress/kube/tcpservices"
 	"github.com/alibaba/higress/pkg/ingress/kube/util"
 	. "github.com/alibaba/higress/pkg/ingress/log"
 )
 
 var (
-	_ model.ConfigStoreCache = &IngressConfig{}
-	_ model.IngressStore     = &IngressConfig{}
+	_ model.ConfigStoreCache      = &IngressConfig{}
+	_ model.IngressStore          = &IngressConfig{}
+	_ multicluster.ClusterHandler = &IngressConfig{}
 )
 
 type IngressConfig struct {
 	// key: cluster id
 	remoteIngressControllers map[string]common.IngressController
-	mutex                    sync.RWMutex
+	// key: cluster id
+	gatewayAPIControllers map[string]common.IngressController
+	// key: cluster id of the remote clusters
+	remoteEndpoints map[string]*remoteEndpoints
+	mutex           sync.RWMutex
 
 	ingressRouteCache  model.IngressRouteCollection
 	ingressDomainCache model.IngressDomainCollection
@@ -65,11 +81,53 @@ type IngressConfig struct {
 	gatewayHandlers         []model.EventHandler
 	destinationRuleHandlers []model.EventHandler
 	envoyFilterHandlers     []model.EventHandler
+	serviceEntryHandlers    []model.EventHandler
 	watchErrorHandler       cache.WatchErrorHandler
 
-	cachedEnvoyFilters []config.Config
+	// snapshotMutex serializes the conversions. The snapshot is guarded by the mutex, the lists and
+	// gets are served from it without any conversion.
+	snapshotMutex sync.Mutex
+	snapshot      *conversionSnapshot
+	// pendingChanges are the converted configs changed since the last reconciliation.
+	pendingChanges map[model.ConfigKey]model.Event
+	reconcileCh    chan struct{}
+	// generation is increased on the changes not reflected by the resource versions, e.g. the
+	// gateways of gateway api and the tcp-services and gateway options ConfigMaps.
+	generation uint64
+
+	// key: cluster id
+	eventRecorders map[string]*common.IngressEventRecorder
+
+	hostConflicts []common.HostConflict
+
+	// Keys of the host conflicts which have been reported.
+	reportedConflicts sets.Set
+
+	// Keys of the ingress problems which have been reported.
+	reportedProblems sets.Set
+
+	// Only the local cluster exposes tcp and udp services.
+	tcpServicesController tcpservices.Controller
+	tcpServicesOptions    common.Options
+
+	gatewayOptionsController gatewayoptions.Controller
+
+	annotationDefaultsController annotationdefaults.Controller
 
-	watchedSecretSet sets.Set
+	// ingressClassParamsController is nil if the IngressClassParams are not enabled.
+	ingressClassParamsController ingressclassparams.Controller
+
+	// The remote clusters inherit the gateway options of the local cluster.
+	localOptions common.Options
+	// key: id of the remote cluster in the kubeconfig secret, value: cluster id
+	remoteClusterIds map[cluster.ID]string
+
+	// The keys of the services and secrets read by the conversion, i.e. cluster/namespace/name,
+	// and the ones changed since the last conversion.
+	watchedServiceSet sets.Set
+	watchedSecretSet  sets.Set
+	changedServices   sets.Set
+	changedSecrets    sets.Set
 
 	XDSUpdater model.XDSUpdater
 
@@ -88,24 +146,37 @@ func NewIngressConfig(localKubeClient kube.Client, XDSUpdater model.XDSUpdater,
 	}
 	return &IngressConfig{
 		remoteIngressControllers: make(map[string]common.IngressController),
+		gatewayAPIControllers:    make(map[string]common.IngressController),
+		remoteEndpoints:          make(map[string]*remoteEndpoints),
 		localKubeClient:          localKubeClient,
 		XDSUpdater:               XDSUpdater,
 		annotationHandler:        annotations.NewAnnotationHandlerManager(),
 		clusterId:                clusterId,
 		globalGatewayName: namespace + "/" +
 			common.CreateConvertedName(clusterId, "global"),
-		watchedSecretSet: sets.NewSet(),
-		namespace:        namespace,
+		watchedServiceSet: sets.NewSet(),
+		watchedSecretSet:  sets.NewSet(),
+		changedServices:   sets.NewSet(),
+		changedSecrets:    sets.NewSet(),
+		eventRecorders:    make(map[string]*common.IngressEventRecorder),
+		remoteClusterIds:  make(map[cluster.ID]string),
+		reportedConflicts: sets.NewSet(),
+		reportedProblems:  sets.NewSet(),
+		namespace:         namespace,
+		reconcileCh:       make(chan struct{}, 1),
 	}
 }
 
 func (m *IngressConfig) RegisterEventHandler(kind config.GroupVersionKind, f model.EventHandler) {
 	IngressLog.Infof("register resource %v", kind)
 	if kind != gvk.VirtualService && kind != gvk.Gateway &&
-		kind != gvk.DestinationRule && kind != gvk.EnvoyFilter {
+		kind != gvk.DestinationRule && kind != gvk.EnvoyFilter &&
+		kind != gvk.ServiceEntry {
 		return
 	}
 
+	// The handlers are notified of the converted configs changed by the reconciliation, instead of
+	// the events of the ingresses.
 	switch kind {
 	case gvk.VirtualService:
 		m.virtualServiceHandlers = append(m.virtualServiceHandlers, f)
@@ -118,147 +189,249 @@ func (m *IngressConfig) RegisterEventHandler(kind config.GroupVersionKind, f mod
 
 	case gvk.EnvoyFilter:
 		m.envoyFilterHandlers = append(m.envoyFilterHandlers, f)
-	}
 
-	for _, remoteIngressController := range m.remoteIngressControllers {
-		remoteIngressController.RegisterEventHandler(kind, f)
+	case gvk.ServiceEntry:
+		m.serviceEntryHandlers = append(m.serviceEntryHandlers, f)
 	}
 }
 
 func (m *IngressConfig) AddLocalCluster(options common.Options) common.IngressController {
-	secretController := secretkube.NewController(m.localKubeClient, options)
-	secretController.AddEventHandler(m.ReflectSecretChanges)
+	ingressController, secretController := m.addLocalIngressController(options)
 
-	var ingressController common.IngressController
-	v1 := common.V1Available(m.localKubeClient)
-	if !v1 {
-		ingressController = ingress.NewController(m.localKubeClient, m.localKubeClient, options, secretController)
-	} else {
-		ingressController = ingressv1.NewController(m.localKubeClient, m.localKubeClient, options, secretController)
+	if options.EnableGatewayAPI {
+		m.gatewayAPIControllers[options.ClusterId] = gatewayapi.NewController(m.localKubeClient, options, secretController)
 	}
 
+	if tcpServicesController := tcpservices.NewController(m.localKubeClient, options); tcpServicesController != nil {
+		tcpServicesController.AddEventHandler(m.ReflectTCPServicesChanges)
+		m.tcpServicesController = tcpServicesController
+		m.tcpServicesOptions = options
+	}
+	if gatewayOptionsController := gatewayoptions.NewController(m.localKubeClient, options); gatewayOptionsController != nil {
+		gatewayOptionsController.AddEventHandler(m.ReflectGatewayOptionsChanges)
+		m.gatewayOptionsController = gatewayOptionsController
+	}
+	if annotationDefaultsController := annotationdefaults.NewController(m.localKubeClient, options); annotationDefaultsController != nil {
+		annotationDefaultsController.AddEventHandler(m.ReflectAnnotationDefaultsChanges)
+		m.annotationDefaultsController = annotationDefaultsController
+	}
+	if options.EnableIngressClassParams {
+		if ingressClassParamsController := ingressclassparams.NewController(m.localKubeClient); ingressClassParamsController != nil {
+			ingressClassParamsController.AddEventHandler(m.ReflectIngressClassParamsChanges)
+			m.ingressClassParamsController = ingressClassParamsController
+		}
+	}
+	return ingressController
+}
+
+// AddFileCluster watches the ingresses loaded from the files in the directory, the local kube
+// client must be the in-memory client created by file.NewClient. Only the ingresses, services and
+// secrets are loaded, so the gateway api, the ingress class params and the config maps are not
+// watched without an api server.
+func (m *IngressConfig) AddFileCluster(dir string, options common.Options) common.IngressController {
+	if options.EnableGatewayAPI || options.EnableIngressClassParams {
+		IngressLog.Warnf("Gateway api and ingress class params are not supported with the files of %s, ignore them", dir)
+	}
+	ingressController, _ := m.addLocalIngressController(options)
+	ingressController = file.NewController(ingressController, m.localKubeClient.(*file.Client), dir)
 	m.remoteIngressControllers[options.ClusterId] = ingressController
 	return ingressController
 }
 
-func (m *IngressConfig) InitializeCluster(ingressController common.IngressController, stop <-chan struct{}) error {
-	for _, handler := range m.virtualServiceHandlers {
-		ingressController.RegisterEventHandler(gvk.VirtualService, handler)
+// addLocalIngressController creates the ingress controller of the local cluster.
+func (m *IngressConfig) addLocalIngressController(options common.Options) (common.IngressController, secret.Controller) {
+	secretController := secretkube.NewController(m.localKubeClient, options)
+	secretController.AddEventHandler(m.ReflectSecretChanges)
+	ingressController, eventRecorder := m.newIngressController(m.localKubeClient, options, secretController)
+
+	m.localOptions = options
+	m.remoteIngressControllers[options.ClusterId] = ingressController
+	m.eventRecorders[options.ClusterId] = eventRecorder
+	return ingressController, secretController
+}
+
+// newIngressController creates the ingress controller of the cluster, the status of the ingresses
+// is synced from the gateway of the local cluster.
+func (m *IngressConfig) newIngressController(client kube.Client, options common.Options,
+	secretController secret.Controller) (common.IngressController, *common.IngressEventRecorder) {
+	if !common.V1Available(client) {
+		return ingress.NewController(m.localKubeClient, client, options, secretController),
+			common.NewIngressEventRecorder(client.Kube(), common.IngressV1Beta1APIVersion)
 	}
-	for _, handler := range m.gatewayHandlers {
-		ingressController.RegisterEventHandler(gvk.Gateway, handler)
+	return ingressv1.NewController(m.localKubeClient, client, options, secretController),
+		common.NewIngressEventRecorder(client.Kube(), common.IngressV1APIVersion)
+}
+
+// ClusterAdded watches the ingresses of the remote cluster declared in the kubeconfig secret, the
+// id of the cluster is in the format of clusterId_ingressClass_watchNamespace[_enableStatus]. The
+// routes to the services of the remote cluster use the cluster local hosts of the services, whose
+// endpoints are resolved from the remote cluster into service entries, unless a service of the
+// same host exists in the local cluster.
+func (m *IngressConfig) ClusterAdded(cluster *multicluster.Cluster, stop <-chan struct{}) error {
+	// The local cluster is added by AddLocalCluster.
+	if cluster.Client == m.localKubeClient {
+		return nil
 	}
-	for _, handler := range m.destinationRuleHandlers {
-		ingressController.RegisterEventHandler(gvk.DestinationRule, handler)
+
+	options := common.CreateOptions(cluster.ID)
+	if !options.Enable {
+		IngressLog.Infof("Skip remote cluster %s whose id doesn't specify the ingress class and watch namespace", cluster.ID)
+		return nil
 	}
-	for _, handler := range m.envoyFilterHandlers {
-		ingressController.RegisterEventHandler(gvk.EnvoyFilter, handler)
+	m.mutex.RLock()
+	localOptions := m.localOptions
+	_, exist := m.remoteIngressControllers[options.ClusterId]
+	m.mutex.RUnlock()
+	if exist {
+		return fmt.Errorf("cluster id %s of remote cluster %s is already in use", options.ClusterId, cluster.ID)
 	}
+	options.SystemNamespace = localOptions.SystemNamespace
+	options.GatewaySelectorKey = localOptions.GatewaySelectorKey
+	options.GatewaySelectorValue = localOptions.GatewaySelectorValue
+	options.NamespaceGatewayLabel = localOptions.NamespaceGatewayLabel
+	options.WatchNamespaceSelector = localOptions.WatchNamespaceSelector
+	options.EnableHTTP3 = localOptions.EnableHTTP3
+
+	secretController := secretkube.NewController(cluster.Client, options)
+	secretController.AddEventHandler(m.ReflectSecretChanges)
+	ingressController, eventRecorder := m.newIngressController(cluster.Client, options, secretController)
+	endpoints := newRemoteEndpoints(cluster.Client, options.ClusterId, ingressController.ServiceLister(), m.onServiceChanged)
 
-	_ = ingressController.SetWatchErrorHandler(m.watchErrorHandler)
+	m.mutex.Lock()
+	m.remoteIngressControllers[options.ClusterId] = ingressController
+	m.remoteEndpoints[options.ClusterId] = endpoints
+	m.eventRecorders[options.ClusterId] = eventRecorder
+	m.remoteClusterIds[cluster.ID] = options.ClusterId
+	m.generation++
+	m.mutex.Unlock()
 
-	go ingressController.Run(stop)
+	IngressLog.Infof("Add remote cluster %s", cluster.ID)
+	// The informers of the cluster are started after the handlers are registered.
+	m.initializeController(ingressController, m.onIngressEvent, stop)
 	return nil
 }
 
-func (m *IngressConfig) List(typ config.GroupVersionKind, namespace string) ([]config.Config, error) {
-	if typ != gvk.Gateway &&
-		typ != gvk.VirtualService &&
-		typ != gvk.DestinationRule &&
-		typ != gvk.EnvoyFilter {
-		return nil, common.ErrUnsupportedOp
+// ClusterUpdated recreates the ingress controller, the previous one has been stopped.
+func (m *IngressConfig) ClusterUpdated(cluster *multicluster.Cluster, stop <-chan struct{}) error {
+	if err := m.ClusterDeleted(cluster.ID); err != nil {
+		return err
 	}
+	return m.ClusterAdded(cluster, stop)
+}
 
-	// Currently, only support list all namespaces gateways or virtualservices.
-	if namespace != "" {
-		IngressLog.Warnf("ingress store only support type %s of all namespace.", typ)
-		return nil, common.ErrUnsupportedOp
+// ClusterDeleted removes the ingress controller of the remote cluster, and pushes the configs
+// without the ingresses of the cluster.
+func (m *IngressConfig) ClusterDeleted(clusterID cluster.ID) error {
+	m.mutex.Lock()
+	clusterId, exist := m.remoteClusterIds[clusterID]
+	if exist {
+		delete(m.remoteClusterIds, clusterID)
+		delete(m.remoteIngressControllers, clusterId)
+		delete(m.remoteEndpoints, clusterId)
+		delete(m.eventRecorders, clusterId)
+		m.generation++
 	}
-
-	if typ == gvk.EnvoyFilter {
-		m.mutex.RLock()
-		defer m.mutex.RUnlock()
-		IngressLog.Infof("resource type %s, configs number %d", typ, len(m.cachedEnvoyFilters))
-		return m.cachedEnvoyFilters, nil
+	m.mutex.Unlock()
+	if !exist {
+		return nil
 	}
 
-	var configs []config.Config
-	m.mutex.RLock()
-	for _, ingressController := range m.remoteIngressControllers {
-		configs = append(configs, ingressController.List()...)
+	IngressLog.Infof("Delete remote cluster %s", clusterID)
+	for _, kind := range []config.GroupVersionKind{
+		gvk.Gateway, gvk.VirtualService, gvk.DestinationRule, gvk.EnvoyFilter, gvk.ServiceEntry,
+	} {
+		m.XDSUpdater.ConfigUpdate(&model.PushRequest{
+			Full: true,
+			ConfigsUpdated: map[model.ConfigKey]struct{}{{
+				Kind:      kind,
+				Name:      clusterId,
+				Namespace: m.namespace,
+			}: {}},
+			Reason: []model.TriggerReason{"remote-cluster-change"},
+		})
 	}
-	m.mutex.RUnlock()
+	return nil
+}
 
-	common.SortIngressByCreationTime(configs)
-	wrapperConfigs := m.createWrapperConfigs(configs)
+func (m *IngressConfig) InitializeCluster(ingressController common.IngressController, stop <-chan struct{}) error {
+	m.initializeController(ingressController, m.onIngressEvent, stop)
 
-	IngressLog.Infof("resource type %s, configs number %d", typ, len(wrapperConfigs))
-	switch typ {
-	case gvk.Gateway:
-		return m.convertGateways(wrapperConfigs), nil
-	case gvk.VirtualService:
-		return m.convertVirtualService(wrapperConfigs), nil
-	case gvk.DestinationRule:
-		return m.convertDestinationRule(wrapperConfigs), nil
+	// The gateway api controller is initialized along with the ingress controller of the same cluster.
+	for clusterId, controller := range m.remoteIngressControllers {
+		if controller != ingressController {
+			continue
+		}
+		if gatewayAPIController := m.gatewayAPIControllers[clusterId]; gatewayAPIController != nil {
+			m.initializeController(gatewayAPIController, m.onGatewayAPIEvent, stop)
+		}
 	}
 
-	return nil, nil
+	if m.tcpServicesController != nil {
+		go m.tcpServicesController.Run(stop)
+	}
+	if m.gatewayOptionsController != nil {
+		go m.gatewayOptionsController.Run(stop)
+	}
+	if m.annotationDefaultsController != nil {
+		go m.annotationDefaultsController.Run(stop)
+	}
+	if m.ingressClassParamsController != nil {
+		go m.ingressClassParamsController.Run(stop)
+	}
+	return nil
 }
 
-func (m *IngressConfig) createWrapperConfigs(configs []config.Config) []common.WrapperConfig {
-	var wrapperConfigs []common.WrapperConfig
+func (m *IngressConfig) initializeController(ingressController common.IngressController, handler model.EventHandler,
+	stop <-chan struct{}) {
+	// The controllers notify the handlers of all kinds on every event, one of them is enough.
+	ingressController.RegisterEventHandler(gvk.VirtualService, handler)
+	ingressController.RegisterEventHandler(gvk.Namespace, m.onNamespaceEvent)
+	ingressController.RegisterEventHandler(gvk.Service, m.onServiceEvent)
+
+	_ = ingressController.SetWatchErrorHandler(m.watchErrorHandler)
 
-	// Init global context
-	clusterSecretListers := map[string]listersv1.SecretLister{}
-	clusterServiceListers := map[string]listersv1.ServiceLister{}
+	go ingressController.Run(stop)
+}
+
+// ingressControllerFor returns the controller of the cluster which the config comes from.
+func (m *IngressConfig) ingressControllerFor(cfg *config.Config) common.IngressController {
+	clusterId := common.GetClusterId(cfg.Annotations)
 	m.mutex.RLock()
-	for clusterId, controller := range m.remoteIngressControllers {
-		clusterSecretListers[clusterId] = controller.SecretLister()
-		clusterServiceListers[clusterId] = controller.ServiceLister()
-	}
-	m.mutex.RUnlock()
-	globalContext := &annotations.GlobalContext{
-		WatchedSecrets:      sets.NewSet(),
-		ClusterSecretLister: clusterSecretListers,
-		ClusterServiceList:  clusterServiceListers,
+	defer m.mutex.RUnlock()
+	if gatewayapi.IsRoute(cfg.GroupVersionKind) {
+		return m.gatewayAPIControllers[clusterId]
 	}
+	return m.remoteIngressControllers[clusterId]
+}
 
-	for idx := range configs {
-		rawConfig := configs[idx]
-		annotationsConfig := &annotations.Ingress{
-			Meta: annotations.Meta{
-				Namespace:    rawConfig.Namespace,
-				Name:         rawConfig.Name,
-				RawClusterId: common.GetRawClusterId(rawConfig.Annotations),
-				ClusterId:    common.GetClusterId(rawConfig.Annotations),
-			},
-		}
-		_ = m.annotationHandler.Parse(rawConfig.Annotations, annotationsConfig, globalContext)
-		wrapperConfigs = append(wrapperConfigs, common.WrapperConfig{
-			Config:            &rawConfig,
-			AnnotationsConfig: annotationsConfig,
-		})
+func (m *IngressConfig) List(typ config.GroupVersionKind, namespace string) ([]config.Config, error) {
+	if !isConvertedKind(typ) {
+		return nil, common.ErrUnsupportedOp
 	}
 
-	m.mutex.Lock()
-	m.watchedSecretSet = globalContext.WatchedSecrets
-	m.mutex.Unlock()
+	// Currently, only support list all namespaces gateways or virtualservices.
+	if namespace != "" {
+		IngressLog.Warnf("ingress store only support type %s of all namespace.", typ)
+		return nil, common.ErrUnsupportedOp
+	}
 
-	return wrapperConfigs
+	out := m.lastSnapshot().configs(typ)
+	IngressLog.Infof("resource type %s, configs number %d", typ, len(out))
+	return out, nil
 }
 
-func (m *IngressConfig) convertGateways(configs []common.WrapperConfig) []config.Config {
+// convertGateways returns the gateways and the options recording the domains and host settings.
+func (m *IngressConfig) convertGateways(configs []common.WrapperConfig) ([]config.Config, *common.ConvertOptions) {
 	convertOptions := common.ConvertOptions{
 		IngressDomainCache: common.NewIngressDomainCache(),
 		Gateways:           map[string]*common.WrapperGateway{},
+		HostSettingsCache:  common.NewHostSettingsCache(),
 	}
 
 	for idx := range configs {
 		cfg := configs[idx]
 		clusterId := common.GetClusterId(cfg.Config.Annotations)
-		m.mutex.RLock()
-		ingressController := m.remoteIngressControllers[clusterId]
-		m.mutex.RUnlock()
+		ingressController := m.ingressControllerFor(cfg.Config)
 		if ingressController == nil {
 			continue
 		}
@@ -272,17 +445,16 @@ func (m *IngressConfig) convertGateways(configs []common.WrapperConfig) []config
 		m.annotationHandler.ApplyGateway(wrapperGateway.Gateway, wrapperGateway.WrapperConfig.AnnotationsConfig)
 	}
 
-	m.mutex.Lock()
-	m.ingressDomainCache = convertOptions.IngressDomainCache.Extract()
-	m.mutex.Unlock()
-
 	out := make([]config.Config, 0, len(convertOptions.Gateways))
 	for _, gateway := range convertOptions.Gateways {
-		cleanHost := common.CleanHost(gateway.Host)
+		scope := gateway.Scope
+		if scope == "" {
+			scope = common.CleanHost(gateway.Host)
+		}
 		out = append(out, config.Config{
 			Meta: config.Meta{
 				GroupVersionKind: gvk.Gateway,
-				Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, cleanHost),
+				Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, scope),
 				Namespace:        m.namespace,
 				Annotations: map[string]string{
 					common.ClusterIdAnnotation: gateway.ClusterId,
@@ -292,24 +464,91 @@ func (m *IngressConfig) convertGateways(configs []common.WrapperConfig) []config
 			Spec: gateway.Gateway,
 		})
 	}
-	return out
+	return out, &convertOptions
 }
 
-func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []config.Config {
+// reportHostConflicts records the conflicts for debugging, and only emits events
+// and logs for the conflicts which have not been reported before.
+func (m *IngressConfig) reportHostConflicts(conflicts []common.HostConflict) {
+	current := sets.NewSet()
+	var newConflicts []common.HostConflict
+	m.mutex.Lock()
+	for _, conflict := range conflicts {
+		key := conflict.Key()
+		current.Insert(key)
+		if !m.reportedConflicts.Contains(key) {
+			newConflicts = append(newConflicts, conflict)
+		}
+	}
+	m.hostConflicts = conflicts
+	m.reportedConflicts = current
+	recorders := make(map[string]*common.IngressEventRecorder, len(m.eventRecorders))
+	for clusterId, recorder := range m.eventRecorders {
+		recorders[clusterId] = recorder
+	}
+	m.mutex.Unlock()
+
+	for _, conflict := range newConflicts {
+		message := conflict.Message()
+		IngressLog.Warnf("%s", message)
+		for _, source := range conflict.Sources {
+			recorders[source.ClusterId].Warning(source.Ingress, common.HostSettingConflictReason, message)
+		}
+	}
+}
+
+// reportIngressProblems emits events on the ingresses for the problems which have not been
+// reported before, the problems found again after being fixed are reported again.
+func (m *IngressConfig) reportIngressProblems(problems []common.IngressProblem) {
+	current := sets.NewSet()
+	var newProblems []common.IngressProblem
+	m.mutex.Lock()
+	for _, problem := range problems {
+		key := problem.Key()
+		if current.Contains(key) {
+			continue
+		}
+		current.Insert(key)
+		if !m.reportedProblems.Contains(key) {
+			newProblems = append(newProblems, problem)
+		}
+	}
+	m.reportedProblems = current
+	recorders := make(map[string]*common.IngressEventRecorder, len(m.eventRecorders))
+	for clusterId, recorder := range m.eventRecorders {
+		recorders[clusterId] = recorder
+	}
+	m.mutex.Unlock()
+
+	for _, problem := range newProblems {
+		recorders[problem.ClusterId].Warning(problem.Ingress, common.EventReason(problem.Event), problem.Message)
+	}
+}
+
+func (m *IngressConfig) tcpServicesEntries() []*tcpservices.Entry {
+	if m.tcpServicesController == nil {
+		return nil
+	}
+	return m.tcpServicesController.Entries()
+}
+
+// convertVirtualService returns the virtual services and the options recording the routes of the hosts.
+func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig,
+	externalNames map[common.ServiceKey]*common.WrapperExternalName) ([]config.Config, *common.ConvertOptions) {
 	convertOptions := common.ConvertOptions{
 		HostAndPath2Ingress: map[string]*config.Config{},
 		IngressRouteCache:   common.NewIngressRouteCache(),
 		VirtualServices:     map[string]*common.WrapperVirtualService{},
 		HTTPRoutes:          map[string][]*common.WrapperHTTPRoute{},
+		TLSRoutes:           map[string][]*common.WrapperTLSRoute{},
+		TCPRoutes:           map[string][]*common.WrapperTCPRoute{},
 	}
 
 	// convert http route
 	for idx := range configs {
 		cfg := configs[idx]
 		clusterId := common.GetClusterId(cfg.Config.Annotations)
-		m.mutex.RLock()
-		ingressController := m.remoteIngressControllers[clusterId]
-		m.mutex.RUnlock()
+		ingressController := m.ingressControllerFor(cfg.Config)
 		if ingressController == nil {
 			continue
 		}
@@ -342,9 +581,7 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 		for idx := range configs {
 			cfg := configs[idx]
 			clusterId := common.GetClusterId(cfg.Config.Annotations)
-			m.mutex.RLock()
-			ingressController := m.remoteIngressControllers[clusterId]
-			m.mutex.RUnlock()
+			ingressController := m.ingressControllerFor(cfg.Config)
 			if ingressController == nil {
 				continue
 			}
@@ -354,6 +591,17 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 		}
 	}
 
+	// Rewrite the authority of the routes to the ExternalName services.
+	externalHosts := sets.NewSet()
+	for _, externalName := range externalNames {
+		externalHosts.Insert(externalName.Host)
+	}
+	for _, routes := range convertOptions.HTTPRoutes {
+		for _, route := range routes {
+			applyExternalNameAuthority(route.HTTPRoute, externalHosts)
+		}
+	}
+
 	// Apply annotation on virtual services
 	for _, virtualService := range convertOptions.VirtualServices {
 		m.annotationHandler.ApplyVirtualServiceHandler(virtualService.VirtualService, virtualService.WrapperConfig.AnnotationsConfig)
@@ -365,12 +613,10 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 	// Apply internal active redirect for error page.
 	m.applyInternalActiveRedirect(&convertOptions)
 
-	m.mutex.Lock()
-	m.ingressRouteCache = convertOptions.IngressRouteCache.Extract()
-	m.mutex.Unlock()
-
 	// Convert http route to virtual service
 	out := make([]config.Config, 0, len(convertOptions.HTTPRoutes))
+	// host -> virtual service
+	virtualServices := map[string]*networking.VirtualService{}
 	for host, routes := range convertOptions.HTTPRoutes {
 		if len(routes) == 0 {
 			continue
@@ -381,20 +627,22 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 		gateways := []string{m.namespace + "/" +
 			common.CreateConvertedName(m.clusterId, cleanHost),
 			common.CreateConvertedName(constants.IstioIngressGatewayName, cleanHost)}
-		if host != "*" {
-			gateways = append(gateways, m.globalGatewayName)
-		}
-
 		wrapperVS, exist := convertOptions.VirtualServices[host]
 		if !exist {
 			IngressLog.Warnf("virtual service for host %s does not exist.", host)
 		}
+
+		// The global gateway listens on port 80, so only bind it when the host is exposed on port 80.
+		if host != "*" && wrapperVS.WrapperConfig.AnnotationsConfig.ListenOnDefaultHTTPPort() {
+			gateways = append(gateways, m.globalGatewayName)
+		}
 		vs := wrapperVS.VirtualService
 		vs.Gateways = gateways
 
 		for _, route := range routes {
 			vs.Http = append(vs.Http, route.HTTPRoute)
 		}
+		virtualServices[host] = vs
 
 		firstRoute := routes[0]
 		out = append(out, config.Config{
@@ -410,18 +658,70 @@ func (m *IngressConfig) convertVirtualService(configs []common.WrapperConfig) []
 		})
 	}
 
-	// We generate some specific envoy filter here to avoid duplicated computation.
-	m.convertEnvoyFilter(&convertOptions)
+	// Append tls and tcp routes to the virtual service of the host, which is created if the host has
+	// no http routes. The tcp routes are bound to the gateway of their scope instead of the host.
+	l4VirtualService := func(host string, scope string, wrapperConfig *common.WrapperConfig, clusterId string) *networking.VirtualService {
+		key := host
+		if scope != "" {
+			key = scope
+		} else {
+			scope = common.CleanHost(host)
+		}
+		if vs, exist := virtualServices[key]; exist {
+			return vs
+		}
+
+		vs := &networking.VirtualService{
+			Hosts: []string{host},
+			Gateways: []string{m.namespace + "/" +
+				common.CreateConvertedName(m.clusterId, scope),
+				common.CreateConvertedName(constants.IstioIngressGatewayName, scope)},
+		}
+		virtualServices[key] = vs
+		out = append(out, config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: gvk.VirtualService,
+				Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, wrapperConfig.Config.Namespace, wrapperConfig.Config.Name, scope),
+				Namespace:        m.namespace,
+				Annotations: map[string]string{
+					common.ClusterIdAnnotation: clusterId,
+				},
+			},
+			Spec: vs,
+		})
+		return vs
+	}
+	for host, routes := range convertOptions.TLSRoutes {
+		for _, route := range routes {
+			vs := l4VirtualService(host, "", route.WrapperConfig, route.ClusterId)
+			vs.Tls = append(vs.Tls, route.TLSRoute)
+		}
+	}
+	for scope, routes := range convertOptions.TCPRoutes {
+		for _, route := range routes {
+			vs := l4VirtualService(route.Host, scope, route.WrapperConfig, route.ClusterId)
+			vs.Tcp = append(vs.Tcp, route.TCPRoute)
+		}
+	}
 
-	return out
+	return out, &convertOptions
 }
 
-func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions) {
+// convertEnvoyFilter converts the envoy filters from the http routes of all hosts,
+// and the gateway options which apply to the listener ports of the gateways.
+func (m *IngressConfig) convertEnvoyFilter(httpRoutes map[string][]*common.WrapperHTTPRoute, gateways []config.Config) []config.Config {
 	var envoyFilters []config.Config
 	mappings := map[string]*common.Rule{}
 
-	for _, routes := range convertOptions.HTTPRoutes {
-		for _, route := range routes {
+	// Keep the order of the rules stable, so that the unchanged filter is equal across conversions.
+	hosts := make([]string, 0, len(httpRoutes))
+	for host := range httpRoutes {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	var keys []string
+	for _, host := range hosts {
+		for _, route := range httpRoutes[host] {
 			if strings.HasSuffix(route.HTTPRoute.Name, "app-root") {
 				continue
 			}
@@ -433,6 +733,7 @@ func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions
 
 			key := auth.AuthSecret.String() + "/" + auth.AuthRealm
 			if rule, exist := mappings[key]; !exist {
+				keys = append(keys, key)
 				mappings[key] = &common.Rule{
 					Realm:       auth.AuthRealm,
 					MatchRoute:  []string{route.HTTPRoute.Name},
@@ -448,8 +749,8 @@ func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions
 	IngressLog.Infof("Found %d number of basic auth", len(mappings))
 	if len(mappings) > 0 {
 		rules := &common.BasicAuthRules{}
-		for _, rule := range mappings {
-			rules.Rules = append(rules.Rules, rule)
+		for _, key := range keys {
+			rules.Rules = append(rules.Rules, mappings[key])
 		}
 
 		basicAuth, err := constructBasicAuthEnvoyFilter(rules, m.namespace)
@@ -460,14 +761,59 @@ func (m *IngressConfig) convertEnvoyFilter(convertOptions *common.ConvertOptions
 		}
 	}
 
+	var gatewayOptions *gatewayoptions.Options
+	if m.gatewayOptionsController != nil {
+		gatewayOptions = m.gatewayOptionsController.Options()
+	}
+	gatewayOptionsFilter, err := gatewayoptions.ConvertEnvoyFilter(gatewayOptions, listenerPorts(gateways), m.namespace)
+	if err != nil {
+		IngressLog.Errorf("Construct gateway options filter error %v", err)
+	} else if gatewayOptionsFilter != nil {
+		envoyFilters = append(envoyFilters, *gatewayOptionsFilter)
+	}
+
+	tcpServicesEntries := m.tcpServicesEntries()
+	for idx, entry := range tcpServicesEntries {
+		// Avoid decoding the PROXY protocol twice on the same listener.
+		if entry.DecodeProxyProtocol && gatewayOptions.ProxyProtocolEnabled(entry.Port) {
+			copied := *entry
+			copied.DecodeProxyProtocol = false
+			tcpServicesEntries[idx] = &copied
+		}
+	}
+	tcpServices, err := tcpservices.ConvertEnvoyFilter(tcpServicesEntries, m.tcpServicesOptions, m.namespace)
+	if err != nil {
+		IngressLog.Errorf("Construct tcp services filter error %v", err)
+	} else if tcpServices != nil {
+		envoyFilters = append(envoyFilters, *tcpServices)
+	}
+
 	// TODO Support other envoy filters
 
-	m.mutex.Lock()
-	m.cachedEnvoyFilters = envoyFilters
-	m.mutex.Unlock()
+	return envoyFilters
 }
 
-func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) []config.Config {
+// listenerPorts returns the sorted ports of the servers of the gateways.
+func listenerPorts(gateways []config.Config) []uint32 {
+	seen := map[uint32]struct{}{}
+	var ports []uint32
+	for _, gateway := range gateways {
+		for _, server := range gateway.Spec.(*networking.Gateway).Servers {
+			if _, exist := seen[server.Port.Number]; exist {
+				continue
+			}
+			seen[server.Port.Number] = struct{}{}
+			ports = append(ports, server.Port.Number)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i] < ports[j]
+	})
+	return ports
+}
+
+func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig,
+	externalNames map[common.ServiceKey]*common.WrapperExternalName) []config.Config {
 	convertOptions := common.ConvertOptions{
 		Service2TrafficPolicy: map[common.ServiceKey]*common.WrapperTrafficPolicy{},
 	}
@@ -476,9 +822,7 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 	for idx := range configs {
 		cfg := configs[idx]
 		clusterId := common.GetClusterId(cfg.Config.Annotations)
-		m.mutex.RLock()
-		ingressController := m.remoteIngressControllers[clusterId]
-		m.mutex.RUnlock()
+		ingressController := m.ingressControllerFor(cfg.Config)
 		if ingressController == nil {
 			continue
 		}
@@ -486,6 +830,7 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 			IngressLog.Errorf("Convert ingress %s/%s to destination rule fail in cluster %s, err %v", cfg.Config.Namespace, cfg.Config.Name, clusterId, err)
 		}
 	}
+	common.RecordInvalidIngresses(convertOptions.InvalidIngresses)
 
 	IngressLog.Debugf("traffic policy number %d", len(convertOptions.Service2TrafficPolicy))
 
@@ -493,10 +838,36 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 		m.annotationHandler.ApplyTrafficPolicy(wrapperTrafficPolicy.TrafficPolicy, wrapperTrafficPolicy.WrapperConfig.AnnotationsConfig)
 	}
 
+	// The traffic policies of the ExternalName services apply to the external names, and the
+	// upstream tls is originated on the port 443 unless the annotations configure it.
+	for key, externalName := range externalNames {
+		if key.Port != common.HTTPSPort {
+			continue
+		}
+		wrapperTrafficPolicy, exist := convertOptions.Service2TrafficPolicy[key]
+		if !exist {
+			wrapperTrafficPolicy = &common.WrapperTrafficPolicy{
+				TrafficPolicy: &networking.TrafficPolicy_PortTrafficPolicy{
+					Port: &networking.PortSelector{
+						Number: uint32(key.Port),
+					},
+				},
+				WrapperConfig: externalName.WrapperConfig,
+			}
+			convertOptions.Service2TrafficPolicy[key] = wrapperTrafficPolicy
+		}
+		if wrapperTrafficPolicy.TrafficPolicy.Tls == nil {
+			wrapperTrafficPolicy.TrafficPolicy.Tls = common.ExternalNameTLSSettings(externalName.Host)
+		}
+	}
+
 	// Merge multi-port traffic policy per service into one destination rule.
 	destinationRules := map[string]*common.WrapperDestinationRule{}
 	for key, wrapperTrafficPolicy := range convertOptions.Service2TrafficPolicy {
 		serviceName := util.CreateServiceFQDN(key.Namespace, key.Name)
+		if externalName, exist := externalNames[key]; exist {
+			serviceName = externalName.Host
+		}
 		dr, exist := destinationRules[serviceName]
 		if !exist {
 			dr = &common.WrapperDestinationRule{
@@ -509,7 +880,8 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 				WrapperConfig: wrapperTrafficPolicy.WrapperConfig,
 				ServiceKey:    key,
 			}
-		} else {
+		} else if !hasPortTrafficPolicy(dr.DestinationRule, key.Port) {
+			// The services of the same external name may have the policies of the same port.
 			dr.DestinationRule.TrafficPolicy.PortLevelSettings = append(dr.DestinationRule.TrafficPolicy.PortLevelSettings, wrapperTrafficPolicy.TrafficPolicy)
 		}
 
@@ -531,6 +903,100 @@ func (m *IngressConfig) convertDestinationRule(configs []common.WrapperConfig) [
 	return out
 }
 
+// convertExternalNames returns the ExternalName services used as the backends.
+func (m *IngressConfig) convertExternalNames(configs []common.WrapperConfig) map[common.ServiceKey]*common.WrapperExternalName {
+	convertOptions := common.ConvertOptions{
+		Service2ExternalName: map[common.ServiceKey]*common.WrapperExternalName{},
+	}
+	for idx := range configs {
+		cfg := configs[idx]
+		clusterId := common.GetClusterId(cfg.Config.Annotations)
+		ingressController := m.ingressControllerFor(cfg.Config)
+		if ingressController == nil {
+			continue
+		}
+		if err := ingressController.ConvertServiceEntry(&convertOptions, &cfg); err != nil {
+			IngressLog.Errorf("Convert ingress %s/%s to service entry fail in cluster %s, err %v", cfg.Config.Namespace, cfg.Config.Name, clusterId, err)
+		}
+	}
+	return convertOptions.Service2ExternalName
+}
+
+// convertServiceEntry converts the external names of the ExternalName services to the dns resolved
+// service entries, one per external name.
+func (m *IngressConfig) convertServiceEntry(externalNames map[common.ServiceKey]*common.WrapperExternalName) []config.Config {
+	// host -> ports
+	hostPorts := map[string]map[uint32]bool{}
+	for key, externalName := range externalNames {
+		// The named ports are resolved against the spec of the service by the ingress controller,
+		// a zero port is left only if the backend doesn't specify any.
+		if key.Port <= 0 {
+			IngressLog.Warnf("ignore the external name service %s/%s without port", key.Namespace, key.Name)
+			continue
+		}
+		ports, exist := hostPorts[externalName.Host]
+		if !exist {
+			ports = map[uint32]bool{}
+			hostPorts[externalName.Host] = ports
+		}
+		ports[uint32(key.Port)] = true
+	}
+
+	out := make([]config.Config, 0, len(hostPorts))
+	for host, ports := range hostPorts {
+		numbers := make([]uint32, 0, len(ports))
+		for port := range ports {
+			numbers = append(numbers, port)
+		}
+		sort.Slice(numbers, func(i, j int) bool {
+			return numbers[i] < numbers[j]
+		})
+		out = append(out, config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: gvk.ServiceEntry,
+				Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, m.clusterId, "external", common.CleanHost(host)),
+				Namespace:        m.namespace,
+			},
+			Spec: common.ExternalNameServiceEntry(host, numbers),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// applyExternalNameAuthority rewrites the authority to the external name if all destinations of
+// the route are the same external name, the authority configured by the annotations takes priority.
+func applyExternalNameAuthority(route *networking.HTTPRoute, externalHosts sets.Set) {
+	if len(route.Route) == 0 || (route.Rewrite != nil && route.Rewrite.Authority != "") {
+		return
+	}
+	host := route.Route[0].Destination.GetHost()
+	if !externalHosts.Contains(host) {
+		return
+	}
+	for _, destination := range route.Route[1:] {
+		if destination.Destination.GetHost() != host {
+			return
+		}
+	}
+
+	if route.Rewrite == nil {
+		route.Rewrite = &networking.HTTPRewrite{}
+	}
+	route.Rewrite.Authority = host
+}
+
+func hasPortTrafficPolicy(destinationRule *networking.DestinationRule, port int32) bool {
+	for _, policy := range destinationRule.TrafficPolicy.PortLevelSettings {
+		if policy.Port.GetNumber() == uint32(port) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *IngressConfig) applyAppRoot(convertOptions *common.ConvertOptions) {
 	for host, wrapVS := range convertOptions.VirtualServices {
 		if wrapVS.AppRoot != "" {
@@ -618,32 +1084,27 @@ func (m *IngressConfig) applyInternalActiveRedirect(convertOptions *common.Conve
 	}
 }
 
+// ReflectSecretChanges reconverts the hosts of the ingresses watching the secret, the handlers of
+// the changed configs are notified by the reconciliation.
 func (m *IngressConfig) ReflectSecretChanges(clusterNamespacedName util.ClusterNamespacedName) {
-	var hit bool
-	m.mutex.RLock()
-	if m.watchedSecretSet.Contains(clusterNamespacedName.String()) {
-		hit = true
+	key := clusterNamespacedName.String()
+	m.mutex.Lock()
+	hit := m.watchedSecretSet.Contains(key)
+	if hit {
+		m.changedSecrets.Insert(key)
 	}
-	m.mutex.RUnlock()
+	m.mutex.Unlock()
 
 	if hit {
-		push := func(kind config.GroupVersionKind) {
-			m.XDSUpdater.ConfigUpdate(&model.PushRequest{
-				Full: true,
-				ConfigsUpdated: map[model.ConfigKey]struct{}{{
-					Kind:      kind,
-					Name:      clusterNamespacedName.Name,
-					Namespace: clusterNamespacedName.Namespace,
-				}: {}},
-				Reason: []model.TriggerReason{"auth-secret-change"},
-			})
-		}
-		push(gvk.VirtualService)
-		push(gvk.EnvoyFilter)
+		m.notifyReconcile()
 	}
 }
 
 func normalizeWeightedCluster(cache *common.IngressRouteCache, route *common.WrapperHTTPRoute) {
+	if len(route.HTTPRoute.Route) == 0 {
+		return
+	}
+
 	if len(route.HTTPRoute.Route) == 1 {
 		route.HTTPRoute.Route[0].Weight = 100
 		return
@@ -682,6 +1143,60 @@ func normalizeWeightedCluster(cache *common.IngressRouteCache, route *common.Wra
 	}
 }
 
+func (m *IngressConfig) ReflectTCPServicesChanges() {
+	m.invalidateSnapshot()
+	push := func(kind config.GroupVersionKind) {
+		m.XDSUpdater.ConfigUpdate(&model.PushRequest{
+			Full: true,
+			ConfigsUpdated: map[model.ConfigKey]struct{}{{
+				Kind:      kind,
+				Name:      tcpservices.ResourceName,
+				Namespace: m.namespace,
+			}: {}},
+			Reason: []model.TriggerReason{"tcp-services-change"},
+		})
+	}
+	push(gvk.Gateway)
+	push(gvk.VirtualService)
+	push(gvk.EnvoyFilter)
+}
+
+func (m *IngressConfig) ReflectGatewayOptionsChanges() {
+	m.invalidateSnapshot()
+	push := func(kind config.GroupVersionKind) {
+		m.XDSUpdater.ConfigUpdate(&model.PushRequest{
+			Full: true,
+			ConfigsUpdated: map[model.ConfigKey]struct{}{{
+				Kind:      kind,
+				Name:      gatewayoptions.ResourceName,
+				Namespace: m.namespace,
+			}: {}},
+			Reason: []model.TriggerReason{"gateway-options-change"},
+		})
+	}
+	push(gvk.EnvoyFilter)
+}
+
+// ReflectAnnotationDefaultsChanges reparses all the ingresses, and pushes the configs of all
+// kinds because the defaults may change any of them.
+func (m *IngressConfig) ReflectAnnotationDefaultsChanges() {
+	m.reparseIngresses("annotation-defaults-change")
+}
+
+// ReflectIngressClassParamsChanges reparses all the ingresses like the annotation defaults,
+// which are also set by the parameters of the ingress classes.
+func (m *IngressConfig) ReflectIngressClassParamsChanges() {
+	m.reparseIngresses("ingress-class-params-change")
+}
+
+func (m *IngressConfig) reparseIngresses(reason model.TriggerReason) {
+	m.invalidateSnapshot()
+	m.XDSUpdater.ConfigUpdate(&model.PushRequest{
+		Full:   true,
+		Reason: []model.TriggerReason{reason},
+	})
+}
+
 func (m *IngressConfig) applyCanaryIngresses(convertOptions *common.ConvertOptions) {
 	if len(convertOptions.CanaryIngresses) == 0 {
 		return
@@ -690,9 +1205,7 @@ func (m *IngressConfig) applyCanaryIngresses(convertOptions *common.ConvertOptio
 	IngressLog.Infof("Found %d number of canary ingresses.", len(convertOptions.CanaryIngresses))
 	for _, cfg := range convertOptions.CanaryIngresses {
 		clusterId := common.GetClusterId(cfg.Config.Annotations)
-		m.mutex.RLock()
-		ingressController := m.remoteIngressControllers[clusterId]
-		m.mutex.RUnlock()
+		ingressController := m.ingressControllerFor(cfg.Config)
 		if ingressController == nil {
 			continue
 		}
@@ -785,7 +1298,24 @@ func constructBasicAuthEnvoyFilter(rules *common.BasicAuthRules, namespace strin
 	}, nil
 }
 
-func (m *IngressConfig) Run(<-chan struct{}) {}
+// Run reconciles the conversion snapshot on the events of the controllers, the events within the
+// debounce delay are merged.
+func (m *IngressConfig) Run(stop <-chan struct{}) {
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-m.reconcileCh:
+			if debounce == nil {
+				debounce = time.After(reconcileDebounceDelay)
+			}
+		case <-debounce:
+			debounce = nil
+			m.reconcile()
+		case <-stop:
+			return
+		}
+	}
+}
 
 func (m *IngressConfig) HasSynced() bool {
 	m.mutex.RLock()
@@ -795,6 +1325,23 @@ func (m *IngressConfig) HasSynced() bool {
 			return false
 		}
 	}
+	for _, gatewayAPIController := range m.gatewayAPIControllers {
+		if !gatewayAPIController.HasSynced() {
+			return false
+		}
+	}
+	if m.tcpServicesController != nil && !m.tcpServicesController.HasSynced() {
+		return false
+	}
+	if m.gatewayOptionsController != nil && !m.gatewayOptionsController.HasSynced() {
+		return false
+	}
+	if m.annotationDefaultsController != nil && !m.annotationDefaultsController.HasSynced() {
+		return false
+	}
+	if m.ingressClassParamsController != nil && !m.ingressClassParamsController.HasSynced() {
+		return false
+	}
 
 	IngressLog.Info("Ingress config controller synced.")
 	return true
@@ -817,12 +1364,29 @@ func (m *IngressConfig) GetIngressDomains() model.IngressDomainCollection {
 	return m.ingressDomainCache
 }
 
+// GetHostConflicts returns the host-scoped settings conflicts found in the latest conversion.
+func (m *IngressConfig) GetHostConflicts() []common.HostConflict {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.hostConflicts
+}
+
 func (m *IngressConfig) Schemas() collection.Schemas {
 	return common.Schemas
 }
 
-func (m *IngressConfig) Get(config.GroupVersionKind, string, string) *config.Config {
-	return nil
+// Get returns the converted config of the name generated by List, e.g. the gateway of a host.
+func (m *IngressConfig) Get(typ config.GroupVersionKind, name, namespace string) *config.Config {
+	if !isConvertedKind(typ) {
+		return nil
+	}
+
+	cfg := m.lastSnapshot().get(typ, name, namespace)
+	if cfg == nil {
+		return nil
+	}
+	out := *cfg
+	return &out
 }
 
 func (m *IngressConfig) Create(config.Config) (revision string, err error) {
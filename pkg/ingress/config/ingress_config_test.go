@@ -15,23 +15,39 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	httppb "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/proto"
 	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3"
 	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/config/xds"
 	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/multicluster"
+	v1 "k8s.io/api/core/v1"
 	ingress "k8s.io/api/networking/v1"
 	ingressv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
 	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/file"
 	controllerv1beta1 "github.com/alibaba/higress/pkg/ingress/kube/ingress"
 	controllerv1 "github.com/alibaba/higress/pkg/ingress/kube/ingressv1"
+	secretkube "github.com/alibaba/higress/pkg/ingress/kube/secret/kube"
 )
 
 func TestNormalizeWeightedCluster(t *testing.T) {
@@ -569,7 +585,7 @@ func TestConvertGatewaysForIngress(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			result := m.convertGateways(testCase.inputConfig)
+			result, _ := m.convertGateways(testCase.inputConfig)
 			target := map[string]config.Config{}
 			for _, item := range result {
 				host := common.GetHost(item.Annotations)
@@ -580,6 +596,251 @@ func TestConvertGatewaysForIngress(t *testing.T) {
 	}
 }
 
+// TestConvertGatewaysForQUIC runs the converted configs through the gateway merge of istio, so that
+// only the https hosts enabling http3 are served over QUIC and advertise it by the alt-svc header.
+func TestConvertGatewaysForQUIC(t *testing.T) {
+	// The push context names the converted gateways after the cluster of istio, which are
+	// referenced by the converted virtual services.
+	clusterName := features.ClusterName
+	features.ClusterName = "gw-123-istio"
+	defer func() {
+		features.ClusterName = clusterName
+	}()
+
+	fake := kube.NewFakeClient()
+	options := common.Options{
+		Enable:               true,
+		ClusterId:            "ingress-v1",
+		RawClusterId:         "ingress-v1__",
+		GatewaySelectorKey:   "higress",
+		GatewaySelectorValue: "higress-gateway",
+		EnableHTTP3:          true,
+	}
+	m := NewIngressConfig(fake, nil, "wakanda", "gw-123-istio")
+	m.remoteIngressControllers = map[string]common.IngressController{
+		"ingress-v1": controllerv1.NewController(fake, fake, options, nil),
+	}
+
+	pathType := ingress.PathTypePrefix
+	newIngress := func(name, host string, http3 *annotations.HTTP3Config) common.WrapperConfig {
+		return common.WrapperConfig{
+			Config: &config.Config{
+				Meta: config.Meta{
+					Name:      name,
+					Namespace: "wakanda",
+					Annotations: map[string]string{
+						common.ClusterIdAnnotation: "ingress-v1",
+					},
+				},
+				Spec: ingress.IngressSpec{
+					TLS: []ingress.IngressTLS{
+						{
+							Hosts:      []string{host},
+							SecretName: name,
+						},
+					},
+					Rules: []ingress.IngressRule{
+						{
+							Host: host,
+							IngressRuleValue: ingress.IngressRuleValue{
+								HTTP: &ingress.HTTPIngressRuleValue{
+									Paths: []ingress.HTTPIngressPath{
+										{
+											Path:     "/",
+											PathType: &pathType,
+											Backend: ingress.IngressBackend{
+												Service: &ingress.IngressServiceBackend{
+													Name: name,
+													Port: ingress.ServiceBackendPort{Number: 80},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			AnnotationsConfig: &annotations.Ingress{
+				HTTP3: http3,
+			},
+		}
+	}
+
+	// foo.com is served over QUIC by the global switch, while bar.com disables it by annotation.
+	configs := []common.WrapperConfig{
+		newIngress("foo", "foo.com", nil),
+		newIngress("bar", "bar.com", &annotations.HTTP3Config{Enabled: false}),
+	}
+	gateways, _ := m.convertGateways(configs)
+	assert.Len(t, gateways, 2)
+	servers := map[string][]*networking.Server{}
+	for _, item := range gateways {
+		servers[common.GetHost(item.Annotations)] = item.Spec.(*networking.Gateway).Servers
+	}
+	assert.Len(t, servers["bar.com"], 2)
+	assert.Len(t, servers["foo.com"], 3)
+	https, quic := servers["foo.com"][1], servers["foo.com"][2]
+	assert.Equal(t, &networking.Server{
+		Port: &networking.Port{
+			Number:   443,
+			Protocol: "UDP",
+			Name:     "http3-443-ingress-ingress-v1-wakanda-foo-foo-com",
+		},
+		Hosts: []string{"foo.com"},
+		Tls:   https.Tls,
+	}, quic)
+
+	virtualServices, _ := m.convertVirtualService(configs, nil)
+	assert.Len(t, virtualServices, 2)
+
+	gatewayService := &model.Service{
+		Hostname: "higress-gateway.wakanda.svc.cluster.local",
+		Ports: model.PortList{
+			{Name: "http2", Port: 80, Protocol: protocol.HTTP},
+			{Name: "https", Port: 443, Protocol: protocol.HTTPS},
+			{Name: "http3", Port: 443, Protocol: protocol.UDP},
+		},
+		Attributes: model.ServiceAttributes{
+			Name:      "higress-gateway",
+			Namespace: "wakanda",
+		},
+	}
+	var instances []*model.ServiceInstance
+	for _, port := range gatewayService.Ports {
+		instances = append(instances, &model.ServiceInstance{
+			Service:     gatewayService,
+			ServicePort: port,
+			Endpoint: &model.IstioEndpoint{
+				Address:      "1.1.1.1",
+				EndpointPort: uint32(port.Port),
+			},
+		})
+	}
+	cg := v1alpha3.NewConfigGenTest(t, v1alpha3.TestOptions{
+		Configs:   append(gateways, virtualServices...),
+		Services:  []*model.Service{gatewayService},
+		Instances: instances,
+	})
+	proxy := cg.SetupProxy(&model.Proxy{
+		Type:            model.Router,
+		ConfigNamespace: "wakanda",
+		Metadata: &model.NodeMetadata{
+			Labels: map[string]string{"higress": "higress-gateway"},
+		},
+	})
+
+	merged := proxy.MergedGateway
+	if merged == nil {
+		t.Fatalf("the gateways are not merged")
+	}
+	var hosts, quicHosts []string
+	for port, servers := range merged.MergedServers {
+		for _, server := range servers.Servers {
+			if port.Number == 443 {
+				hosts = append(hosts, server.Hosts...)
+			}
+		}
+	}
+	for port, servers := range merged.MergedQUICTransportServers {
+		assert.Equal(t, uint32(443), port.Number)
+		for _, server := range servers.Servers {
+			quicHosts = append(quicHosts, server.Hosts...)
+		}
+	}
+	assert.ElementsMatch(t, []string{"foo.com", "bar.com"}, hosts)
+	assert.Equal(t, []string{"foo.com"}, quicHosts)
+	// The alt-svc header is added by the routes of the hosts instead.
+	assert.Empty(t, merged.HTTP3AdvertisingRoutes)
+
+	var quicListener *listener.Listener
+	for _, l := range cg.Listeners(proxy) {
+		if l.Name == "udp_0.0.0.0_443" {
+			quicListener = l
+		}
+	}
+	if quicListener == nil {
+		t.Fatalf("the quic listener is not built")
+	}
+	assert.Len(t, quicListener.FilterChains, 1)
+	assert.Equal(t, []string{"foo.com"}, quicListener.FilterChains[0].FilterChainMatch.ServerNames)
+
+	altSvc := map[string]string{}
+	for _, routeConfig := range cg.Routes(proxy) {
+		for _, virtualHost := range routeConfig.VirtualHosts {
+			for _, r := range virtualHost.Routes {
+				for _, header := range r.ResponseHeadersToAdd {
+					if header.Header.Key == "alt-svc" {
+						altSvc[virtualHost.Domains[0]] = header.Header.Value
+					}
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]string{"foo.com": `h3=":443"; ma=86400`}, altSvc)
+}
+
+func TestConvertGatewaysForListenPort(t *testing.T) {
+	fake := kube.NewFakeClient()
+	options := common.Options{
+		Enable:       true,
+		ClusterId:    "ingress-v1",
+		RawClusterId: "ingress-v1__",
+		EnableHTTP3:  true,
+	}
+	m := NewIngressConfig(fake, nil, "wakanda", "gw-123-istio")
+	m.remoteIngressControllers = map[string]common.IngressController{
+		"ingress-v1": controllerv1.NewController(fake, fake, options, nil),
+	}
+
+	result, _ := m.convertGateways([]common.WrapperConfig{
+		{
+			Config: &config.Config{
+				Meta: config.Meta{
+					Name:      "admin",
+					Namespace: "wakanda",
+					Annotations: map[string]string{
+						common.ClusterIdAnnotation: "ingress-v1",
+					},
+				},
+				Spec: ingress.IngressSpec{
+					TLS: []ingress.IngressTLS{
+						{
+							Hosts:      []string{"admin.com"},
+							SecretName: "admin",
+						},
+					},
+					Rules: []ingress.IngressRule{
+						{
+							Host: "admin.com",
+						},
+					},
+				},
+			},
+			AnnotationsConfig: &annotations.Ingress{
+				ListenPort: &annotations.ListenPortConfig{
+					HTTPS: []uint32{8443},
+				},
+			},
+		},
+	})
+
+	assert.Len(t, result, 1)
+	servers := result[0].Spec.(*networking.Gateway).Servers
+	assert.Len(t, servers, 2)
+	assert.Equal(t, &networking.Port{
+		Number:   8443,
+		Protocol: "HTTPS",
+		Name:     "https-8443-ingress-ingress-v1-wakanda-admin-admin-com",
+	}, servers[0].Port)
+	assert.Equal(t, &networking.Port{
+		Number:   8443,
+		Protocol: "UDP",
+		Name:     "http3-8443-ingress-ingress-v1-wakanda-admin-admin-com",
+	}, servers[1].Port)
+}
+
 func TestConstructBasicAuthEnvoyFilter(t *testing.T) {
 	rules := &common.BasicAuthRules{
 		Rules: []*common.Rule{
@@ -604,3 +865,345 @@ func TestConstructBasicAuthEnvoyFilter(t *testing.T) {
 	target := proto.Clone(pb).(*httppb.HttpFilter)
 	t.Log(target)
 }
+
+func TestConvertExternalNameService(t *testing.T) {
+	fake := kube.NewFakeClient()
+	options := common.Options{
+		Enable:       true,
+		ClusterId:    "ingress-v1",
+		RawClusterId: "ingress-v1__",
+	}
+	m := NewIngressConfig(fake, nil, "wakanda", "gw-123-istio")
+	m.remoteIngressControllers = map[string]common.IngressController{
+		"ingress-v1": controllerv1.NewController(fake, fake, options, nil),
+	}
+	_ = fake.KubeInformer().Core().V1().Services().Informer().GetIndexer().Add(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "wakanda"},
+		Spec: v1.ServiceSpec{
+			Type:         v1.ServiceTypeExternalName,
+			ExternalName: "api.example.com.",
+			Ports: []v1.ServicePort{
+				{Name: "grpc", Port: 9090},
+			},
+		},
+	})
+
+	pathType := ingress.PathTypePrefix
+	newIngress := func(name, service string, port ingress.ServiceBackendPort) common.WrapperConfig {
+		return common.WrapperConfig{
+			Config: &config.Config{
+				Meta: config.Meta{
+					Name:      name,
+					Namespace: "wakanda",
+					Annotations: map[string]string{
+						common.ClusterIdAnnotation: "ingress-v1",
+					},
+				},
+				Spec: ingress.IngressSpec{
+					Rules: []ingress.IngressRule{
+						{
+							Host: name + ".com",
+							IngressRuleValue: ingress.IngressRuleValue{
+								HTTP: &ingress.HTTPIngressRuleValue{
+									Paths: []ingress.HTTPIngressPath{
+										{
+											Path:     "/",
+											PathType: &pathType,
+											Backend: ingress.IngressBackend{
+												Service: &ingress.IngressServiceBackend{
+													Name: service,
+													Port: port,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			AnnotationsConfig: &annotations.Ingress{},
+		}
+	}
+	configs := []common.WrapperConfig{
+		newIngress("foo", "external", ingress.ServiceBackendPort{Number: 443}),
+		newIngress("bar", "external", ingress.ServiceBackendPort{Number: 80}),
+		newIngress("baz", "internal", ingress.ServiceBackendPort{Number: 443}),
+		newIngress("qux", "external", ingress.ServiceBackendPort{Name: "grpc"}),
+		newIngress("quux", "external", ingress.ServiceBackendPort{Name: "unknown"}),
+	}
+
+	externalNames := m.convertExternalNames(configs)
+	serviceEntries := m.convertServiceEntry(externalNames)
+	assert.Len(t, serviceEntries, 1)
+	assert.Equal(t, &networking.ServiceEntry{
+		Hosts:      []string{"api.example.com"},
+		Location:   networking.ServiceEntry_MESH_EXTERNAL,
+		Resolution: networking.ServiceEntry_DNS,
+		Ports: []*networking.Port{
+			{Number: 80, Protocol: "HTTP", Name: "http-80"},
+			{Number: 443, Protocol: "HTTP", Name: "http-443"},
+			{Number: 9090, Protocol: "HTTP", Name: "http-9090"},
+		},
+	}, serviceEntries[0].Spec)
+
+	destinationRules := m.convertDestinationRule(configs, externalNames)
+	assert.Len(t, destinationRules, 1)
+	assert.Equal(t, &networking.DestinationRule{
+		Host: "api.example.com",
+		TrafficPolicy: &networking.TrafficPolicy{
+			PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{
+				{
+					Port: &networking.PortSelector{Number: 443},
+					Tls: &networking.ClientTLSSettings{
+						Mode: networking.ClientTLSSettings_SIMPLE,
+						Sni:  "api.example.com",
+					},
+				},
+			},
+		},
+	}, destinationRules[0].Spec)
+
+	routes := map[string]*networking.HTTPRoute{}
+	virtualServices, _ := m.convertVirtualService(configs, externalNames)
+	for _, item := range virtualServices {
+		vs := item.Spec.(*networking.VirtualService)
+		routes[vs.Hosts[0]] = vs.Http[0]
+	}
+	assert.Equal(t, "api.example.com", routes["foo.com"].Route[0].Destination.Host)
+	assert.Equal(t, &networking.HTTPRewrite{Authority: "api.example.com"}, routes["foo.com"].Rewrite)
+	assert.Equal(t, "api.example.com", routes["bar.com"].Route[0].Destination.Host)
+	assert.Equal(t, "internal.wakanda.svc.cluster.local", routes["baz.com"].Route[0].Destination.Host)
+	assert.Nil(t, routes["baz.com"].Rewrite)
+	assert.Equal(t, &networking.PortSelector{Number: 9090}, routes["qux.com"].Route[0].Destination.Port)
+}
+
+type fakeXdsUpdater struct {
+	model.XDSUpdater
+	pushes []*model.PushRequest
+}
+
+func (f *fakeXdsUpdater) ConfigUpdate(req *model.PushRequest) {
+	f.pushes = append(f.pushes, req)
+}
+
+func TestRemoteCluster(t *testing.T) {
+	local := kube.NewFakeClient()
+	updater := &fakeXdsUpdater{}
+	m := NewIngressConfig(local, updater, "wakanda", "")
+	m.AddLocalCluster(common.Options{
+		Enable:          true,
+		SystemNamespace: "wakanda",
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// The local cluster is skipped.
+	assert.NoError(t, m.ClusterAdded(&multicluster.Cluster{ID: "Kubernetes", Client: local}, stop))
+	assert.Len(t, m.remoteIngressControllers, 1)
+
+	// The old cluster id without ingress class and watch namespace is skipped.
+	assert.NoError(t, m.ClusterAdded(&multicluster.Cluster{ID: "old", Client: kube.NewFakeClient()}, stop))
+	assert.Len(t, m.remoteIngressControllers, 1)
+
+	assert.NoError(t, m.ClusterAdded(&multicluster.Cluster{ID: "remote_higress__false", Client: kube.NewFakeClient()}, stop))
+	assert.Len(t, m.remoteIngressControllers, 2)
+	assert.NotNil(t, m.remoteIngressControllers["remote"])
+	assert.NotNil(t, m.eventRecorders["remote"])
+	assert.NotNil(t, m.remoteEndpoints["remote"])
+
+	// The cluster id is already in use.
+	assert.Error(t, m.ClusterAdded(&multicluster.Cluster{ID: "remote_nginx_", Client: kube.NewFakeClient()}, stop))
+
+	assert.NoError(t, m.ClusterUpdated(&multicluster.Cluster{ID: "remote_higress__false", Client: kube.NewFakeClient()}, stop))
+	assert.Len(t, m.remoteIngressControllers, 2)
+	assert.NotEmpty(t, updater.pushes)
+
+	updater.pushes = nil
+	assert.NoError(t, m.ClusterDeleted("remote_higress__false"))
+	assert.Len(t, m.remoteIngressControllers, 1)
+	assert.Nil(t, m.eventRecorders["remote"])
+	assert.Nil(t, m.remoteEndpoints["remote"])
+	assert.Len(t, updater.pushes, 5)
+	assert.True(t, updater.pushes[0].Full)
+
+	// The unknown cluster is ignored.
+	updater.pushes = nil
+	assert.NoError(t, m.ClusterDeleted("unknown"))
+	assert.Empty(t, updater.pushes)
+}
+
+func BenchmarkConvertIngresses(b *testing.B) {
+	fake := kube.NewFakeClient()
+	options := common.Options{
+		Enable:    true,
+		ClusterId: "ingress-v1",
+	}
+	m := NewIngressConfig(fake, nil, "wakanda", "")
+	m.remoteIngressControllers = map[string]common.IngressController{
+		"ingress-v1": controllerv1.NewController(fake, fake, options, secretkube.NewController(fake, options)),
+	}
+	// The ingresses are put into the store of the informer, so that the controller lists them
+	// without waiting for the events.
+	store := fake.KubeInformer().Networking().V1().Ingresses().Informer().GetStore()
+
+	newIngresses := func(n int) []interface{} {
+		pathType := ingress.PathTypePrefix
+		ingresses := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			name := fmt.Sprintf("ingress-%d", i)
+			ingresses = append(ingresses, &ingress.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         "wakanda",
+					ResourceVersion:   "1",
+					CreationTimestamp: metav1.NewTime(time.Unix(int64(i), 0)),
+				},
+				Spec: ingress.IngressSpec{
+					Rules: []ingress.IngressRule{{
+						Host: name + ".com",
+						IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+							Paths: []ingress.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &pathType,
+								Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+									Name: name,
+									Port: ingress.ServiceBackendPort{Number: 80},
+								}},
+							}},
+						}},
+					}},
+				},
+			})
+		}
+		return ingresses
+	}
+	// list is what the xds server does after the handlers are notified by the reconciliation.
+	list := func(b *testing.B, n int) {
+		for _, kind := range convertedKinds {
+			if _, err := m.List(kind, ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if configs, _ := m.List(gvk.VirtualService, ""); len(configs) != n {
+			b.Fatalf("expect %d virtual services, got %d", n, len(configs))
+		}
+	}
+
+	for _, n := range []int{100, 1000, 10000} {
+		ingresses := newIngresses(n)
+		if err := store.Replace(ingresses, ""); err != nil {
+			b.Fatal(err)
+		}
+		m.reconcile()
+		b.Run(fmt.Sprintf("full-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.invalidateSnapshot()
+				m.reconcile()
+				list(b, n)
+			}
+		})
+		b.Run(fmt.Sprintf("incremental-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				updated := ingresses[i%n].(*ingress.Ingress).DeepCopy()
+				updated.ResourceVersion = strconv.Itoa(i + 2)
+				if err := store.Update(updated); err != nil {
+					b.Fatal(err)
+				}
+				m.reconcile()
+				list(b, n)
+			}
+		})
+		b.Run(fmt.Sprintf("unchanged-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.reconcile()
+				list(b, n)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{Enable: true, SystemNamespace: "wakanda"})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	go m.Run(stop)
+	client.RunAndWait(stop)
+
+	_, err := client.Kube().CoreV1().Secrets("wakanda").Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "wakanda", ResourceVersion: "1"},
+		Data:       map[string][]byte{"auth": []byte("foo:bar")},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	_, err = client.Kube().CoreV1().Services("wakanda").Create(context.TODO(), &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "wakanda", ResourceVersion: "1"},
+		Spec: v1.ServiceSpec{
+			Type:         v1.ServiceTypeExternalName,
+			ExternalName: "example.com",
+			Ports:        []v1.ServicePort{{Port: 443}},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	pathType := ingress.PathTypePrefix
+	_, err = client.Kube().NetworkingV1().Ingresses("wakanda").Create(context.TODO(), &ingress.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo",
+			Namespace:       "wakanda",
+			ResourceVersion: "1",
+			Annotations: map[string]string{
+				"higress.io/auth-type":   "basic",
+				"higress.io/auth-secret": "auth",
+			},
+		},
+		Spec: ingress.IngressSpec{
+			Rules: []ingress.IngressRule{{
+				Host: "foo.com",
+				IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+					Paths: []ingress.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+							Name: "external",
+							Port: ingress.ServiceBackendPort{Number: 443},
+						}},
+					}},
+				}},
+			}},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	kinds := []config.GroupVersionKind{gvk.Gateway, gvk.VirtualService, gvk.DestinationRule, gvk.EnvoyFilter, gvk.ServiceEntry}
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		for _, kind := range kinds {
+			if configs, _ := m.List(kind, ""); len(configs) == 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("wait for the converted configs timeout")
+	}
+
+	for _, kind := range kinds {
+		t.Run(kind.Kind, func(t *testing.T) {
+			configs, err := m.List(kind, "")
+			assert.NoError(t, err)
+			for _, cfg := range configs {
+				assert.Equal(t, &cfg, m.Get(kind, cfg.Name, cfg.Namespace))
+			}
+			assert.Nil(t, m.Get(kind, configs[0].Name, "default"))
+			assert.Nil(t, m.Get(kind, "nonexistent", "wakanda"))
+		})
+	}
+
+	gateway := m.Get(gvk.Gateway, common.CreateConvertedName(constants.IstioIngressGatewayName, "foo-com"), "wakanda")
+	assert.NotNil(t, gateway)
+	assert.Equal(t, "foo.com", common.GetHost(gateway.Annotations))
+	assert.Nil(t, m.Get(gvk.Ingress, "foo", "wakanda"))
+}
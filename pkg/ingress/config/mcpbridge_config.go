@@ -0,0 +1,221 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"sync"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+	"github.com/alibaba/higress/pkg/registry/reconcile"
+)
+
+var _ model.ConfigStoreCache = &McpBridgeConfig{}
+
+var mcpBridgeSchemas = collection.SchemasFor(
+	collections.IstioNetworkingV1Alpha3Serviceentries,
+	collections.IstioNetworkingV1Alpha3Destinationrules,
+)
+
+// McpBridgeConfig converts the services of the registries declared in McpBridges to service entries,
+// and to destination rules with the version subsets of the services.
+type McpBridgeConfig struct {
+	mcpBridgeController mcpbridge.Controller
+	reconciler          *reconcile.Reconciler
+	serviceEntryCache   memory.Cache
+
+	eventHandlers map[config.GroupVersionKind][]model.EventHandler
+
+	// Serialize the status updates from the controller worker and the registry watchers.
+	statusMutex sync.Mutex
+
+	namespace string
+
+	clusterId string
+}
+
+func NewMcpBridgeConfig(localKubeClient kube.Client, namespace, clusterId string) *McpBridgeConfig {
+	if clusterId == "Kubernetes" {
+		clusterId = ""
+	}
+	m := &McpBridgeConfig{
+		mcpBridgeController: mcpbridge.NewController(localKubeClient, namespace),
+		serviceEntryCache:   memory.NewCache(),
+		eventHandlers:       map[config.GroupVersionKind][]model.EventHandler{},
+		namespace:           namespace,
+		clusterId:           clusterId,
+	}
+	m.reconciler = reconcile.NewReconciler(m.serviceEntryCache, m.onRegistryUpdate)
+	m.mcpBridgeController.AddEventHandler(m.onMcpBridgeEvent)
+	return m
+}
+
+func (m *McpBridgeConfig) onMcpBridgeEvent(name types.NamespacedName) {
+	bridge, err := m.mcpBridgeController.Get(name)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			IngressLog.Errorf("Get mcpbridge %s error %v", name, err)
+			return
+		}
+		bridge = nil
+	}
+
+	m.reconciler.Reconcile(name, bridge)
+	if bridge != nil {
+		m.updateStatus(name)
+	}
+}
+
+func (m *McpBridgeConfig) onRegistryUpdate() {
+	for _, schema := range mcpBridgeSchemas.All() {
+		kind := schema.Resource().GroupVersionKind()
+		for _, f := range m.eventHandlers[kind] {
+			f(config.Config{}, config.Config{
+				Meta: config.Meta{
+					GroupVersionKind: kind,
+					Name:             common.CreateConvertedName(m.clusterId, "mcpbridge"),
+					Namespace:        m.namespace,
+				},
+			}, model.EventUpdate)
+		}
+	}
+
+	for _, name := range m.reconciler.Bridges() {
+		m.updateStatus(name)
+	}
+}
+
+// updateStatus writes the sync status of the registries if it changes.
+func (m *McpBridgeConfig) updateStatus(name types.NamespacedName) {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+
+	bridge, err := m.mcpBridgeController.Get(name)
+	if err != nil {
+		return
+	}
+	status := mcpbridge.McpBridgeStatus{Registries: m.reconciler.Statuses(name)}
+	if reflect.DeepEqual(status, bridge.Status) {
+		return
+	}
+
+	bridge.Status = status
+	if err = m.mcpBridgeController.UpdateStatus(bridge); err != nil {
+		IngressLog.Errorf("Update status of mcpbridge %s error %v", name, err)
+	}
+}
+
+func (m *McpBridgeConfig) RegisterEventHandler(kind config.GroupVersionKind, f model.EventHandler) {
+	if _, exist := mcpBridgeSchemas.FindByGroupVersionKind(kind); !exist {
+		return
+	}
+	m.eventHandlers[kind] = append(m.eventHandlers[kind], f)
+}
+
+func (m *McpBridgeConfig) List(typ config.GroupVersionKind, namespace string) ([]config.Config, error) {
+	if typ != gvk.ServiceEntry && typ != gvk.DestinationRule {
+		return nil, common.ErrUnsupportedOp
+	}
+
+	var out []config.Config
+	hosts := map[string]string{}
+	for _, wrapper := range m.serviceEntryCache.ServiceEntries() {
+		// The registries are ordered by name for the same host.
+		if registry, exist := hosts[wrapper.Host]; exist {
+			IngressLog.Warnf("host %s of registry %s conflicts with registry %s, ignore it",
+				wrapper.Host, wrapper.Registry, registry)
+			continue
+		}
+		hosts[wrapper.Host] = wrapper.Registry
+
+		if namespace != "" && namespace != m.namespace {
+			continue
+		}
+		var spec config.Spec = wrapper.ServiceEntry
+		if typ == gvk.DestinationRule {
+			destinationRule := registry.NewDestinationRule(wrapper.Host, wrapper.ServiceEntry)
+			if destinationRule == nil {
+				continue
+			}
+			spec = destinationRule
+		}
+		out = append(out, config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: typ,
+				Name:             common.CreateConvertedName(m.clusterId, "mcpbridge", common.CleanHost(wrapper.Host)),
+				Namespace:        m.namespace,
+				Annotations: map[string]string{
+					common.ClusterIdAnnotation: m.clusterId,
+				},
+			},
+			Spec: spec,
+		})
+	}
+	return out, nil
+}
+
+func (m *McpBridgeConfig) Run(stop <-chan struct{}) {
+	m.mcpBridgeController.Run(stop)
+}
+
+func (m *McpBridgeConfig) HasSynced() bool {
+	return m.mcpBridgeController.HasSynced()
+}
+
+func (m *McpBridgeConfig) SetWatchErrorHandler(func(r *cache.Reflector, err error)) error {
+	return nil
+}
+
+func (m *McpBridgeConfig) Schemas() collection.Schemas {
+	return mcpBridgeSchemas
+}
+
+func (m *McpBridgeConfig) Get(config.GroupVersionKind, string, string) *config.Config {
+	return nil
+}
+
+func (m *McpBridgeConfig) Create(config.Config) (revision string, err error) {
+	return "", common.ErrUnsupportedOp
+}
+
+func (m *McpBridgeConfig) Update(config.Config) (newRevision string, err error) {
+	return "", common.ErrUnsupportedOp
+}
+
+func (m *McpBridgeConfig) UpdateStatus(config.Config) (newRevision string, err error) {
+	return "", common.ErrUnsupportedOp
+}
+
+func (m *McpBridgeConfig) Patch(config.Config, config.PatchFunc) (string, error) {
+	return "", common.ErrUnsupportedOp
+}
+
+func (m *McpBridgeConfig) Delete(config.GroupVersionKind, string, string, *string) error {
+	return common.ErrUnsupportedOp
+}
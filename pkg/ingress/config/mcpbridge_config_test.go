@@ -0,0 +1,125 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+)
+
+type fakeMcpBridgeController struct {
+	mcpbridge.Controller
+	bridges map[types.NamespacedName]*mcpbridge.McpBridge
+	updated []*mcpbridge.McpBridge
+}
+
+func (f *fakeMcpBridgeController) Get(name types.NamespacedName) (*mcpbridge.McpBridge, error) {
+	bridge, exist := f.bridges[name]
+	if !exist {
+		return nil, kerrors.NewNotFound(schema.GroupResource{Resource: "mcpbridges"}, name.Name)
+	}
+	copied := *bridge
+	return &copied, nil
+}
+
+func (f *fakeMcpBridgeController) UpdateStatus(bridge *mcpbridge.McpBridge) error {
+	f.updated = append(f.updated, bridge)
+	f.bridges[types.NamespacedName{Namespace: bridge.Namespace, Name: bridge.Name}] = bridge
+	return nil
+}
+
+func TestMcpBridgeConfig(t *testing.T) {
+	m := NewMcpBridgeConfig(kube.NewFakeClient(), "higress-system", "")
+	fake := &fakeMcpBridgeController{bridges: map[types.NamespacedName]*mcpbridge.McpBridge{}}
+	m.mcpBridgeController = fake
+	var pushed []config.Config
+	m.RegisterEventHandler(gvk.ServiceEntry, func(_ config.Config, curr config.Config, _ model.Event) {
+		pushed = append(pushed, curr)
+	})
+
+	name := types.NamespacedName{Namespace: "higress-system", Name: "default"}
+	bridge := &mcpbridge.McpBridge{}
+	bridge.Namespace, bridge.Name = name.Namespace, name.Name
+	bridge.Spec.Registries = []*mcpbridge.RegistryConfig{{Type: "unknown", Name: "foo"}}
+	fake.bridges[name] = bridge
+
+	m.onMcpBridgeEvent(name)
+	assert.Len(t, fake.updated, 1)
+	assert.Equal(t, []mcpbridge.RegistryStatus{
+		{Name: "foo", Type: "unknown", Phase: "Failed", Message: "unsupported registry type unknown"},
+	}, fake.updated[0].Status.Registries)
+
+	// The status is written only if it changes.
+	m.onMcpBridgeEvent(name)
+	assert.Len(t, fake.updated, 1)
+
+	a := &networking.ServiceEntry{Hosts: []string{"a.nacos"}}
+	m.serviceEntryCache.UpdateServiceEntry("higress-system/default/nacos-2", "a.nacos", &networking.ServiceEntry{})
+	m.serviceEntryCache.UpdateServiceEntry("higress-system/default/nacos-1", "a.nacos", a)
+	m.onRegistryUpdate()
+	assert.Len(t, pushed, 1)
+	assert.Equal(t, gvk.ServiceEntry, pushed[0].GroupVersionKind)
+
+	configs, err := m.List(gvk.ServiceEntry, "")
+	assert.NoError(t, err)
+	assert.Len(t, configs, 1)
+	assert.Equal(t, "mcpbridge-a-nacos", configs[0].Name)
+	assert.Equal(t, "higress-system", configs[0].Namespace)
+	assert.Same(t, a, configs[0].Spec)
+
+	configs, err = m.List(gvk.ServiceEntry, "default")
+	assert.NoError(t, err)
+	assert.Empty(t, configs)
+
+	configs, err = m.List(gvk.DestinationRule, "")
+	assert.NoError(t, err)
+	assert.Empty(t, configs)
+
+	a.Endpoints = []*networking.WorkloadEntry{
+		{Address: "1.1.1.1", Labels: map[string]string{"version": "v2"}},
+		{Address: "1.1.1.2", Labels: map[string]string{"version": "v1"}},
+		{Address: "1.1.1.3", Labels: map[string]string{"version": "v1"}},
+	}
+	configs, err = m.List(gvk.DestinationRule, "")
+	assert.NoError(t, err)
+	assert.Len(t, configs, 1)
+	assert.Equal(t, gvk.DestinationRule, configs[0].GroupVersionKind)
+	assert.Equal(t, "mcpbridge-a-nacos", configs[0].Name)
+	assert.Equal(t, &networking.DestinationRule{
+		Host: "a.nacos",
+		Subsets: []*networking.Subset{
+			{Name: "v1", Labels: map[string]string{"version": "v1"}},
+			{Name: "v2", Labels: map[string]string{"version": "v2"}},
+		},
+	}, configs[0].Spec)
+
+	_, err = m.List(gvk.VirtualService, "")
+	assert.Error(t, err)
+
+	delete(fake.bridges, name)
+	m.onMcpBridgeEvent(name)
+	assert.Empty(t, m.reconciler.Bridges())
+}
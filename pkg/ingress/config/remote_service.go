@@ -0,0 +1,201 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	kubeconv "istio.io/istio/pkg/config/kube"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	v1 "k8s.io/api/core/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// remoteEndpoints resolves the endpoints of the services of a remote cluster, which are unknown to
+// the service registry of the gateway.
+type remoteEndpoints struct {
+	services  listerv1.ServiceLister
+	endpoints listerv1.EndpointsLister
+}
+
+// newRemoteEndpoints watches the endpoints of the remote cluster, the handler is called with the
+// key of the service, i.e. cluster/namespace/name, on every change of its endpoints.
+func newRemoteEndpoints(client kube.Client, clusterId string, services listerv1.ServiceLister,
+	handler func(key string)) *remoteEndpoints {
+	endpointsInformer := client.KubeInformer().Core().V1().Endpoints()
+	endpointsInformer.Informer().AddEventHandler(controllers.LatestVersionHandlerFuncs(func(o controllers.Object) {
+		handler(util.ClusterNamespacedName{
+			NamespacedName: model.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()},
+			ClusterId:      clusterId,
+		}.String())
+	}))
+	return &remoteEndpoints{
+		services:  services,
+		endpoints: endpointsInformer.Lister(),
+	}
+}
+
+// serviceEntry returns the static service entry of the cluster local host of the service, whose
+// endpoints are the ready addresses of the service. It's nil if the service isn't found or is an
+// ExternalName service, which is resolved by the external name service entries.
+func (r *remoteEndpoints) serviceEntry(namespace, name string) *networking.ServiceEntry {
+	service, err := r.services.Services(namespace).Get(name)
+	if err != nil || service.Spec.Type == v1.ServiceTypeExternalName {
+		return nil
+	}
+	serviceEntry := &networking.ServiceEntry{
+		Hosts:      []string{util.CreateServiceFQDN(namespace, name)},
+		Location:   networking.ServiceEntry_MESH_INTERNAL,
+		Resolution: networking.ServiceEntry_STATIC,
+	}
+	// The ports of the endpoints are named after the ports of the service.
+	portNames := map[string]string{}
+	for _, port := range service.Spec.Ports {
+		protocol := kubeconv.ConvertProtocol(port.Port, port.Name, port.Protocol, port.AppProtocol)
+		portName := fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port.Port)
+		portNames[port.Name] = portName
+		serviceEntry.Ports = append(serviceEntry.Ports, &networking.Port{
+			Number:   uint32(port.Port),
+			Protocol: string(protocol),
+			Name:     portName,
+		})
+	}
+
+	endpoints, err := r.endpoints.Endpoints(namespace).Get(name)
+	if err != nil {
+		return serviceEntry
+	}
+	for _, subset := range endpoints.Subsets {
+		ports := map[string]uint32{}
+		for _, port := range subset.Ports {
+			if portName, exist := portNames[port.Name]; exist {
+				ports[portName] = uint32(port.Port)
+			}
+		}
+		if len(ports) == 0 {
+			continue
+		}
+		for _, address := range subset.Addresses {
+			serviceEntry.Endpoints = append(serviceEntry.Endpoints, &networking.WorkloadEntry{
+				Address: address.IP,
+				Ports:   ports,
+			})
+		}
+	}
+	return serviceEntry
+}
+
+// convertRemoteServiceEntry converts the services of the remote clusters referenced by the
+// ingresses to the service entries of their cluster local hosts. The services of the same namespace
+// and name in several remote clusters are merged into one service entry, and a service of the same
+// host in the local cluster takes precedence over the service entry.
+func (m *IngressConfig) convertRemoteServiceEntry(entries []*ingressEntry) []config.Config {
+	m.mutex.RLock()
+	remotes := make(map[string]*remoteEndpoints, len(m.remoteEndpoints))
+	for clusterId, remote := range m.remoteEndpoints {
+		remotes[clusterId] = remote
+	}
+	m.mutex.RUnlock()
+	if len(remotes) == 0 {
+		return nil
+	}
+
+	// namespace/name -> cluster ids
+	services := map[model.NamespacedName][]string{}
+	for _, entry := range entries {
+		if entry.classParamsError != nil {
+			continue
+		}
+		for key := range entry.services {
+			// The cluster id may contain the separator, the namespace and name can't.
+			parts := strings.Split(key, "/")
+			if len(parts) < 3 {
+				continue
+			}
+			clusterId := strings.Join(parts[:len(parts)-2], "/")
+			if _, exist := remotes[clusterId]; !exist {
+				continue
+			}
+			service := model.NamespacedName{Namespace: parts[len(parts)-2], Name: parts[len(parts)-1]}
+			services[service] = append(services[service], clusterId)
+		}
+	}
+
+	out := make([]config.Config, 0, len(services))
+	for service, clusterIds := range services {
+		sort.Strings(clusterIds)
+		var merged *networking.ServiceEntry
+		for idx, clusterId := range clusterIds {
+			if idx > 0 && clusterId == clusterIds[idx-1] {
+				continue
+			}
+			serviceEntry := remotes[clusterId].serviceEntry(service.Namespace, service.Name)
+			if serviceEntry == nil {
+				continue
+			}
+			if merged == nil {
+				merged = serviceEntry
+				continue
+			}
+			mergeServiceEntry(merged, serviceEntry)
+		}
+		if merged == nil {
+			continue
+		}
+		sort.SliceStable(merged.Endpoints, func(i, j int) bool {
+			return merged.Endpoints[i].Address < merged.Endpoints[j].Address
+		})
+		IngressLog.Debugf("Resolve %d endpoints of remote service %s/%s", len(merged.Endpoints), service.Namespace, service.Name)
+		out = append(out, config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: gvk.ServiceEntry,
+				Name:             common.CreateConvertedName(constants.IstioIngressGatewayName, m.clusterId, "remote", service.Namespace, service.Name),
+				Namespace:        m.namespace,
+			},
+			Spec: merged,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// mergeServiceEntry merges the ports and endpoints of the service of another cluster, the ports of
+// the same number are the same as they're named after the protocol and number.
+func mergeServiceEntry(dst, src *networking.ServiceEntry) {
+	numbers := map[uint32]bool{}
+	for _, port := range dst.Ports {
+		numbers[port.Number] = true
+	}
+	for _, port := range src.Ports {
+		if !numbers[port.Number] {
+			dst.Ports = append(dst.Ports, port)
+		}
+	}
+	dst.Endpoints = append(dst.Endpoints, src.Endpoints...)
+}
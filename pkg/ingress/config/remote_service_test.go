@@ -0,0 +1,124 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newFakeRemoteEndpoints(t *testing.T, clusterId string, objects ...interface{}) *remoteEndpoints {
+	client := kube.NewFakeClient()
+	endpoints := newRemoteEndpoints(client, clusterId, client.KubeInformer().Core().V1().Services().Lister(), func(string) {})
+	for _, object := range objects {
+		var err error
+		switch object.(type) {
+		case *v1.Service:
+			err = client.KubeInformer().Core().V1().Services().Informer().GetStore().Add(object)
+		case *v1.Endpoints:
+			err = client.KubeInformer().Core().V1().Endpoints().Informer().GetStore().Add(object)
+		}
+		assert.NoError(t, err)
+	}
+	return endpoints
+}
+
+func TestConvertRemoteServiceEntry(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "foo"}
+	service := &v1.Service{
+		ObjectMeta: meta,
+		Spec: v1.ServiceSpec{Ports: []v1.ServicePort{
+			{Name: "http", Port: 80},
+			{Name: "grpc", Port: 9090},
+		}},
+	}
+	ports := []v1.EndpointPort{{Name: "http", Port: 8080}, {Name: "grpc", Port: 19090}}
+
+	a := newFakeRemoteEndpoints(t, "a", service, &v1.Endpoints{
+		ObjectMeta: meta,
+		Subsets: []v1.EndpointSubset{{
+			Addresses:         []v1.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}},
+			NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.3"}},
+			Ports:             ports,
+		}},
+	})
+	b := newFakeRemoteEndpoints(t, "b", service, &v1.Endpoints{
+		ObjectMeta: meta,
+		Subsets:    []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{{IP: "10.1.0.1"}}, Ports: ports}},
+	})
+	external := newFakeRemoteEndpoints(t, "c", &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bar"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeExternalName, ExternalName: "bar.com"},
+	})
+
+	m := NewIngressConfig(kube.NewFakeClient(), nil, "wakanda", "")
+	m.remoteEndpoints = map[string]*remoteEndpoints{"a": a, "b": b, "c": external}
+	entries := []*ingressEntry{
+		// The services of the local cluster are resolved by the service registry.
+		{services: sets.NewSet("/default/local", "a/default/foo")},
+		{services: sets.NewSet("b/default/foo", "a/default/unknown", "c/default/bar")},
+		{services: sets.NewSet("b/default/skipped"), classParamsError: assert.AnError},
+	}
+
+	out := m.convertRemoteServiceEntry(entries)
+	assert.Len(t, out, 1)
+	assert.Equal(t, "istio-autogenerated-k8s-ingress-remote-default-foo", out[0].Name)
+	assert.Equal(t, "wakanda", out[0].Namespace)
+	assert.Equal(t, &networking.ServiceEntry{
+		Hosts:      []string{"foo.default.svc.cluster.local"},
+		Location:   networking.ServiceEntry_MESH_INTERNAL,
+		Resolution: networking.ServiceEntry_STATIC,
+		Ports: []*networking.Port{
+			{Number: 80, Protocol: "HTTP", Name: "http-80"},
+			{Number: 9090, Protocol: "GRPC", Name: "grpc-9090"},
+		},
+		Endpoints: []*networking.WorkloadEntry{
+			{Address: "10.0.0.1", Ports: map[string]uint32{"http-80": 8080, "grpc-9090": 19090}},
+			{Address: "10.0.0.2", Ports: map[string]uint32{"http-80": 8080, "grpc-9090": 19090}},
+			{Address: "10.1.0.1", Ports: map[string]uint32{"http-80": 8080, "grpc-9090": 19090}},
+		},
+	}, out[0].Spec)
+
+	// Without the remote clusters, nothing is converted.
+	m.remoteEndpoints = map[string]*remoteEndpoints{}
+	assert.Empty(t, m.convertRemoteServiceEntry(entries))
+}
+
+func TestRemoteEndpointsEvent(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "foo"}
+	client := kube.NewFakeClient()
+	m := NewIngressConfig(kube.NewFakeClient(), nil, "wakanda", "")
+	m.remoteEndpoints["remote"] = newRemoteEndpoints(client, "remote", client.KubeInformer().Core().V1().Services().Lister(), m.onServiceChanged)
+	m.watchedServiceSet = sets.NewSet("remote/default/foo")
+	stop := make(chan struct{})
+	defer close(stop)
+	client.RunAndWait(stop)
+
+	_, err := client.Kube().CoreV1().Endpoints("default").Create(context.TODO(), &v1.Endpoints{ObjectMeta: meta}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+		return m.changedServices.Contains("remote/default/foo")
+	}, time.Second, 10*time.Millisecond)
+}
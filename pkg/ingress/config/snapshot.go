@@ -0,0 +1,804 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/ingressclassparams"
+	"github.com/alibaba/higress/pkg/ingress/kube/tcpservices"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// reconcileDebounceDelay merges the events of the controllers arriving in a burst.
+const reconcileDebounceDelay = 100 * time.Millisecond
+
+// conversionSnapshot holds the resources converted from the same listing of the ingresses, and the
+// results per ingress and per host, so that the next conversion only reconverts the changed hosts.
+type conversionSnapshot struct {
+	key       uint64
+	cacheable bool
+	// generation covers the inputs shared by all the ingresses, the results of the snapshot are
+	// only reused if it is unchanged.
+	generation uint64
+
+	// ingresses is keyed by the kind, cluster id, namespace and name.
+	ingresses map[string]*ingressEntry
+	hosts     map[string]*hostEntry
+
+	gateways         []config.Config
+	virtualServices  []config.Config
+	destinationRules []config.Config
+	envoyFilters     []config.Config
+	serviceEntries   []config.Config
+
+	// index is keyed by the kind, name and namespace of the converted configs.
+	index map[model.ConfigKey]*config.Config
+}
+
+// convertedKinds are the kinds of the configs converted from the ingresses.
+var convertedKinds = []config.GroupVersionKind{gvk.Gateway, gvk.VirtualService, gvk.DestinationRule, gvk.EnvoyFilter, gvk.ServiceEntry}
+
+func isConvertedKind(kind config.GroupVersionKind) bool {
+	for _, convertedKind := range convertedKinds {
+		if kind == convertedKind {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *conversionSnapshot) configs(kind config.GroupVersionKind) []config.Config {
+	switch kind {
+	case gvk.Gateway:
+		return s.gateways
+	case gvk.VirtualService:
+		return s.virtualServices
+	case gvk.DestinationRule:
+		return s.destinationRules
+	case gvk.EnvoyFilter:
+		return s.envoyFilters
+	case gvk.ServiceEntry:
+		return s.serviceEntries
+	}
+	return nil
+}
+
+func (s *conversionSnapshot) buildIndex() {
+	s.index = map[model.ConfigKey]*config.Config{}
+	for _, kind := range convertedKinds {
+		configs := s.configs(kind)
+		for idx := range configs {
+			s.index[model.ConfigKey{Kind: kind, Name: configs[idx].Name, Namespace: configs[idx].Namespace}] = &configs[idx]
+		}
+	}
+}
+
+func (s *conversionSnapshot) get(kind config.GroupVersionKind, name, namespace string) *config.Config {
+	return s.index[model.ConfigKey{Kind: kind, Name: name, Namespace: namespace}]
+}
+
+// ingressEntry is an ingress with its parsed annotations.
+type ingressEntry struct {
+	config           *config.Config
+	annotations      *annotations.Ingress
+	annotationErrors []*annotations.AnnotationError
+	// classParamsError is set if the params of the ingress class can't be resolved, the ingress
+	// is not converted then.
+	classParamsError error
+	// gatewaySelector is set by the parameters of the ingress class or the label of the namespace.
+	gatewaySelector map[string]string
+	// hosts is nil if they are unknown before the conversion, e.g. for the gateway api routes.
+	hosts sets.Set
+	// services and watchedSecrets are the keys of the services and secrets read by the conversion
+	// of the ingress, which is reconverted if any of them is changed.
+	services       sets.Set
+	watchedSecrets sets.Set
+	// problems are found by the last conversion of the ingress as a whole, e.g. the empty rules.
+	problems []common.IngressProblem
+}
+
+// wrapper returns a wrapper config for a single conversion. The annotations are copied if the
+// ingress is converted to the gateways and virtual services, which may merge the annotations of
+// the ingresses sharing the same host.
+func (e *ingressEntry) wrapper(converting bool) common.WrapperConfig {
+	annotationsConfig := e.annotations
+	if converting {
+		copied := *e.annotations
+		annotationsConfig = &copied
+	}
+	return common.WrapperConfig{
+		Config:            e.config,
+		AnnotationsConfig: annotationsConfig,
+		AnnotationErrors:  e.annotationErrors,
+		GatewaySelector:   e.gatewaySelector,
+	}
+}
+
+// hostEntry holds the resources converted for a host.
+type hostEntry struct {
+	gateways        []config.Config
+	virtualServices []config.Config
+	httpRoutes      []*common.WrapperHTTPRoute
+	ingressRoutes   model.IngressRouteCollection
+	ingressDomains  model.IngressDomainCollection
+	conflicts       []common.HostConflict
+	problems        []common.IngressProblem
+}
+
+// references reports whether the ingress references any of the changed services or secrets.
+func (e *ingressEntry) references(changed changedReferences) bool {
+	return setsIntersect(e.services, changed.services) || setsIntersect(e.watchedSecrets, changed.secrets)
+}
+
+// changedReferences are the keys of the services and secrets changed since the last conversion,
+// i.e. cluster/namespace/name.
+type changedReferences struct {
+	services sets.Set
+	secrets  sets.Set
+}
+
+func (c changedReferences) empty() bool {
+	return len(c.services) == 0 && len(c.secrets) == 0
+}
+
+func ingressKey(cfg *config.Config) string {
+	return cfg.GroupVersionKind.Kind + "/" + common.GetClusterId(cfg.Annotations) + "/" + cfg.Namespace + "/" + cfg.Name
+}
+
+// snapshotKey accumulates the inputs of the conversion regardless of their order.
+type snapshotKey struct {
+	sum   uint64
+	count uint64
+	// cacheable is false if any input has no resource version, e.g. in the fake clients.
+	cacheable bool
+}
+
+func (k *snapshotKey) add(kind, clusterId, namespace, name, resourceVersion string) {
+	if resourceVersion == "" {
+		k.cacheable = false
+	}
+	h := fnv.New64a()
+	for _, part := range []string{kind, clusterId, namespace, name, resourceVersion} {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	k.sum += h.Sum64()
+	k.count++
+}
+
+func (k *snapshotKey) value(generation uint64) uint64 {
+	h := fnv.New64a()
+	for _, part := range []uint64{k.sum, k.count, generation} {
+		_, _ = h.Write([]byte(strconv.FormatUint(part, 16)))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// invalidateSnapshot makes the next conversion reconvert all the ingresses.
+func (m *IngressConfig) invalidateSnapshot() {
+	m.mutex.Lock()
+	m.generation++
+	m.mutex.Unlock()
+	m.notifyReconcile()
+}
+
+// notifyReconcile wakes up the reconciliation, the pending notification is enough if any.
+func (m *IngressConfig) notifyReconcile() {
+	select {
+	case m.reconcileCh <- struct{}{}:
+	default:
+	}
+}
+
+func (m *IngressConfig) onIngressEvent(config.Config, config.Config, model.Event) {
+	m.notifyReconcile()
+}
+
+// onServiceEvent reconverts the hosts of the ingresses referencing the service.
+func (m *IngressConfig) onServiceEvent(_ config.Config, curr config.Config, _ model.Event) {
+	m.onServiceChanged(util.ClusterNamespacedName{
+		NamespacedName: model.NamespacedName{Namespace: curr.Namespace, Name: curr.Name},
+		ClusterId:      common.GetClusterId(curr.Annotations),
+	}.String())
+}
+
+// onServiceChanged is called with the key of the service, i.e. cluster/namespace/name, when the
+// service or its endpoints in a remote cluster are changed.
+func (m *IngressConfig) onServiceChanged(key string) {
+	m.mutex.Lock()
+	hit := m.watchedServiceSet.Contains(key)
+	if hit {
+		m.changedServices.Insert(key)
+	}
+	m.mutex.Unlock()
+	if hit {
+		m.notifyReconcile()
+	}
+}
+
+// onNamespaceEvent reconverts all the ingresses, because the gateway selectors set by the labels
+// of the namespaces are not covered by the resource versions of the ingresses.
+func (m *IngressConfig) onNamespaceEvent(config.Config, config.Config, model.Event) {
+	m.invalidateSnapshot()
+}
+
+// onGatewayAPIEvent reconverts all the routes, because the gateways the routes attach to are not
+// covered by the resource versions of the routes.
+func (m *IngressConfig) onGatewayAPIEvent(config.Config, config.Config, model.Event) {
+	m.invalidateSnapshot()
+}
+
+// reconcile converts the ingresses and notifies the handlers of the converted configs changed
+// since the last reconciliation.
+func (m *IngressConfig) reconcile() {
+	m.refreshSnapshot()
+
+	m.snapshotMutex.Lock()
+	changes := m.pendingChanges
+	m.pendingChanges = nil
+	m.snapshotMutex.Unlock()
+	if len(changes) == 0 {
+		return
+	}
+
+	m.mutex.RLock()
+	handlers := map[config.GroupVersionKind][]model.EventHandler{
+		gvk.Gateway:         m.gatewayHandlers,
+		gvk.VirtualService:  m.virtualServiceHandlers,
+		gvk.DestinationRule: m.destinationRuleHandlers,
+		gvk.EnvoyFilter:     m.envoyFilterHandlers,
+		gvk.ServiceEntry:    m.serviceEntryHandlers,
+	}
+	m.mutex.RUnlock()
+
+	IngressLog.Debugf("Notify %d changed configs", len(changes))
+	for key, event := range changes {
+		cfg := config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: key.Kind,
+				Name:             key.Name,
+				Namespace:        key.Namespace,
+			},
+		}
+		for _, handler := range handlers[key.Kind] {
+			handler(cfg, cfg, event)
+		}
+	}
+}
+
+// lastSnapshot returns the snapshot of the last reconciliation, which is only refreshed by the
+// reconciliation, except that the first one is converted on demand.
+func (m *IngressConfig) lastSnapshot() *conversionSnapshot {
+	m.mutex.RLock()
+	snapshot := m.snapshot
+	m.mutex.RUnlock()
+	if snapshot != nil {
+		return snapshot
+	}
+	return m.refreshSnapshot()
+}
+
+// refreshSnapshot returns the snapshot converted last time if the resource versions of the
+// ingresses are unchanged and none of the services and secrets they reference is changed.
+// Otherwise, the hosts of the changed ingresses and the ones referencing the changed services and
+// secrets are reconverted, or all of them if the generation is changed.
+func (m *IngressConfig) refreshSnapshot() *conversionSnapshot {
+	m.snapshotMutex.Lock()
+	defer m.snapshotMutex.Unlock()
+
+	key := snapshotKey{cacheable: true}
+	var configs []config.Config
+	m.mutex.Lock()
+	generation := m.generation
+	for _, ingressController := range m.remoteIngressControllers {
+		configs = append(configs, ingressController.List()...)
+	}
+	for _, gatewayAPIController := range m.gatewayAPIControllers {
+		configs = append(configs, gatewayAPIController.List()...)
+	}
+	changed := changedReferences{services: m.changedServices, secrets: m.changedSecrets}
+	m.changedServices = sets.NewSet()
+	m.changedSecrets = sets.NewSet()
+	m.mutex.Unlock()
+
+	for _, cfg := range configs {
+		key.add(cfg.GroupVersionKind.String(), common.GetClusterId(cfg.Annotations), cfg.Namespace, cfg.Name, cfg.ResourceVersion)
+	}
+	value := key.value(generation)
+	prev := m.snapshot
+	if key.cacheable && prev != nil && prev.cacheable && prev.key == value && changed.empty() {
+		IngressLog.Debugf("Reuse the conversion snapshot of %d configs", len(configs))
+		return prev
+	}
+
+	var base *conversionSnapshot
+	if prev != nil && prev.generation == generation {
+		base = prev
+	}
+	snapshot := m.convert(configs, base, changed)
+	snapshot.key = value
+	snapshot.cacheable = key.cacheable
+	snapshot.generation = generation
+
+	m.recordChanges(prev, snapshot)
+	m.mutex.Lock()
+	m.snapshot = snapshot
+	m.mutex.Unlock()
+	return snapshot
+}
+
+// convert converts the ingresses to all kinds of the resources. If the base snapshot is given,
+// only the hosts of the ingresses changed since then, or referencing the changed services and
+// secrets, are reconverted, and the others are reused.
+func (m *IngressConfig) convert(configs []config.Config, base *conversionSnapshot, changed changedReferences) *conversionSnapshot {
+	common.SortIngressByCreationTime(configs)
+	snapshot := &conversionSnapshot{
+		ingresses: make(map[string]*ingressEntry, len(configs)),
+		hosts:     map[string]*hostEntry{},
+	}
+	globalContext := m.annotationsGlobalContext()
+
+	// affected is nil if all the hosts need to be reconverted.
+	var affected sets.Set
+	if base != nil {
+		affected = sets.NewSet()
+	}
+	affect := func(hosts sets.Set) {
+		if affected == nil {
+			return
+		}
+		if hosts == nil {
+			affected = nil
+			return
+		}
+		for host := range hosts {
+			affected.Insert(host)
+		}
+	}
+
+	entries := make([]*ingressEntry, 0, len(configs))
+	for idx := range configs {
+		cfg := &configs[idx]
+		key := ingressKey(cfg)
+		var entry *ingressEntry
+		if base != nil {
+			entry = base.ingresses[key]
+		}
+		if entry == nil || cfg.ResourceVersion == "" || entry.config.ResourceVersion != cfg.ResourceVersion || entry.references(changed) {
+			if entry != nil {
+				affect(entry.hosts)
+			}
+			entry = m.parseIngress(cfg, globalContext)
+			if entry.classParamsError != nil {
+				clusterId := common.GetClusterId(cfg.Annotations)
+				IngressLog.Errorf("Skip ingress %s/%s in cluster %s: %v", cfg.Namespace, cfg.Name, clusterId, entry.classParamsError)
+				common.IncrementInvalidIngress(clusterId, common.InvalidClassParams)
+			}
+			if len(entry.annotationErrors) > 0 {
+				clusterId := common.GetClusterId(cfg.Annotations)
+				IngressLog.Errorf("Parse annotations of ingress %s/%s in cluster %s error: %s",
+					cfg.Namespace, cfg.Name, clusterId, annotations.JoinAnnotationErrors(entry.annotationErrors))
+				for _, err := range entry.annotationErrors {
+					common.IncrementInvalidAnnotation(clusterId, err.Key)
+				}
+			}
+			affect(entry.hosts)
+		}
+		snapshot.ingresses[key] = entry
+		entries = append(entries, entry)
+	}
+	if base != nil {
+		for key, entry := range base.ingresses {
+			if _, exist := snapshot.ingresses[key]; !exist {
+				affect(entry.hosts)
+			}
+		}
+	}
+
+	wrappers := make([]common.WrapperConfig, 0, len(entries))
+	var converting []common.WrapperConfig
+	convertingEntries := map[*config.Config]*ingressEntry{}
+	for _, entry := range entries {
+		if entry.classParamsError != nil {
+			continue
+		}
+		// The ingresses without any host are converted anyway to find their problems.
+		if affected == nil || len(entry.hosts) == 0 || hostsIntersect(entry.hosts, affected) {
+			wrappers = append(wrappers, entry.wrapper(true))
+			converting = append(converting, wrappers[len(wrappers)-1])
+			convertingEntries[entry.config] = entry
+			entry.problems = nil
+		} else {
+			wrappers = append(wrappers, entry.wrapper(false))
+		}
+	}
+	if affected != nil {
+		IngressLog.Debugf("Reconvert %d hosts of %d ingresses", len(affected), len(converting))
+	}
+
+	externalNames := m.convertExternalNames(wrappers)
+	gateways, gatewayOptions := m.convertGateways(converting)
+	virtualServices, virtualServiceOptions := m.convertVirtualService(converting, externalNames)
+	common.RecordInvalidIngresses(gatewayOptions.InvalidIngresses)
+	common.RecordInvalidIngresses(virtualServiceOptions.InvalidIngresses)
+
+	converted := map[string]*hostEntry{}
+	hostEntryOf := func(host string) *hostEntry {
+		entry, exist := converted[host]
+		if !exist {
+			entry = &hostEntry{}
+			converted[host] = entry
+		}
+		return entry
+	}
+	for _, gateway := range gateways {
+		entry := hostEntryOf(common.GetHost(gateway.Annotations))
+		entry.gateways = append(entry.gateways, gateway)
+	}
+	for _, virtualService := range virtualServices {
+		spec := virtualService.Spec.(*networking.VirtualService)
+		if len(spec.Hosts) == 0 {
+			continue
+		}
+		entry := hostEntryOf(spec.Hosts[0])
+		entry.virtualServices = append(entry.virtualServices, virtualService)
+	}
+	for host, routes := range virtualServiceOptions.HTTPRoutes {
+		hostEntryOf(host).httpRoutes = routes
+	}
+	ingressRoutes := virtualServiceOptions.IngressRouteCache.Extract()
+	for _, route := range ingressRoutes.Valid {
+		entry := hostEntryOf(route.Host)
+		entry.ingressRoutes.Valid = append(entry.ingressRoutes.Valid, route)
+	}
+	for _, route := range ingressRoutes.Invalid {
+		entry := hostEntryOf(route.Host)
+		entry.ingressRoutes.Invalid = append(entry.ingressRoutes.Invalid, route)
+	}
+	ingressDomains := gatewayOptions.IngressDomainCache.Extract()
+	for _, domain := range ingressDomains.Valid {
+		entry := hostEntryOf(domain.Host)
+		entry.ingressDomains.Valid = append(entry.ingressDomains.Valid, domain)
+	}
+	for _, domain := range ingressDomains.Invalid {
+		entry := hostEntryOf(domain.Host)
+		entry.ingressDomains.Invalid = append(entry.ingressDomains.Invalid, domain)
+	}
+	for _, conflict := range gatewayOptions.HostSettingsCache.Analyze() {
+		entry := hostEntryOf(conflict.Host)
+		entry.conflicts = append(entry.conflicts, conflict)
+	}
+	for _, problem := range virtualServiceOptions.IngressRouteCache.Problems() {
+		entry := hostEntryOf(problem.Host)
+		entry.problems = append(entry.problems, problem)
+	}
+	for _, problem := range gatewayOptions.IngressDomainCache.Problems() {
+		entry := hostEntryOf(problem.Host)
+		entry.problems = append(entry.problems, problem)
+	}
+	for _, problem := range gatewayOptions.IngressProblems {
+		if entry, exist := convertingEntries[problem.Ingress]; exist {
+			entry.problems = append(entry.problems, problem)
+		}
+	}
+
+	// The unaffected hosts converted along with the affected ones are the same as before.
+	if affected == nil {
+		snapshot.hosts = converted
+	} else {
+		for host, entry := range base.hosts {
+			if !affected.Contains(host) {
+				snapshot.hosts[host] = entry
+			}
+		}
+		for host, entry := range converted {
+			if affected.Contains(host) {
+				snapshot.hosts[host] = entry
+			}
+		}
+	}
+
+	hosts := make([]string, 0, len(snapshot.hosts))
+	for host := range snapshot.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	var gatewayCount, virtualServiceCount int
+	for _, entry := range snapshot.hosts {
+		gatewayCount += len(entry.gateways)
+		virtualServiceCount += len(entry.virtualServices)
+	}
+	snapshot.gateways = make([]config.Config, 0, gatewayCount+1)
+	snapshot.virtualServices = make([]config.Config, 0, virtualServiceCount+1)
+	httpRoutes := make(map[string][]*common.WrapperHTTPRoute, len(hosts))
+	var routes model.IngressRouteCollection
+	var domains model.IngressDomainCollection
+	var conflicts []common.HostConflict
+	var problems []common.IngressProblem
+	for _, host := range hosts {
+		entry := snapshot.hosts[host]
+		snapshot.gateways = append(snapshot.gateways, entry.gateways...)
+		snapshot.virtualServices = append(snapshot.virtualServices, entry.virtualServices...)
+		if len(entry.httpRoutes) != 0 {
+			httpRoutes[host] = entry.httpRoutes
+		}
+		routes.Valid = append(routes.Valid, entry.ingressRoutes.Valid...)
+		routes.Invalid = append(routes.Invalid, entry.ingressRoutes.Invalid...)
+		domains.Valid = append(domains.Valid, entry.ingressDomains.Valid...)
+		domains.Invalid = append(domains.Invalid, entry.ingressDomains.Invalid...)
+		conflicts = append(conflicts, entry.conflicts...)
+		problems = append(problems, entry.problems...)
+	}
+	for _, entry := range entries {
+		problems = append(problems, entry.problems...)
+		if entry.classParamsError != nil {
+			problems = append(problems, common.IngressProblem{
+				ClusterId: common.GetClusterId(entry.config.Annotations),
+				Event:     common.InvalidClassParams,
+				Message:   entry.classParamsError.Error(),
+				Ingress:   entry.config,
+			})
+		}
+		if len(entry.annotationErrors) > 0 {
+			problems = append(problems, common.IngressProblem{
+				ClusterId: common.GetClusterId(entry.config.Annotations),
+				Event:     common.InvalidAnnotation,
+				Message:   annotations.JoinAnnotationErrors(entry.annotationErrors),
+				Ingress:   entry.config,
+			})
+		}
+	}
+	if gateway := tcpservices.ConvertGateway(m.tcpServicesEntries(), m.tcpServicesOptions, m.namespace); gateway != nil {
+		snapshot.gateways = append(snapshot.gateways, *gateway)
+	}
+	if virtualService := tcpservices.ConvertVirtualService(m.tcpServicesEntries(), m.tcpServicesOptions, m.namespace); virtualService != nil {
+		snapshot.virtualServices = append(snapshot.virtualServices, *virtualService)
+	}
+	snapshot.envoyFilters = m.convertEnvoyFilter(httpRoutes, snapshot.gateways)
+	snapshot.destinationRules = m.convertDestinationRule(wrappers, externalNames)
+	snapshot.serviceEntries = append(m.convertServiceEntry(externalNames), m.convertRemoteServiceEntry(entries)...)
+	snapshot.buildIndex()
+
+	watchedServices, watchedSecrets := sets.NewSet(), sets.NewSet()
+	for _, entry := range entries {
+		for service := range entry.services {
+			watchedServices.Insert(service)
+		}
+		for secret := range entry.watchedSecrets {
+			watchedSecrets.Insert(secret)
+		}
+	}
+	m.mutex.Lock()
+	m.ingressRouteCache = routes
+	m.ingressDomainCache = domains
+	m.watchedServiceSet = watchedServices
+	m.watchedSecretSet = watchedSecrets
+	m.mutex.Unlock()
+	m.reportHostConflicts(conflicts)
+	m.reportIngressProblems(problems)
+
+	return snapshot
+}
+
+func setsIntersect(a, b sets.Set) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	for key := range a {
+		if b.Contains(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostsIntersect(hosts, affected sets.Set) bool {
+	if hosts == nil {
+		return true
+	}
+	for host := range hosts {
+		if affected.Contains(host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *IngressConfig) annotationsGlobalContext() *annotations.GlobalContext {
+	clusterSecretListers := map[string]listersv1.SecretLister{}
+	clusterServiceListers := map[string]listersv1.ServiceLister{}
+	m.mutex.RLock()
+	for clusterId, controller := range m.remoteIngressControllers {
+		clusterSecretListers[clusterId] = controller.SecretLister()
+		clusterServiceListers[clusterId] = controller.ServiceLister()
+	}
+	annotationDefaultsController := m.annotationDefaultsController
+	m.mutex.RUnlock()
+	var defaults annotations.Defaults
+	if annotationDefaultsController != nil {
+		defaults = annotationDefaultsController.Defaults()
+	}
+	return &annotations.GlobalContext{
+		ClusterSecretLister: clusterSecretListers,
+		ClusterServiceList:  clusterServiceListers,
+		Defaults:            defaults,
+	}
+}
+
+// classParamsOf returns the parameters of the ingress class of the ingress, or nil if there are none.
+// An error is returned if the ingress class references the parameters which can't be resolved.
+func (m *IngressConfig) classParamsOf(ingressController common.IngressController, cfg *config.Config) (*ingressclassparams.Params, error) {
+	m.mutex.RLock()
+	ingressClassParamsController := m.ingressClassParamsController
+	m.mutex.RUnlock()
+	if ingressClassParamsController == nil {
+		return nil, nil
+	}
+
+	name := ingressController.ClassParamsName(cfg)
+	if name == "" {
+		return nil, nil
+	}
+	return ingressClassParamsController.Params(name)
+}
+
+// parseIngress parses the annotations of the ingress, recording the services and secrets it reads
+// and the errors of the invalid annotations, which are skipped. The annotations are not parsed if
+// the params of the ingress class can't be resolved, rather than serving the ingress without the
+// gateway selector and the annotation restrictions of the class.
+func (m *IngressConfig) parseIngress(cfg *config.Config, globalContext *annotations.GlobalContext) *ingressEntry {
+	context := *globalContext
+	context.WatchedSecrets = sets.NewSet()
+	context.WatchedServices = ingressServices(cfg)
+	annotationsConfig := &annotations.Ingress{
+		Meta: annotations.Meta{
+			Namespace:    cfg.Namespace,
+			Name:         cfg.Name,
+			RawClusterId: common.GetRawClusterId(cfg.Annotations),
+			ClusterId:    common.GetClusterId(cfg.Annotations),
+		},
+	}
+	// The gateway selector of the ingress class takes precedence over the one of the namespace.
+	var gatewaySelector map[string]string
+	if ingressController := m.ingressControllerFor(cfg); ingressController != nil {
+		gatewaySelector = ingressController.NamespaceGatewaySelector(cfg.Namespace)
+		params, err := m.classParamsOf(ingressController, cfg)
+		if err != nil {
+			return &ingressEntry{
+				config:           cfg,
+				annotations:      annotationsConfig,
+				classParamsError: err,
+				hosts:            common.IngressHosts(cfg),
+				services:         context.WatchedServices,
+				watchedSecrets:   context.WatchedSecrets,
+			}
+		}
+		if params != nil {
+			context.Defaults = context.Defaults.Merge(params.Defaults)
+			context.AllowedAnnotations = params.AllowedAnnotations
+			if params.GatewaySelector != nil {
+				gatewaySelector = params.GatewaySelector
+			}
+		}
+	}
+	err := m.annotationHandler.Parse(cfg.Annotations, annotationsConfig, &context)
+	return &ingressEntry{
+		config:           cfg,
+		annotations:      annotationsConfig,
+		annotationErrors: annotations.AnnotationErrors(err),
+		gatewaySelector:  gatewaySelector,
+		hosts:            common.IngressHosts(cfg),
+		services:         context.WatchedServices,
+		watchedSecrets:   context.WatchedSecrets,
+	}
+}
+
+// ingressServices returns the keys of the backend services of the ingress, which are read by the
+// conversion of its routes.
+func ingressServices(cfg *config.Config) sets.Set {
+	clusterId := common.GetClusterId(cfg.Annotations)
+	services, _ := common.IngressReferences(cfg)
+	out := sets.NewSet()
+	for name := range services {
+		out.Insert(util.ClusterNamespacedName{
+			NamespacedName: model.NamespacedName{Namespace: cfg.Namespace, Name: name},
+			ClusterId:      clusterId,
+		}.String())
+	}
+	return out
+}
+
+// recordChanges accumulates the converted configs changed from the previous snapshot into the
+// pending changes, which are notified by the next reconciliation.
+func (m *IngressConfig) recordChanges(prev, curr *conversionSnapshot) {
+	if prev == nil {
+		prev = &conversionSnapshot{}
+	}
+	if m.pendingChanges == nil {
+		m.pendingChanges = map[model.ConfigKey]model.Event{}
+	}
+
+	diff := func(kind config.GroupVersionKind, prevConfigs, currConfigs []config.Config) {
+		previous := make(map[model.ConfigKey]*config.Config, len(prevConfigs))
+		for idx := range prevConfigs {
+			cfg := &prevConfigs[idx]
+			previous[model.ConfigKey{Kind: kind, Name: cfg.Name, Namespace: cfg.Namespace}] = cfg
+		}
+		for idx := range currConfigs {
+			cfg := &currConfigs[idx]
+			key := model.ConfigKey{Kind: kind, Name: cfg.Name, Namespace: cfg.Namespace}
+			prevConfig, exist := previous[key]
+			delete(previous, key)
+			if !exist {
+				m.addChange(key, model.EventAdd)
+			} else if !configEqual(prevConfig, cfg) {
+				m.addChange(key, model.EventUpdate)
+			}
+		}
+		for key := range previous {
+			m.addChange(key, model.EventDelete)
+		}
+	}
+	for _, kind := range convertedKinds {
+		diff(kind, prev.configs(kind), curr.configs(kind))
+	}
+}
+
+// addChange merges the event into the pending change of the same config, e.g. a config added and
+// then deleted before the notification is not notified at all.
+func (m *IngressConfig) addChange(key model.ConfigKey, event model.Event) {
+	if pending, exist := m.pendingChanges[key]; exist {
+		switch {
+		case pending == model.EventAdd && event == model.EventDelete:
+			delete(m.pendingChanges, key)
+			return
+		case pending == model.EventAdd:
+			return
+		case pending == model.EventDelete:
+			event = model.EventUpdate
+		}
+	}
+	m.pendingChanges[key] = event
+}
+
+func configEqual(a, b *config.Config) bool {
+	if !reflect.DeepEqual(a.Annotations, b.Annotations) || !reflect.DeepEqual(a.Labels, b.Labels) {
+		return false
+	}
+	aSpec, aOk := a.Spec.(proto.Message)
+	bSpec, bOk := b.Spec.(proto.Message)
+	if aOk && bOk {
+		// The specs of the hosts not reconverted are shared with the previous snapshot.
+		return aSpec == bSpec || proto.Equal(aSpec, bSpec)
+	}
+	return reflect.DeepEqual(a.Spec, b.Spec)
+}
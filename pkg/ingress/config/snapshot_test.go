@@ -0,0 +1,851 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	v1 "k8s.io/api/core/v1"
+	ingress "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/file"
+	"github.com/alibaba/higress/pkg/ingress/kube/ingressclassparams"
+)
+
+func TestConversionSnapshot(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{Enable: true, SystemNamespace: "wakanda"})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	client.RunAndWait(stop)
+
+	newIngress := func(name, resourceVersion string) *ingress.Ingress {
+		pathType := ingress.PathTypePrefix
+		return &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "wakanda", ResourceVersion: resourceVersion},
+			Spec: ingress.IngressSpec{
+				Rules: []ingress.IngressRule{{
+					Host: name + ".com",
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: name,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	waitForSnapshot := func(check func(*conversionSnapshot) bool) *conversionSnapshot {
+		t.Helper()
+		var snapshot *conversionSnapshot
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			snapshot = m.refreshSnapshot()
+			return check(snapshot), nil
+		})
+		if err != nil {
+			t.Fatalf("wait for the snapshot timeout")
+		}
+		return snapshot
+	}
+
+	ingresses := client.Kube().NetworkingV1().Ingresses("wakanda")
+	_, err := ingresses.Create(context.TODO(), newIngress("foo", "1"), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	snapshot := waitForSnapshot(func(s *conversionSnapshot) bool {
+		return len(s.virtualServices) == 1
+	})
+	assert.Len(t, snapshot.gateways, 1)
+	assert.Len(t, snapshot.destinationRules, 0)
+
+	// All kinds are listed from the same snapshot.
+	assert.Same(t, snapshot, m.refreshSnapshot())
+	virtualServices, err := m.List(gvk.VirtualService, "")
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.virtualServices, virtualServices)
+	assert.Same(t, snapshot, m.lastSnapshot())
+
+	// The changes not reflected by the resource versions.
+	m.ReflectTCPServicesChanges()
+	assert.NotSame(t, snapshot, m.refreshSnapshot())
+	snapshot = m.refreshSnapshot()
+	assert.Same(t, snapshot, m.refreshSnapshot())
+
+	// The resource version of the ingress is changed.
+	updated := newIngress("foo", "2")
+	updated.Spec.Rules[0].Host = "bar.com"
+	_, err = ingresses.Update(context.TODO(), updated, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	// The lists are served from the last snapshot until it is refreshed by the reconciliation.
+	assert.Same(t, snapshot, m.lastSnapshot())
+	snapshot = waitForSnapshot(func(s *conversionSnapshot) bool {
+		return s != snapshot && len(s.gateways) == 1 && s.gateways[0].Annotations[common.HostAnnotation] == "bar.com"
+	})
+
+	// The services are the inputs of the conversion.
+	_, err = client.Kube().CoreV1().Services("wakanda").Create(context.TODO(), &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "wakanda", ResourceVersion: "3"},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	snapshot = waitForSnapshot(func(s *conversionSnapshot) bool {
+		return s != snapshot
+	})
+	assert.Same(t, snapshot, m.refreshSnapshot())
+
+	// The snapshot is not cached without the resource versions.
+	_, err = ingresses.Create(context.TODO(), newIngress("baz", ""), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	snapshot = waitForSnapshot(func(s *conversionSnapshot) bool {
+		return len(s.virtualServices) == 2
+	})
+	assert.NotSame(t, snapshot, m.refreshSnapshot())
+}
+
+func TestIncrementalConversion(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{Enable: true, SystemNamespace: "wakanda"})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	client.RunAndWait(stop)
+
+	changes := map[model.ConfigKey]model.Event{}
+	for _, kind := range []config.GroupVersionKind{gvk.Gateway, gvk.VirtualService, gvk.DestinationRule, gvk.EnvoyFilter, gvk.ServiceEntry} {
+		m.RegisterEventHandler(kind, func(_ config.Config, curr config.Config, event model.Event) {
+			changes[model.ConfigKey{Kind: curr.GroupVersionKind, Name: curr.Name, Namespace: curr.Namespace}] = event
+		})
+	}
+	reconcile := func() map[model.ConfigKey]model.Event {
+		for key := range changes {
+			delete(changes, key)
+		}
+		m.reconcile()
+		return changes
+	}
+
+	newIngress := func(name, host, path, resourceVersion string) *ingress.Ingress {
+		pathType := ingress.PathTypePrefix
+		return &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "wakanda", ResourceVersion: resourceVersion},
+			Spec: ingress.IngressSpec{
+				Rules: []ingress.IngressRule{{
+					Host: host,
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     path,
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: name,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	virtualServiceOf := func(snapshot *conversionSnapshot, host string) *config.Config {
+		for idx := range snapshot.virtualServices {
+			if snapshot.virtualServices[idx].Spec.(*networking.VirtualService).Hosts[0] == host {
+				return &snapshot.virtualServices[idx]
+			}
+		}
+		return nil
+	}
+	entryOf := func(snapshot *conversionSnapshot, name string) *ingressEntry {
+		for _, entry := range snapshot.ingresses {
+			if entry.config.Name == name {
+				return entry
+			}
+		}
+		return nil
+	}
+	resourceVersionOf := func(snapshot *conversionSnapshot, name string) string {
+		if entry := entryOf(snapshot, name); entry != nil {
+			return entry.config.ResourceVersion
+		}
+		return ""
+	}
+	waitForSnapshot := func(prev *conversionSnapshot, check func(*conversionSnapshot) bool) *conversionSnapshot {
+		t.Helper()
+		var snapshot *conversionSnapshot
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			snapshot = m.refreshSnapshot()
+			return snapshot != prev && check(snapshot), nil
+		})
+		if err != nil {
+			t.Fatalf("wait for the snapshot timeout")
+		}
+		return snapshot
+	}
+
+	ingresses := client.Kube().NetworkingV1().Ingresses("wakanda")
+	for _, item := range []*ingress.Ingress{
+		newIngress("foo", "foo.com", "/", "1"),
+		newIngress("bar", "bar.com", "/", "1"),
+		newIngress("baz-1", "baz.com", "/1", "1"),
+		newIngress("baz-2", "baz.com", "/2", "1"),
+	} {
+		_, err := ingresses.Create(context.TODO(), item, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	snapshot := waitForSnapshot(nil, func(s *conversionSnapshot) bool {
+		return len(s.ingresses) == 4
+	})
+	assert.Len(t, snapshot.gateways, 3)
+	assert.Len(t, snapshot.virtualServices, 3)
+	assert.Len(t, reconcile(), 6)
+	assert.Len(t, reconcile(), 0)
+
+	// Only the host of the changed ingress is reconverted and notified.
+	_, err := ingresses.Update(context.TODO(), newIngress("foo", "foo.com", "/foo", "2"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	prev := snapshot
+	snapshot = waitForSnapshot(prev, func(s *conversionSnapshot) bool {
+		return resourceVersionOf(s, "foo") == "2"
+	})
+	assert.Same(t, virtualServiceOf(prev, "bar.com").Spec, virtualServiceOf(snapshot, "bar.com").Spec)
+	assert.Same(t, virtualServiceOf(prev, "baz.com").Spec, virtualServiceOf(snapshot, "baz.com").Spec)
+	assert.NotSame(t, virtualServiceOf(prev, "foo.com").Spec, virtualServiceOf(snapshot, "foo.com").Spec)
+	assert.Equal(t, map[model.ConfigKey]model.Event{
+		{Kind: gvk.VirtualService, Name: virtualServiceOf(snapshot, "foo.com").Name, Namespace: "wakanda"}: model.EventUpdate,
+	}, reconcile())
+
+	// The routes of the other ingresses of the same host are kept.
+	_, err = ingresses.Update(context.TODO(), newIngress("baz-2", "baz.com", "/3", "2"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	prev = snapshot
+	snapshot = waitForSnapshot(prev, func(s *conversionSnapshot) bool {
+		return resourceVersionOf(s, "baz-2") == "2"
+	})
+	assert.Same(t, virtualServiceOf(prev, "foo.com").Spec, virtualServiceOf(snapshot, "foo.com").Spec)
+	assert.Len(t, virtualServiceOf(snapshot, "baz.com").Spec.(*networking.VirtualService).Http, 2)
+	assert.Len(t, reconcile(), 1)
+
+	// The reused results are the same as a full conversion.
+	full := m.convert(ingressController.List(), nil, changedReferences{})
+	assert.Equal(t, len(full.virtualServices), len(snapshot.virtualServices))
+	for idx := range full.virtualServices {
+		assert.True(t, configEqual(&full.virtualServices[idx], &snapshot.virtualServices[idx]))
+	}
+
+	// Only the hosts of the ingresses referencing the changed service are reconverted.
+	_, err = client.Kube().CoreV1().Services("wakanda").Create(context.TODO(), &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "baz-1", Namespace: "wakanda", ResourceVersion: "1"},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	prev = snapshot
+	snapshot = waitForSnapshot(prev, func(s *conversionSnapshot) bool {
+		return entryOf(s, "baz-1") != entryOf(prev, "baz-1")
+	})
+	assert.Same(t, entryOf(prev, "baz-2"), entryOf(snapshot, "baz-2"))
+	assert.Same(t, virtualServiceOf(prev, "foo.com").Spec, virtualServiceOf(snapshot, "foo.com").Spec)
+	assert.NotSame(t, virtualServiceOf(prev, "baz.com").Spec, virtualServiceOf(snapshot, "baz.com").Spec)
+	assert.Len(t, reconcile(), 0)
+
+	// The changes of the services not referenced are ignored.
+	unreferenced := config.Config{Meta: config.Meta{GroupVersionKind: gvk.Service, Name: "qux", Namespace: "wakanda"}}
+	m.onServiceEvent(unreferenced, unreferenced, model.EventUpdate)
+	assert.Same(t, snapshot, m.refreshSnapshot())
+
+	// Only the hosts of the ingresses watching the changed secret are reconverted.
+	withAuth := newIngress("foo", "foo.com", "/foo", "3")
+	withAuth.Annotations = map[string]string{
+		"higress.io/auth-type":   "basic",
+		"higress.io/auth-secret": "auth",
+	}
+	_, err = ingresses.Update(context.TODO(), withAuth, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	snapshot = waitForSnapshot(snapshot, func(s *conversionSnapshot) bool {
+		return resourceVersionOf(s, "foo") == "3"
+	})
+	assert.Len(t, snapshot.envoyFilters, 0)
+	reconcile()
+	_, err = client.Kube().CoreV1().Secrets("wakanda").Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "wakanda", ResourceVersion: "1"},
+		Data:       map[string][]byte{"auth": []byte("foo:bar")},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	prev = snapshot
+	snapshot = waitForSnapshot(prev, func(s *conversionSnapshot) bool {
+		return len(s.envoyFilters) == 1
+	})
+	assert.Same(t, entryOf(prev, "baz-1"), entryOf(snapshot, "baz-1"))
+	assert.Same(t, virtualServiceOf(prev, "baz.com").Spec, virtualServiceOf(snapshot, "baz.com").Spec)
+	events := reconcile()
+	assert.Equal(t, model.EventAdd, events[model.ConfigKey{Kind: gvk.EnvoyFilter, Name: snapshot.envoyFilters[0].Name, Namespace: "wakanda"}])
+
+	// The resources of the removed host are deleted.
+	assert.NoError(t, ingresses.Delete(context.TODO(), "bar", metav1.DeleteOptions{}))
+	snapshot = waitForSnapshot(snapshot, func(s *conversionSnapshot) bool {
+		return len(s.ingresses) == 3
+	})
+	assert.Nil(t, virtualServiceOf(snapshot, "bar.com"))
+	events = reconcile()
+	assert.Len(t, events, 2)
+	for key, event := range events {
+		assert.Equal(t, model.EventDelete, event, key)
+	}
+}
+
+func TestReportIngressProblems(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{Enable: true, SystemNamespace: "wakanda"})
+	fakeRecorder := record.NewFakeRecorder(10)
+	m.eventRecorders[""] = common.NewIngressEventRecorderWithRecorder(fakeRecorder, common.IngressV1APIVersion)
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	client.RunAndWait(stop)
+
+	newIngress := func(name, host, path, resourceVersion string, created int64) *ingress.Ingress {
+		pathType := ingress.PathTypePrefix
+		return &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "wakanda",
+				ResourceVersion:   resourceVersion,
+				CreationTimestamp: metav1.Unix(created, 0),
+			},
+			Spec: ingress.IngressSpec{
+				Rules: []ingress.IngressRule{{
+					Host: host,
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     path,
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: name,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	waitForSnapshot := func(prev *conversionSnapshot, check func(*conversionSnapshot) bool) *conversionSnapshot {
+		t.Helper()
+		var snapshot *conversionSnapshot
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			snapshot = m.refreshSnapshot()
+			return snapshot != prev && check(snapshot), nil
+		})
+		if err != nil {
+			t.Fatalf("wait for the snapshot timeout")
+		}
+		return snapshot
+	}
+	drainEvents := func() []string {
+		var events []string
+		for {
+			select {
+			case event := <-fakeRecorder.Events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
+	reportedProblems := func() int {
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+		return len(m.reportedProblems)
+	}
+
+	ingresses := client.Kube().NetworkingV1().Ingresses("wakanda")
+	empty := &ingress.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "wakanda", ResourceVersion: "1"},
+	}
+	for _, item := range []*ingress.Ingress{
+		newIngress("first", "foo.com", "/", "1", 1),
+		newIngress("second", "foo.com", "/", "1", 2),
+		empty,
+	} {
+		_, err := ingresses.Create(context.TODO(), item, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	snapshot := waitForSnapshot(nil, func(s *conversionSnapshot) bool {
+		return len(s.ingresses) == 3
+	})
+	events := drainEvents()
+	assert.Len(t, events, 2)
+	assert.Contains(t, events, "Warning DuplicatedRoute host foo.com and path / in ingress wakanda/second within cluster  "+
+		"is already defined in ingress wakanda/first within cluster ")
+	assert.Contains(t, events, "Warning EmptyRule either `defaultBackend` or `rules` must be specified")
+
+	// The problems are reported only once, also for the reused hosts and ingresses.
+	_, err := ingresses.Create(context.TODO(), newIngress("bar", "bar.com", "/", "1", 3), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	snapshot = waitForSnapshot(snapshot, func(s *conversionSnapshot) bool {
+		return len(s.ingresses) == 4
+	})
+	assert.Empty(t, drainEvents())
+	assert.Equal(t, 2, reportedProblems())
+
+	// The fixed problems are forgotten, and reported again if they come back.
+	_, err = ingresses.Update(context.TODO(), newIngress("second", "foo.com", "/second", "2", 2), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	snapshot = waitForSnapshot(snapshot, func(s *conversionSnapshot) bool {
+		return reportedProblems() == 1
+	})
+	assert.Empty(t, drainEvents())
+	_, err = ingresses.Update(context.TODO(), newIngress("second", "foo.com", "/", "3", 2), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	waitForSnapshot(snapshot, func(s *conversionSnapshot) bool {
+		return reportedProblems() == 2
+	})
+	assert.Equal(t, []string{"Warning DuplicatedRoute host foo.com and path / in ingress wakanda/second within cluster  " +
+		"is already defined in ingress wakanda/first within cluster "}, drainEvents())
+}
+
+func TestAnnotationDefaults(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{
+		Enable:                      true,
+		SystemNamespace:             "wakanda",
+		AnnotationDefaultsConfigMap: "annotation-defaults",
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	client.RunAndWait(stop)
+
+	configMaps := client.Kube().CoreV1().ConfigMaps("wakanda")
+	_, err := configMaps.Create(context.TODO(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "annotation-defaults", Namespace: "wakanda", ResourceVersion: "1"},
+		Data:       map[string]string{"proxy-next-upstream-tries": "5"},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	pathType := ingress.PathTypePrefix
+	newIngress := func(name string, annotations map[string]string) *ingress.Ingress {
+		return &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "wakanda", ResourceVersion: "1", Annotations: annotations},
+			Spec: ingress.IngressSpec{
+				Rules: []ingress.IngressRule{{
+					Host: name + ".com",
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: name,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	ingresses := client.Kube().NetworkingV1().Ingresses("wakanda")
+	for _, item := range []*ingress.Ingress{
+		newIngress("foo", nil),
+		newIngress("bar", map[string]string{"higress.io/proxy-next-upstream-tries": "2"}),
+	} {
+		_, err = ingresses.Create(context.TODO(), item, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	attempts := func(snapshot *conversionSnapshot, host string) int32 {
+		for _, virtualService := range snapshot.virtualServices {
+			spec := virtualService.Spec.(*networking.VirtualService)
+			if spec.Hosts[0] == host && len(spec.Http) > 0 && spec.Http[0].Retries != nil {
+				return spec.Http[0].Retries.Attempts
+			}
+		}
+		return 0
+	}
+	waitForAttempts := func(foo, bar int32) {
+		t.Helper()
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			snapshot := m.refreshSnapshot()
+			return attempts(snapshot, "foo.com") == foo && attempts(snapshot, "bar.com") == bar, nil
+		})
+		if err != nil {
+			t.Fatalf("wait for the retry attempts %d and %d timeout", foo, bar)
+		}
+	}
+	waitForAttempts(5, 2)
+
+	// The ingresses are reconverted with the changed defaults.
+	_, err = configMaps.Update(context.TODO(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "annotation-defaults", Namespace: "wakanda", ResourceVersion: "2"},
+		Data:       map[string]string{"proxy-next-upstream-tries": "4"},
+	}, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	waitForAttempts(4, 2)
+}
+
+type fakeClassParamsController struct {
+	mutex  sync.Mutex
+	params map[string]*ingressclassparams.Params
+}
+
+func (f *fakeClassParamsController) AddEventHandler(func()) {}
+
+func (f *fakeClassParamsController) Run(<-chan struct{}) {}
+
+func (f *fakeClassParamsController) HasSynced() bool {
+	return true
+}
+
+func (f *fakeClassParamsController) Params(name string) (*ingressclassparams.Params, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	params, exist := f.params[name]
+	if !exist {
+		return nil, fmt.Errorf("ingressclassparams %s is not found", name)
+	}
+	return params, nil
+}
+
+func (f *fakeClassParamsController) setParams(params *ingressclassparams.Params) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.params[params.Name] = params
+}
+
+func (f *fakeClassParamsController) deleteParams(name string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.params, name)
+}
+
+func TestIngressClassParams(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{
+		Enable:          true,
+		SystemNamespace: "wakanda",
+	})
+	fakeRecorder := record.NewFakeRecorder(10)
+	m.eventRecorders[""] = common.NewIngressEventRecorderWithRecorder(fakeRecorder, common.IngressV1APIVersion)
+	paramsController := &fakeClassParamsController{params: map[string]*ingressclassparams.Params{}}
+	paramsController.setParams(&ingressclassparams.Params{
+		Name:               "internal",
+		GatewaySelector:    map[string]string{"higress": "internal-gateway"},
+		Defaults:           annotations.Defaults{"proxy-next-upstream-tries": "3"},
+		AllowedAnnotations: sets.NewSet("ssl-redirect"),
+	})
+	m.ingressClassParamsController = paramsController
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	client.RunAndWait(stop)
+
+	apiGroup := ingressclassparams.GroupName
+	_, err := client.Kube().NetworkingV1().IngressClasses().Create(context.TODO(), &ingress.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal"},
+		Spec: ingress.IngressClassSpec{
+			Controller: "higress.io/higress-controller",
+			Parameters: &ingress.IngressClassParametersReference{
+				APIGroup: &apiGroup,
+				Kind:     ingressclassparams.Kind,
+				Name:     "internal",
+			},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	pathType := ingress.PathTypePrefix
+	newIngress := func(name string, className *string) *ingress.Ingress {
+		return &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "wakanda",
+				ResourceVersion: "1",
+				Annotations:     map[string]string{"higress.io/proxy-next-upstream-tries": "2"},
+			},
+			Spec: ingress.IngressSpec{
+				IngressClassName: className,
+				Rules: []ingress.IngressRule{{
+					Host: name + ".com",
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: name,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	className := "internal"
+	ingresses := client.Kube().NetworkingV1().Ingresses("wakanda")
+	for _, item := range []*ingress.Ingress{newIngress("foo", &className), newIngress("bar", nil)} {
+		_, err = ingresses.Create(context.TODO(), item, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	selector := func(snapshot *conversionSnapshot, host string) map[string]string {
+		for _, gateway := range snapshot.gateways {
+			spec := gateway.Spec.(*networking.Gateway)
+			for _, server := range spec.Servers {
+				if len(server.Hosts) > 0 && server.Hosts[0] == host {
+					return spec.Selector
+				}
+			}
+		}
+		return nil
+	}
+	attempts := func(snapshot *conversionSnapshot, host string) int32 {
+		for _, virtualService := range snapshot.virtualServices {
+			spec := virtualService.Spec.(*networking.VirtualService)
+			if spec.Hosts[0] == host && len(spec.Http) > 0 && spec.Http[0].Retries != nil {
+				return spec.Http[0].Retries.Attempts
+			}
+		}
+		return 0
+	}
+	waitForSnapshot := func(condition func(snapshot *conversionSnapshot) bool) *conversionSnapshot {
+		t.Helper()
+		var snapshot *conversionSnapshot
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			snapshot = m.refreshSnapshot()
+			return condition(snapshot), nil
+		})
+		if err != nil {
+			t.Fatalf("wait for the conversion snapshot timeout")
+		}
+		return snapshot
+	}
+
+	// The ingress of the class is served by the gateway of the class, and the disallowed
+	// annotation is replaced by the default of the class.
+	snapshot := waitForSnapshot(func(snapshot *conversionSnapshot) bool {
+		return attempts(snapshot, "foo.com") != 0 && attempts(snapshot, "bar.com") != 0
+	})
+	assert.Equal(t, map[string]string{"higress": "internal-gateway"}, selector(snapshot, "foo.com"))
+	assert.Empty(t, selector(snapshot, "bar.com"))
+	assert.Equal(t, int32(3), attempts(snapshot, "foo.com"))
+	assert.Equal(t, int32(2), attempts(snapshot, "bar.com"))
+	for _, entry := range snapshot.ingresses {
+		if entry.config.Name == "foo" {
+			assert.Equal(t, []*annotations.AnnotationError{{
+				Key:    "proxy-next-upstream-tries",
+				Value:  "2",
+				Reason: "not allowed by the ingress class",
+			}}, entry.annotationErrors)
+		} else {
+			assert.Empty(t, entry.annotationErrors)
+		}
+	}
+
+	// The ingresses are reconverted with the changed params.
+	paramsController.setParams(&ingressclassparams.Params{
+		Name:            "internal",
+		GatewaySelector: map[string]string{"higress": "private-gateway"},
+	})
+	m.ReflectIngressClassParamsChanges()
+	snapshot = waitForSnapshot(func(snapshot *conversionSnapshot) bool {
+		return selector(snapshot, "foo.com")["higress"] == "private-gateway"
+	})
+	assert.Equal(t, int32(2), attempts(snapshot, "foo.com"))
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+
+	// The ingresses of the class are skipped rather than served without the params.
+	paramsController.deleteParams("internal")
+	m.ReflectIngressClassParamsChanges()
+	snapshot = waitForSnapshot(func(snapshot *conversionSnapshot) bool {
+		return attempts(snapshot, "foo.com") == 0
+	})
+	assert.Nil(t, selector(snapshot, "foo.com"))
+	assert.Equal(t, int32(2), attempts(snapshot, "bar.com"))
+	assert.Equal(t, "Warning InvalidClassParams ingressclassparams internal is not found", <-fakeRecorder.Events)
+	assert.Empty(t, fakeRecorder.Events)
+
+	// The ingresses are served again once the params are restored.
+	paramsController.setParams(&ingressclassparams.Params{
+		Name:            "internal",
+		GatewaySelector: map[string]string{"higress": "private-gateway"},
+	})
+	m.ReflectIngressClassParamsChanges()
+	snapshot = waitForSnapshot(func(snapshot *conversionSnapshot) bool {
+		return selector(snapshot, "foo.com")["higress"] == "private-gateway"
+	})
+	assert.Equal(t, int32(2), attempts(snapshot, "foo.com"))
+}
+
+func TestNamespaceGatewaySelector(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{
+		Enable:                true,
+		SystemNamespace:       "wakanda",
+		GatewaySelectorKey:    "higress",
+		GatewaySelectorValue:  "higress-gateway",
+		NamespaceGatewayLabel: "higress.io/gateway",
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	client.RunAndWait(stop)
+
+	namespaces := client.Kube().CoreV1().Namespaces()
+	_, err := namespaces.Create(context.TODO(), &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "wakanda", Labels: map[string]string{"higress.io/gateway": "internal-gateway"}},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	pathType := ingress.PathTypePrefix
+	_, err = client.Kube().NetworkingV1().Ingresses("wakanda").Create(context.TODO(), &ingress.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "wakanda", ResourceVersion: "1"},
+		Spec: ingress.IngressSpec{
+			Rules: []ingress.IngressRule{{
+				Host: "foo.com",
+				IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+					Paths: []ingress.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+							Name: "foo",
+							Port: ingress.ServiceBackendPort{Number: 80},
+						}},
+					}},
+				}},
+			}},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	waitForSelector := func(value string) {
+		t.Helper()
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			for _, gateway := range m.refreshSnapshot().gateways {
+				if gateway.Spec.(*networking.Gateway).Selector["higress"] == value {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		if err != nil {
+			t.Fatalf("wait for the gateway selector %s timeout", value)
+		}
+	}
+	waitForSelector("internal-gateway")
+
+	// The ingresses are reconverted with the changed label of the namespace.
+	_, err = namespaces.Update(context.TODO(), &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "wakanda", ResourceVersion: "2"},
+	}, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	waitForSelector("higress-gateway")
+}
+
+func TestWatchNamespaceSelector(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{
+		Enable:                 true,
+		SystemNamespace:        "wakanda",
+		WatchNamespace:         "foo,bar",
+		WatchNamespaceSelector: "higress.io/tenant=enabled",
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	client.RunAndWait(stop)
+
+	namespaces := client.Kube().CoreV1().Namespaces()
+	pathType := ingress.PathTypePrefix
+	for _, name := range []string{"foo", "bar", "baz"} {
+		_, err := namespaces.Create(context.TODO(), &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "1"},
+		}, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		_, err = client.Kube().NetworkingV1().Ingresses(name).Create(context.TODO(), &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: name, ResourceVersion: "1"},
+			Spec: ingress.IngressSpec{
+				Rules: []ingress.IngressRule{{
+					Host: name + ".com",
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: name,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	label := func(name, value string) {
+		_, err := namespaces.Update(context.TODO(), &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "2", Labels: map[string]string{"higress.io/tenant": value}},
+		}, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+	}
+
+	waitForHosts := func(expect ...string) {
+		t.Helper()
+		var hosts []string
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			hosts = hosts[:0]
+			for _, virtualService := range m.refreshSnapshot().virtualServices {
+				hosts = append(hosts, virtualService.Spec.(*networking.VirtualService).Hosts...)
+			}
+			return assert.ObjectsAreEqual(expect, hosts), nil
+		})
+		if err != nil {
+			t.Fatalf("wait for the hosts %v timeout, got %v", expect, hosts)
+		}
+	}
+
+	// The namespaces are onboarded by the label, and restricted to the names.
+	label("foo", "enabled")
+	label("baz", "enabled")
+	waitForHosts("foo.com")
+	label("bar", "enabled")
+	waitForHosts("bar.com", "foo.com")
+	label("foo", "disabled")
+	waitForHosts("bar.com")
+}
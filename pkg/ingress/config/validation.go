@@ -0,0 +1,127 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"istio.io/istio/pkg/config"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+)
+
+// ValidateIngress validates the ingress to be admitted in the local cluster. The annotations are
+// parsed, the referenced services and secrets are resolved, and the ingress is converted along
+// with the ingresses sharing its hosts in the current snapshot. The host setting conflicts, which
+// are resolved by the conversion, are returned as the warnings. The ingresses not processed by
+// the local ingress controller are always valid.
+func (m *IngressConfig) ValidateIngress(obj runtime.Object) ([]string, error) {
+	m.mutex.RLock()
+	ingressController := m.remoteIngressControllers[m.localOptions.ClusterId]
+	m.mutex.RUnlock()
+	if ingressController == nil {
+		return nil, nil
+	}
+	cfg, ok := ingressController.ToConfig(obj)
+	if !ok {
+		return nil, nil
+	}
+	// The created ingress is the latest one.
+	if cfg.CreationTimestamp.IsZero() {
+		cfg.CreationTimestamp = time.Now()
+	}
+
+	var errs error
+	for _, key := range annotations.UnknownHigressAnnotations(cfg.Annotations) {
+		errs = multierror.Append(errs, fmt.Errorf("unknown annotation %s", key))
+	}
+	entry := m.parseIngress(cfg, m.annotationsGlobalContext())
+	if entry.classParamsError != nil {
+		return nil, multierror.Append(errs, entry.classParamsError)
+	}
+	for _, err := range entry.annotationErrors {
+		errs = multierror.Append(errs, err)
+	}
+
+	services, secrets := common.IngressReferences(cfg)
+	// The backends are ignored if the destination is specified by the annotation.
+	if entry.annotations.Destination == nil {
+		for _, name := range services.SortedList() {
+			if _, err := ingressController.ServiceLister().Services(cfg.Namespace).Get(name); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("backend service %s/%s is not found", cfg.Namespace, name))
+			}
+		}
+	}
+	for _, name := range secrets.SortedList() {
+		if _, err := ingressController.SecretLister().Secrets(cfg.Namespace).Get(name); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("tls secret %s/%s is not found", cfg.Namespace, name))
+		}
+	}
+
+	warnings, err := m.dryRun(entry)
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	return warnings, errs
+}
+
+// dryRun converts the ingress along with the ingresses sharing its hosts in the current snapshot,
+// which replaces the previous version of the ingress. The invalid routes of the ingress, e.g. the
+// routes already defined by the earlier ingresses, are returned as the error, and the host setting
+// conflicts involving the ingress are returned as the warnings. The invalid ingresses found by the
+// dry run are not recorded to the metrics.
+func (m *IngressConfig) dryRun(candidate *ingressEntry) ([]string, error) {
+	snapshot := m.lastSnapshot()
+	candidateKey := ingressKey(candidate.config)
+	entries := map[string]*ingressEntry{candidateKey: candidate}
+	configs := []config.Config{*candidate.config}
+	for key, entry := range snapshot.ingresses {
+		if key == candidateKey || entry.classParamsError != nil || !hostsIntersect(entry.hosts, candidate.hosts) {
+			continue
+		}
+		entries[key] = entry
+		configs = append(configs, *entry.config)
+	}
+	common.SortIngressByCreationTime(configs)
+
+	wrappers := make([]common.WrapperConfig, 0, len(configs))
+	for idx := range configs {
+		wrappers = append(wrappers, entries[ingressKey(&configs[idx])].wrapper(true))
+	}
+	externalNames := m.convertExternalNames(wrappers)
+	_, gatewayOptions := m.convertGateways(wrappers)
+	_, virtualServiceOptions := m.convertVirtualService(wrappers, externalNames)
+
+	var errs error
+	for _, route := range virtualServiceOptions.IngressRouteCache.InvalidRoutesOf(candidate.config) {
+		if route.Error != "" {
+			errs = multierror.Append(errs, fmt.Errorf("%s", route.Error))
+		}
+	}
+	var warnings []string
+	for _, conflict := range gatewayOptions.HostSettingsCache.Analyze() {
+		for _, source := range conflict.Sources {
+			if source.Ingress == candidate.config {
+				warnings = append(warnings, conflict.Message())
+				break
+			}
+		}
+	}
+	return warnings, errs
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats/view"
+	"istio.io/istio/pkg/config/schema/gvk"
+	v1 "k8s.io/api/core/v1"
+	ingress "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/file"
+)
+
+func invalidIngressCount(t *testing.T) float64 {
+	rows, err := view.RetrieveData("pilot_total_invalid_ingresses")
+	if err != nil {
+		t.Fatalf("retrieve invalid ingresses metric error %v", err)
+	}
+	var count float64
+	for _, row := range rows {
+		count += row.Data.(*view.SumData).Value
+	}
+	return count
+}
+
+func TestValidateIngress(t *testing.T) {
+	client := file.NewClient()
+	m := NewIngressConfig(client, &fakeXdsUpdater{}, "wakanda", "")
+	ingressController := m.AddLocalCluster(common.Options{Enable: true, SystemNamespace: "wakanda"})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	go m.Run(stop)
+	client.RunAndWait(stop)
+
+	newIngress := func(name, host, path, service string, annotations map[string]string) *ingress.Ingress {
+		pathType := ingress.PathTypePrefix
+		return &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "wakanda",
+				Annotations: annotations,
+			},
+			Spec: ingress.IngressSpec{
+				Rules: []ingress.IngressRule{{
+					Host: host,
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     path,
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: service,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+
+	_, err := client.Kube().CoreV1().Services("wakanda").Create(context.TODO(), &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "wakanda"},
+		Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 80}}},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	existing := newIngress("foo", "foo.com", "/", "foo", map[string]string{"higress.io/app-root": "/foo"})
+	existing.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	_, err = client.Kube().NetworkingV1().Ingresses("wakanda").Create(context.TODO(), existing, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		configs, _ := m.List(gvk.VirtualService, "")
+		return len(configs) > 0, nil
+	})
+	if err != nil {
+		t.Fatalf("wait for the converted configs timeout")
+	}
+
+	withTLS := newIngress("bar", "bar.com", "/", "foo", nil)
+	withTLS.Spec.TLS = []ingress.IngressTLS{{Hosts: []string{"bar.com"}, SecretName: "bar-tls"}}
+
+	testCases := []struct {
+		name     string
+		input    runtime.Object
+		errors   []string
+		warnings int
+	}{
+		{
+			name:  "valid",
+			input: newIngress("bar", "bar.com", "/", "foo", nil),
+		},
+		{
+			name:  "update the existing ingress",
+			input: existing,
+		},
+		{
+			name:  "not an ingress",
+			input: &v1.Service{},
+		},
+		{
+			name:   "duplicated route",
+			input:  newIngress("bar", "foo.com", "/", "foo", nil),
+			errors: []string{"host foo.com and path / in ingress wakanda/bar", "already defined in ingress wakanda/foo"},
+		},
+		{
+			name: "invalid annotations",
+			input: newIngress("bar", "bar.com", "/", "foo", map[string]string{
+				"higress.io/canary-wieght":                 "10",
+				"nginx.ingress.kubernetes.io/ssl-redirect": "yes",
+			}),
+			errors: []string{"unknown annotation higress.io/canary-wieght", `invalid annotation ssl-redirect="yes": must be true or false`},
+		},
+		{
+			name:   "unresolvable references",
+			input:  withTLS,
+			errors: []string{"tls secret wakanda/bar-tls is not found"},
+		},
+		{
+			name:   "unresolvable service",
+			input:  newIngress("bar", "bar.com", "/", "nonexistent", nil),
+			errors: []string{"backend service wakanda/nonexistent is not found"},
+		},
+		{
+			name:     "host setting conflict",
+			input:    newIngress("bar", "foo.com", "/bar", "foo", map[string]string{"higress.io/app-root": "/bar"}),
+			warnings: 1,
+		},
+	}
+
+	// The dry runs don't change the metrics of the invalid ingresses.
+	invalidIngresses := invalidIngressCount(t)
+	defer func() {
+		assert.Equal(t, invalidIngresses, invalidIngressCount(t))
+	}()
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			warnings, err := m.ValidateIngress(testCase.input)
+			assert.Len(t, warnings, testCase.warnings)
+			if len(testCase.errors) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				for _, message := range testCase.errors {
+					assert.Contains(t, err.Error(), message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotationdefaults
+
+import (
+	"sync"
+
+	kubeclient "istio.io/istio/pkg/kube"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/configmap"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// Controller watches the ConfigMap of the annotation defaults, whose keys are the annotation
+// names without the prefix, e.g.
+//
+//	ssl-redirect: "false"
+//	load-balance: "round_robin"
+type Controller interface {
+	AddEventHandler(func())
+
+	Run(stop <-chan struct{})
+
+	HasSynced() bool
+
+	// Defaults returns nil if the ConfigMap doesn't exist.
+	Defaults() annotations.Defaults
+}
+
+type controller struct {
+	configMapController configmap.Controller
+	name                types.NamespacedName
+
+	mutex sync.Mutex
+	// The defaults are parsed again only if the resource version changes, so that the invalid
+	// ones are logged once instead of on every conversion.
+	resourceVersion string
+	defaults        annotations.Defaults
+}
+
+// NewController returns nil when the annotation defaults ConfigMap is not specified.
+func NewController(client kubeclient.Client, options common.Options) Controller {
+	if options.AnnotationDefaultsConfigMap == "" {
+		return nil
+	}
+
+	name := configmap.ParseName(options.AnnotationDefaultsConfigMap, options.SystemNamespace)
+	return &controller{
+		configMapController: configmap.NewController(client, name),
+		name:                name,
+	}
+}
+
+func (c *controller) AddEventHandler(f func()) {
+	c.configMapController.AddEventHandler(func(types.NamespacedName) {
+		f()
+	})
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	c.configMapController.Run(stop)
+}
+
+func (c *controller) HasSynced() bool {
+	return c.configMapController.HasSynced()
+}
+
+func (c *controller) Defaults() annotations.Defaults {
+	configMap, err := c.configMapController.Get(c.name)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			IngressLog.Errorf("Get annotation defaults configmap %s error %v", c.name, err)
+		}
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.defaults != nil && configMap.ResourceVersion != "" && configMap.ResourceVersion == c.resourceVersion {
+		return c.defaults
+	}
+	defaults, err := annotations.ParseDefaults(configMap.Data)
+	if err != nil {
+		IngressLog.Errorf("Parse annotation defaults configmap %s error %v", c.name, err)
+	}
+	c.resourceVersion = configMap.ResourceVersion
+	c.defaults = defaults
+	return defaults
+}
@@ -15,6 +15,7 @@
 package annotations
 
 import (
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/util/sets"
 	listersv1 "k8s.io/client-go/listers/core/v1"
@@ -24,9 +25,20 @@ type GlobalContext struct {
 	// secret key is cluster/namespace/name
 	WatchedSecrets sets.Set
 
+	// service key is cluster/namespace/name, the services read by the annotations are recorded
+	// if it's not nil.
+	WatchedServices sets.Set
+
 	ClusterSecretLister map[string]listersv1.SecretLister
 
 	ClusterServiceList map[string]listersv1.ServiceLister
+
+	// Defaults are applied to the annotations of all the ingresses before parsing.
+	Defaults Defaults
+
+	// AllowedAnnotations are the names of the annotations without the prefix which are parsed,
+	// the others are reported as invalid. All the annotations are allowed if it's nil.
+	AllowedAnnotations sets.Set
 }
 
 type Meta struct {
@@ -61,6 +73,12 @@ type Ingress struct {
 	Fallback *FallbackConfig
 
 	Auth *AuthConfig
+
+	HTTP3 *HTTP3Config
+
+	ListenPort *ListenPortConfig
+
+	Destination *DestinationConfig
 }
 
 func (i *Ingress) NeedRegexMatch() bool {
@@ -94,6 +112,39 @@ func (i *Ingress) CanaryKind() (bool, bool) {
 	return false, true
 }
 
+// NeedHTTP3 reports whether HTTP/3 should be served, the annotation of ingress
+// takes precedence over the global switch.
+func (i *Ingress) NeedHTTP3(globalEnabled bool) bool {
+	if i.HTTP3 == nil {
+		return globalEnabled
+	}
+
+	return i.HTTP3.Enabled
+}
+
+// HTTPPorts return the ports of http servers, default is 80.
+func (i *Ingress) HTTPPorts() []uint32 {
+	if i.ListenPort == nil {
+		return []uint32{defaultHTTPPort}
+	}
+
+	return i.ListenPort.HTTP
+}
+
+// HTTPSPorts return the ports of https servers, default is 443.
+func (i *Ingress) HTTPSPorts() []uint32 {
+	if i.ListenPort == nil {
+		return []uint32{defaultHTTPSPort}
+	}
+
+	return i.ListenPort.HTTPS
+}
+
+// ListenOnDefaultHTTPPort reports whether the hosts are exposed on port 80.
+func (i *Ingress) ListenOnDefaultHTTPPort() bool {
+	return containsPort(i.HTTPPorts(), defaultHTTPPort)
+}
+
 func (i *Ingress) NeedTrafficPolicy() bool {
 	return i.UpstreamTLS != nil ||
 		i.LoadBalance != nil
@@ -129,6 +180,9 @@ func NewAnnotationHandlerManager() AnnotationHandler {
 			loadBalance{},
 			fallback{},
 			auth{},
+			http3{},
+			listenPort{},
+			destinationParser{},
 		},
 		gatewayHandlers: []GatewayHandler{
 			downstreamTLS{},
@@ -151,12 +205,18 @@ func NewAnnotationHandlerManager() AnnotationHandler {
 	}
 }
 
+// Parse runs all the parsers, the errors of the invalid annotations are returned together, while
+// the valid parts are still parsed into the config. Use AnnotationErrors to extract them.
 func (h *AnnotationHandlerManager) Parse(annotations Annotations, config *Ingress, globalContext *GlobalContext) error {
+	annotations, errs := annotations.filterAllowed(globalContext.AllowedAnnotations)
+	annotations = annotations.withDefaults(globalContext.Defaults)
 	for _, parser := range h.parsers {
-		_ = parser.Parse(annotations, config, globalContext)
+		if err := parser.Parse(annotations, config, globalContext); err != nil {
+			errs = multierror.Append(errs, err)
+		}
 	}
 
-	return nil
+	return errs
 }
 
 func (h *AnnotationHandlerManager) ApplyGateway(gateway *networking.Gateway, config *Ingress) {
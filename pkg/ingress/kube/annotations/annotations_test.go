@@ -14,7 +14,12 @@
 
 package annotations
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestNeedRegexMatch(t *testing.T) {
 	testCases := []struct {
@@ -180,3 +185,65 @@ func TestNeedTrafficPolicy(t *testing.T) {
 		t.Fatal("should be true")
 	}
 }
+
+func TestParseInvalidValues(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   Annotations
+		invalid []string
+	}{
+		{
+			name: "valid",
+			input: Annotations{
+				buildNginxAnnotationKey(enableCanary):  "true",
+				buildNginxAnnotationKey(canaryWeight):  "10",
+				buildNginxAnnotationKey(retryCount):    "3",
+				buildNginxAnnotationKey(sslRedirect):   "true",
+				buildHigressAnnotationKey(destination): "foo.com:80",
+			},
+		},
+		{
+			name: "invalid values",
+			input: Annotations{
+				buildNginxAnnotationKey(enableCanary):          "yes",
+				buildNginxAnnotationKey(retryCount):            "three",
+				buildNginxAnnotationKey(permanentRedirect):     "ftp://foo.com",
+				buildNginxAnnotationKey(permanentRedirectCode): "200",
+				buildNginxAnnotationKey(loadBalanceAnnotation): "fastest",
+				buildHigressAnnotationKey(destination):         "foo.com",
+				buildHigressAnnotationKey(listenPortHTTP):      "80,http",
+			},
+			invalid: []string{
+				enableCanary, retryCount, permanentRedirect, permanentRedirectCode,
+				loadBalanceAnnotation, destination, listenPortHTTP,
+			},
+		},
+	}
+
+	handler := NewAnnotationHandlerManager()
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := &Ingress{
+				Meta: Meta{
+					Namespace: "default",
+					Name:      "foo",
+				},
+			}
+			err := handler.Parse(testCase.input, config, &GlobalContext{})
+			if len(testCase.invalid) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrInvalidAnnotationValue))
+			var keys []string
+			for _, annotationErr := range AnnotationErrors(err) {
+				keys = append(keys, annotationErr.Key)
+			}
+			assert.ElementsMatch(t, testCase.invalid, keys)
+			// The valid parts are still parsed.
+			assert.NotNil(t, config.Retry)
+			assert.NotNil(t, config.ListenPort)
+			assert.Equal(t, []uint32{80}, config.ListenPort.HTTP)
+		})
+	}
+}
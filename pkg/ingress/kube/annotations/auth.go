@@ -16,13 +16,13 @@ package annotations
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/alibaba/higress/pkg/ingress/kube/util"
-	. "github.com/alibaba/higress/pkg/ingress/log"
 )
 
 const (
@@ -63,21 +63,15 @@ func (a auth) Parse(annotations Annotations, config *Ingress, globalContext *Glo
 	}
 
 	// Check auth type
-	authType, err := annotations.ParseStringASAP(authType)
-	if err != nil {
-		IngressLog.Errorf("Parse auth type error %v within ingress %/%s", err, config.Namespace, config.Name)
-		return nil
-	}
-	if authType != defaultAuthType {
-		IngressLog.Errorf("Auth type %s within ingress %/%s is not supported yet.", authType, config.Namespace, config.Name)
-		return nil
+	rawAuthType, err := annotations.ParseStringASAP(authType)
+	if err != nil || rawAuthType != defaultAuthType {
+		return annotations.invalidValue(authType, "only basic is supported")
 	}
 
 	secretName, _ := annotations.ParseStringASAP(authSecretAnn)
 	namespaced := util.SplitNamespacedName(secretName)
 	if namespaced.Name == "" {
-		IngressLog.Errorf("Auth secret name within ingress %s/%s is invalid", config.Namespace, config.Name)
-		return nil
+		return annotations.invalidValue(authSecretAnn, "the secret name is empty")
 	}
 	if namespaced.Namespace == "" {
 		namespaced.Namespace = config.Namespace
@@ -105,19 +99,15 @@ func (a auth) Parse(annotations Annotations, config *Ingress, globalContext *Glo
 	// Process credentials.
 	secretLister, exist := globalContext.ClusterSecretLister[config.ClusterId]
 	if !exist {
-		IngressLog.Errorf("secret lister of cluster %s doesn't exist", config.ClusterId)
-		return nil
+		return annotations.invalidValue(authSecretAnn, fmt.Sprintf("secret lister of cluster %s doesn't exist", config.ClusterId))
 	}
 	authSecret, err := secretLister.Secrets(namespaced.Namespace).Get(namespaced.Name)
 	if err != nil {
-		IngressLog.Errorf("Secret %s within ingress %s/%s is not found",
-			namespaced.String(), config.Namespace, config.Name)
-		return nil
+		return annotations.invalidValue(authSecretAnn, fmt.Sprintf("secret %s is not found", namespaced.String()))
 	}
 	credentials, err := convertCredentials(secretType, authSecret)
 	if err != nil {
-		IngressLog.Errorf("Parse auth secret fail, err %v", err)
-		return nil
+		return annotations.invalidValue(authSecretAnn, fmt.Sprintf("secret %s is invalid: %v", namespaced.String(), err))
 	}
 	authConfig.Credentials = credentials
 
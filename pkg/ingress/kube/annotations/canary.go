@@ -15,6 +15,7 @@
 package annotations
 
 import (
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 )
 
@@ -57,7 +58,11 @@ func (c canary) Parse(annotations Annotations, config *Ingress, _ *GlobalContext
 		config.Canary = canaryConfig
 	}()
 
-	canaryConfig.Enabled, _ = annotations.ParseBoolASAP(enableCanary)
+	enabled, err := annotations.ParseBoolASAP(enableCanary)
+	if err != nil && !IsMissingAnnotations(err) {
+		return annotations.invalidValue(enableCanary, "must be true or false")
+	}
+	canaryConfig.Enabled = enabled
 	if !canaryConfig.Enabled {
 		return nil
 	}
@@ -84,12 +89,19 @@ func (c canary) Parse(annotations Annotations, config *Ingress, _ *GlobalContext
 		return nil
 	}
 
-	canaryConfig.Weight, _ = annotations.ParseIntASAP(canaryWeight)
+	var errs error
+	if weight, err := annotations.ParseIntASAP(canaryWeight); err == nil && weight >= 0 {
+		canaryConfig.Weight = weight
+	} else if !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(canaryWeight, "must be a non-negative integer"))
+	}
 	if weightTotal, err := annotations.ParseIntASAP(canaryWeightTotal); err == nil && weightTotal > 0 {
 		canaryConfig.WeightTotal = weightTotal
+	} else if !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(canaryWeightTotal, "must be a positive integer"))
 	}
 
-	return nil
+	return errs
 }
 
 func ApplyByWeight(canary, route *networking.HTTPRoute, canaryIngress *Ingress) {
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/gogo/protobuf/types"
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 )
 
@@ -64,7 +65,10 @@ func (c cors) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 	}
 
 	// cors enable
-	enable, _ := annotations.ParseBoolASAP(enableCors)
+	enable, err := annotations.ParseBoolASAP(enableCors)
+	if err != nil && !IsMissingAnnotations(err) {
+		return annotations.invalidValue(enableCors, "must be true or false")
+	}
 	if !enable {
 		return nil
 	}
@@ -102,17 +106,22 @@ func (c cors) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 		corsConfig.ExposeHeaders = splitStringWithSpaceTrim(exposeHeaders)
 	}
 
+	var errs error
 	// allow credentials
-	if allowCredentials, err := annotations.ParseBoolASAP(allowCredentials); err == nil {
-		corsConfig.AllowCredentials = allowCredentials
+	if credentials, err := annotations.ParseBoolASAP(allowCredentials); err == nil {
+		corsConfig.AllowCredentials = credentials
+	} else if !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(allowCredentials, "must be true or false"))
 	}
 
 	// max age
 	if age, err := annotations.ParseIntASAP(maxAge); err == nil {
 		corsConfig.MaxAge = age
+	} else if !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(maxAge, "must be an integer"))
 	}
 
-	return nil
+	return errs
 }
 
 func (c cors) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
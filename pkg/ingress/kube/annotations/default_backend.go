@@ -15,13 +15,14 @@
 package annotations
 
 import (
+	"fmt"
 	"strconv"
 
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/model"
 
 	"github.com/alibaba/higress/pkg/ingress/kube/util"
-	. "github.com/alibaba/higress/pkg/ingress/log"
 )
 
 const (
@@ -55,49 +56,51 @@ func (f fallback) Parse(annotations Annotations, config *Ingress, globalContext
 	fallBackConfig := &FallbackConfig{}
 	svcName, err := annotations.ParseStringASAP(annDefaultBackend)
 	if err != nil {
-		IngressLog.Errorf("Parse annotation default backend err: %v", err)
-		return nil
+		return annotations.invalidValue(annDefaultBackend, "the service name is empty")
 	}
 
 	fallBackConfig.DefaultBackend = util.SplitNamespacedName(svcName)
 	if fallBackConfig.DefaultBackend.Name == "" {
-		IngressLog.Errorf("Annotation default backend within ingress %s/%s is invalid", config.Namespace, config.Name)
-		return nil
+		return annotations.invalidValue(annDefaultBackend, "must be in the format of namespace/name")
 	}
 	// Use ingress namespace instead, if user don't specify the namespace for default backend svc.
 	if fallBackConfig.DefaultBackend.Namespace == "" {
 		fallBackConfig.DefaultBackend.Namespace = config.Namespace
 	}
 
+	// Subscribe service
+	if globalContext.WatchedServices != nil {
+		globalContext.WatchedServices.Insert(util.ClusterNamespacedName{
+			NamespacedName: fallBackConfig.DefaultBackend,
+			ClusterId:      config.ClusterId,
+		}.String())
+	}
+
 	serviceLister, exist := globalContext.ClusterServiceList[config.ClusterId]
 	if !exist {
-		IngressLog.Errorf("service lister of cluster %s doesn't exist", config.ClusterId)
-		return nil
+		return annotations.invalidValue(annDefaultBackend, fmt.Sprintf("service lister of cluster %s doesn't exist", config.ClusterId))
 	}
 
 	fallbackSvc, err := serviceLister.Services(fallBackConfig.DefaultBackend.Namespace).Get(fallBackConfig.DefaultBackend.Name)
 	if err != nil {
-		IngressLog.Errorf("Fallback service %s/%s within ingress %s/%s is not found",
-			fallBackConfig.DefaultBackend.Namespace, fallBackConfig.DefaultBackend.Name, config.Namespace, config.Name)
-		return nil
+		return annotations.invalidValue(annDefaultBackend, fmt.Sprintf("service %s is not found", fallBackConfig.DefaultBackend.String()))
 	}
 	if len(fallbackSvc.Spec.Ports) == 0 {
-		IngressLog.Errorf("Fallback service %s/%s within ingress %s/%s haven't ports",
-			fallBackConfig.DefaultBackend.Namespace, fallBackConfig.DefaultBackend.Name, config.Namespace, config.Name)
-		return nil
+		return annotations.invalidValue(annDefaultBackend, fmt.Sprintf("service %s has no ports", fallBackConfig.DefaultBackend.String()))
 	}
 	// Use the first port like nginx ingress.
 	fallBackConfig.Port = uint32(fallbackSvc.Spec.Ports[0].Port)
 
 	config.Fallback = fallBackConfig
 
+	var errs error
 	if codes, err := annotations.ParseStringASAP(customHTTPError); err == nil {
 		codesStr := splitBySeparator(codes, ",")
 		var codesUint32 []uint32
 		for _, rawCode := range codesStr {
 			code, err := strconv.ParseUint(rawCode, 10, 32)
 			if err != nil {
-				IngressLog.Errorf("Custom HTTP code %s within ingress %s/%s is invalid", rawCode, config.Namespace, config.Name)
+				errs = multierror.Append(errs, annotations.invalidValue(customHTTPError, fmt.Sprintf("invalid status code %s", rawCode)))
 				continue
 			}
 			codesUint32 = append(codesUint32, uint32(code))
@@ -105,12 +108,13 @@ func (f fallback) Parse(annotations Annotations, config *Ingress, globalContext
 		fallBackConfig.customHTTPErrors = codesUint32
 	}
 
-	return nil
+	return errs
 }
 
 func (f fallback) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
 	fallback := config.Fallback
-	if fallback == nil {
+	// The redirect route has no destination to fall back.
+	if fallback == nil || len(route.Route) == 0 {
 		return
 	}
 
@@ -22,6 +22,7 @@ import (
 
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/util/sets"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
@@ -137,6 +138,26 @@ func TestFallbackParse(t *testing.T) {
 	}
 }
 
+func TestFallbackWatchedServices(t *testing.T) {
+	globalContext, cancel := initGlobalContextForService()
+	defer cancel()
+	globalContext.WatchedServices = sets.NewSet()
+
+	// The missing service is watched as well, so that the ingress is reconverted once it's created.
+	config := &Ingress{
+		Meta: Meta{
+			Namespace: "test",
+			ClusterId: "cluster",
+		},
+	}
+	_ = fallback{}.Parse(map[string]string{
+		buildHigressAnnotationKey(annDefaultBackend): "foo/app",
+	}, config, globalContext)
+	if !globalContext.WatchedServices.Equals(sets.NewSet("cluster/foo/app")) {
+		t.Fatalf("Unexpected watched services %v", globalContext.WatchedServices)
+	}
+}
+
 func TestFallbackApplyRoute(t *testing.T) {
 	fallback := fallback{}
 	inputCases := []struct {
@@ -0,0 +1,116 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	"istio.io/istio/pilot/pkg/util/sets"
+)
+
+// perIngressAnnotations can't have default values, because they refer to the resources in the
+// namespace of the ingress, or change where all the ingresses route to.
+var perIngressAnnotations = sets.NewSet(
+	// auth
+	authType, authRealm, authSecretAnn, authSecretTypeAnn,
+	// canary
+	enableCanary, canaryByHeader, canaryByHeaderValue, canaryByHeaderPattern, canaryByCookie,
+	canaryWeight, canaryWeightTotal,
+	// default backend
+	annDefaultBackend, customHTTPError,
+	destination,
+	// secrets
+	authTLSSecret, proxySSLSecret,
+	// redirect and rewrite
+	appRoot, temporalRedirect, permanentRedirect, permanentRedirectCode, rewriteTarget, upstreamVhost,
+)
+
+// Defaults are the default values of the annotations keyed by the names without the prefix,
+// e.g. ssl-redirect. They are applied to the ingresses setting neither the nginx nor the higress
+// annotation, so that the ASAP parsing falls back to them.
+type Defaults map[string]string
+
+// ParseDefaults parses the data of the annotation defaults ConfigMap. The unknown keys, the keys
+// which can't have default values and the invalid values are reported by the error and dropped,
+// the others are still returned.
+func ParseDefaults(data map[string]string) (Defaults, error) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs error
+	defaults := Defaults{}
+	for _, key := range keys {
+		switch {
+		case !knownAnnotations.Contains(key):
+			errs = multierror.Append(errs, fmt.Errorf("unknown annotation %s", key))
+		case perIngressAnnotations.Contains(key):
+			errs = multierror.Append(errs, fmt.Errorf("annotation %s can't have a default value", key))
+		default:
+			defaults[key] = data[key]
+		}
+	}
+
+	// The values are validated by the parsers, the defaults left don't need the listers.
+	err := NewAnnotationHandlerManager().Parse(Annotations{}.withDefaults(defaults), &Ingress{},
+		&GlobalContext{WatchedSecrets: sets.NewSet()})
+	for _, annotationErr := range AnnotationErrors(err) {
+		delete(defaults, annotationErr.Key)
+		errs = multierror.Append(errs, annotationErr)
+	}
+	return defaults, errs
+}
+
+// withDefaults returns the annotations with the default values of the keys set by neither the
+// nginx nor the higress annotation, which are added as the higress annotations.
+func (a Annotations) withDefaults(defaults Defaults) Annotations {
+	if len(defaults) == 0 {
+		return a
+	}
+
+	out := make(Annotations, len(a)+len(defaults))
+	for key, value := range a {
+		out[key] = value
+	}
+	for key, value := range defaults {
+		if !a.HasASAP(key) {
+			out[buildHigressAnnotationKey(key)] = value
+		}
+	}
+	return out
+}
+
+// Merge returns the defaults overridden by the given ones.
+func (d Defaults) Merge(overrides Defaults) Defaults {
+	if len(overrides) == 0 {
+		return d
+	}
+	if len(d) == 0 {
+		return overrides
+	}
+
+	out := make(Defaults, len(d)+len(overrides))
+	for key, value := range d {
+		out[key] = value
+	}
+	for key, value := range overrides {
+		out[key] = value
+	}
+	return out
+}
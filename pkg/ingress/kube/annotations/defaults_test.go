@@ -0,0 +1,130 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pilot/pkg/util/sets"
+)
+
+func TestParseDefaults(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  map[string]string
+		expect Defaults
+		errs   []string
+	}{
+		{
+			name:   "empty",
+			expect: Defaults{},
+		},
+		{
+			name: "valid",
+			input: map[string]string{
+				sslRedirect:           "false",
+				loadBalanceAnnotation: "round_robin",
+				retryCount:            "3",
+			},
+			expect: Defaults{
+				sslRedirect:           "false",
+				loadBalanceAnnotation: "round_robin",
+				retryCount:            "3",
+			},
+		},
+		{
+			name: "unknown and per ingress",
+			input: map[string]string{
+				"proxy-timeout": "10",
+				enableCanary:    "true",
+				retryCount:      "3",
+			},
+			expect: Defaults{
+				retryCount: "3",
+			},
+			errs: []string{"annotation canary can't have a default value", "unknown annotation proxy-timeout"},
+		},
+		{
+			name: "invalid value",
+			input: map[string]string{
+				sslRedirect: "yes",
+				retryCount:  "3",
+			},
+			expect: Defaults{
+				retryCount: "3",
+			},
+			errs: []string{`invalid annotation ssl-redirect="yes": must be true or false`},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			defaults, err := ParseDefaults(testCase.input)
+			assert.Equal(t, testCase.expect, defaults)
+			if len(testCase.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, message := range testCase.errs {
+				assert.Contains(t, err.Error(), message)
+			}
+		})
+	}
+}
+
+func TestParseWithDefaults(t *testing.T) {
+	handler := NewAnnotationHandlerManager()
+	globalContext := &GlobalContext{
+		WatchedSecrets: sets.NewSet(),
+		Defaults: Defaults{
+			sslRedirect: "true",
+			retryCount:  "5",
+		},
+	}
+
+	// The defaults apply to the ingresses setting neither annotation.
+	config := &Ingress{}
+	assert.NoError(t, handler.Parse(Annotations{}, config, globalContext))
+	assert.True(t, config.Redirect.httpsRedirect)
+	assert.Equal(t, int32(5), config.Retry.retryCount)
+
+	// The annotations of ingress take precedence, no matter nginx or higress.
+	config = &Ingress{}
+	assert.NoError(t, handler.Parse(Annotations{
+		buildNginxAnnotationKey(sslRedirect):  "false",
+		buildHigressAnnotationKey(retryCount): "2",
+	}, config, globalContext))
+	assert.False(t, config.Redirect.httpsRedirect)
+	assert.Equal(t, int32(2), config.Retry.retryCount)
+
+	// The invalid annotation of ingress is not replaced by the default.
+	config = &Ingress{}
+	err := handler.Parse(Annotations{buildNginxAnnotationKey(sslRedirect): "yes"}, config, globalContext)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid annotation ssl-redirect="yes"`)
+}
+
+func TestMergeDefaults(t *testing.T) {
+	global := Defaults{sslRedirect: "true", retryCount: "5"}
+	assert.Equal(t, global, global.Merge(nil))
+	assert.Equal(t, global, Defaults(nil).Merge(global))
+
+	merged := global.Merge(Defaults{retryCount: "2", loadBalanceAnnotation: "round_robin"})
+	assert.Equal(t, Defaults{sslRedirect: "true", retryCount: "2", loadBalanceAnnotation: "round_robin"}, merged)
+	// The global defaults are shared by the classes, which must not be modified.
+	assert.Equal(t, Defaults{sslRedirect: "true", retryCount: "5"}, global)
+}
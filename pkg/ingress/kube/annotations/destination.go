@@ -0,0 +1,66 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"net"
+	"strconv"
+)
+
+const (
+	destination = "destination"
+)
+
+var (
+	_ Parser = &destinationParser{}
+)
+
+// DestinationConfig routes to a host which is not a kubernetes service, e.g. the hosts of the
+// service entries synced from the registries of McpBridge.
+// Once it is specified, the backend services of ingress are ignored.
+type DestinationConfig struct {
+	Host string
+
+	Port uint32
+}
+
+type destinationParser struct{}
+
+func (d destinationParser) Parse(annotations Annotations, config *Ingress, _ *GlobalContext) error {
+	if !annotations.HasHigress(destination) {
+		return nil
+	}
+
+	raw, err := annotations.ParseStringForHigress(destination)
+	if err != nil {
+		return annotations.invalidValue(destination, "must be in the format of host:port")
+	}
+
+	// The format is host:port.
+	host, rawPort, err := net.SplitHostPort(raw)
+	if err != nil || host == "" {
+		return annotations.invalidValue(destination, "must be in the format of host:port")
+	}
+	port, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil || port == 0 {
+		return annotations.invalidValue(destination, "the port must be in 1-65535")
+	}
+
+	config.Destination = &DestinationConfig{
+		Host: host,
+		Port: uint32(port),
+	}
+	return nil
+}
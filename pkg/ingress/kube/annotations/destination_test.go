@@ -0,0 +1,65 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDestinationParse(t *testing.T) {
+	parser := destinationParser{}
+
+	testCases := []struct {
+		input  Annotations
+		expect *DestinationConfig
+	}{
+		{},
+		{
+			input: Annotations{
+				buildNginxAnnotationKey(destination): "foo.DEFAULT-GROUP.public.nacos:8080",
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(destination): "foo.DEFAULT-GROUP.public.nacos:8080",
+			},
+			expect: &DestinationConfig{
+				Host: "foo.DEFAULT-GROUP.public.nacos",
+				Port: 8080,
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(destination): "foo.DEFAULT-GROUP.public.nacos",
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(destination): "foo.static:0",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("", func(t *testing.T) {
+			config := &Ingress{}
+			_ = parser.Parse(testCase.input, config, nil)
+			if !reflect.DeepEqual(testCase.expect, config.Destination) {
+				t.Fatalf("Should be equal")
+			}
+		})
+	}
+}
@@ -15,8 +15,10 @@
 package annotations
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/credentials/kube"
 	"istio.io/istio/pilot/pkg/model"
@@ -57,10 +59,11 @@ func (d downstreamTLS) Parse(annotations Annotations, config *Ingress, _ *Global
 		config.DownstreamTLS = downstreamTLSConfig
 	}()
 
+	var errs error
 	if secretName, err := annotations.ParseStringASAP(authTLSSecret); err == nil {
 		namespacedName := util.SplitNamespacedName(secretName)
 		if namespacedName.Name == "" {
-			IngressLog.Errorf("CA secret name %s format is invalid.", secretName)
+			errs = multierror.Append(errs, annotations.invalidValue(authTLSSecret, "the secret name is empty"))
 		} else {
 			if namespacedName.Namespace == "" {
 				namespacedName.Namespace = config.Namespace
@@ -76,13 +79,15 @@ func (d downstreamTLS) Parse(annotations Annotations, config *Ingress, _ *Global
 		for _, cipher := range cipherList {
 			if security.IsValidCipherSuite(cipher) {
 				validCipherSuite = append(validCipherSuite, cipher)
+			} else {
+				errs = multierror.Append(errs, annotations.invalidValue(sslCipher, fmt.Sprintf("unsupported cipher suite %s", cipher)))
 			}
 		}
 
 		downstreamTLSConfig.CipherSuites = validCipherSuite
 	}
 
-	return nil
+	return errs
 }
 
 func (d downstreamTLS) ApplyGateway(gateway *networking.Gateway, config *Ingress) {
@@ -0,0 +1,79 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// AnnotationError is the error of an invalid annotation returned by the parsers, the other parts
+// of the annotations are still parsed.
+type AnnotationError struct {
+	// Key is the annotation key without the prefix, e.g. auth-type.
+	Key string
+	// Value is the raw value of the annotation.
+	Value string
+	// Reason describes why the value is invalid.
+	Reason string
+}
+
+func (e *AnnotationError) Error() string {
+	return fmt.Sprintf("invalid annotation %s=%q: %s", e.Key, e.Value, e.Reason)
+}
+
+// Is reports the annotation errors as ErrInvalidAnnotationValue.
+func (e *AnnotationError) Is(target error) bool {
+	return target == ErrInvalidAnnotationValue
+}
+
+// AnnotationErrors extracts the annotation errors from the error returned by the parsers, the
+// other errors are reported as the errors without the annotation key.
+func AnnotationErrors(err error) []*AnnotationError {
+	if err == nil {
+		return nil
+	}
+
+	var errs []error
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		errs = merr.WrappedErrors()
+	} else {
+		errs = []error{err}
+	}
+
+	var out []*AnnotationError
+	for _, e := range errs {
+		var annotationErr *AnnotationError
+		if errors.As(e, &annotationErr) {
+			out = append(out, annotationErr)
+		} else {
+			out = append(out, &AnnotationError{Reason: e.Error()})
+		}
+	}
+	return out
+}
+
+// JoinAnnotationErrors joins the messages of the annotation errors.
+func JoinAnnotationErrors(errs []*AnnotationError) string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotationErrors(t *testing.T) {
+	annotations := Annotations{
+		buildNginxAnnotationKey(sslRedirect):   "yes",
+		buildHigressAnnotationKey(sslRedirect): "true",
+		buildHigressAnnotationKey(destination): "foo.com",
+	}
+	sslErr := annotations.invalidValue(sslRedirect, "must be true or false")
+	destinationErr := annotations.invalidValue(destination, "must be in the format of host:port")
+	assert.Equal(t, `invalid annotation ssl-redirect="yes": must be true or false`, sslErr.Error())
+	assert.True(t, errors.Is(sslErr, ErrInvalidAnnotationValue))
+
+	testCases := []struct {
+		name   string
+		input  error
+		expect []*AnnotationError
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:  "single",
+			input: sslErr,
+			expect: []*AnnotationError{
+				{Key: sslRedirect, Value: "yes", Reason: "must be true or false"},
+			},
+		},
+		{
+			name:  "multiple",
+			input: multierror.Append(multierror.Append(nil, sslErr), destinationErr),
+			expect: []*AnnotationError{
+				{Key: sslRedirect, Value: "yes", Reason: "must be true or false"},
+				{Key: destination, Value: "foo.com", Reason: "must be in the format of host:port"},
+			},
+		},
+		{
+			name:  "other error",
+			input: errors.New("unknown"),
+			expect: []*AnnotationError{
+				{Reason: "unknown"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expect, AnnotationErrors(testCase.input))
+		})
+	}
+
+	assert.Equal(t, `invalid annotation ssl-redirect="yes": must be true or false; `+
+		`invalid annotation destination="foo.com": must be in the format of host:port`,
+		JoinAnnotationErrors(AnnotationErrors(multierror.Append(sslErr, destinationErr))))
+}
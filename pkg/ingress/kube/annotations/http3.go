@@ -0,0 +1,45 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+const (
+	enableHTTP3 = "enable-http3"
+)
+
+var (
+	_ Parser = &http3{}
+)
+
+type HTTP3Config struct {
+	Enabled bool
+}
+
+type http3 struct{}
+
+func (h http3) Parse(annotations Annotations, config *Ingress, _ *GlobalContext) error {
+	if !annotations.HasHigress(enableHTTP3) {
+		return nil
+	}
+
+	enabled, err := annotations.ParseBoolForHigress(enableHTTP3)
+	if err != nil {
+		return annotations.invalidValue(enableHTTP3, "must be true or false")
+	}
+
+	config.HTTP3 = &HTTP3Config{
+		Enabled: enabled,
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHTTP3Parse(t *testing.T) {
+	parser := http3{}
+
+	testCases := []struct {
+		input  Annotations
+		expect *HTTP3Config
+	}{
+		{},
+		{
+			input: Annotations{
+				buildNginxAnnotationKey(enableHTTP3): "true",
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(enableHTTP3): "true",
+			},
+			expect: &HTTP3Config{
+				Enabled: true,
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(enableHTTP3): "false",
+			},
+			expect: &HTTP3Config{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("", func(t *testing.T) {
+			config := &Ingress{}
+			_ = parser.Parse(testCase.input, config, nil)
+			if !reflect.DeepEqual(testCase.expect, config.HTTP3) {
+				t.Fatalf("Should be equal")
+			}
+		})
+	}
+}
+
+func TestNeedHTTP3(t *testing.T) {
+	if (&Ingress{}).NeedHTTP3(false) {
+		t.Fatalf("Should be disabled")
+	}
+	if !(&Ingress{}).NeedHTTP3(true) {
+		t.Fatalf("Should follow the global switch")
+	}
+	if (&Ingress{HTTP3: &HTTP3Config{}}).NeedHTTP3(true) {
+		t.Fatalf("Annotation should take precedence")
+	}
+}
@@ -17,7 +17,8 @@ package annotations
 import networking "istio.io/api/networking/v1alpha3"
 
 type Parser interface {
-	// Parse parses ingress annotations and puts result on config
+	// Parse parses ingress annotations and puts result on config. The invalid annotations are
+	// skipped and reported by the *AnnotationError, or the multierror of them.
 	Parse(annotations Annotations, config *Ingress, globalContext *GlobalContext) error
 }
 
@@ -74,6 +74,8 @@ func (i ipAccessControl) ApplyRoute(route *networking.HTTPRoute, config *Ingress
 		return
 	}
 
+	// The remote ip is the real client ip resolved by gateway from the PROXY protocol or
+	// X-Forwarded-For according to the gateway options.
 	filter := &networking.IPAccessControl{}
 	if ac.Route.isWhite {
 		filter.RemoteIpBlocks = ac.Route.remoteIp
@@ -0,0 +1,130 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	listenPortHTTP  = "listen-port-http"
+	listenPortHTTPS = "listen-port-https"
+
+	defaultHTTPPort  uint32 = 80
+	defaultHTTPSPort uint32 = 443
+)
+
+var (
+	_ Parser = &listenPort{}
+)
+
+// ListenPortConfig holds the ports on which the hosts of ingress are exposed.
+// The scheme without the annotation is still exposed on its standard port, and
+// the empty value of the annotation disables the scheme, e.g. the hosts are only
+// served on 8443 with listen-port-http: "" and listen-port-https: "8443".
+// The custom ports must be exposed by the gateway service as well, which are set
+// by gateway.service.listenPorts of the helm chart.
+type ListenPortConfig struct {
+	HTTP []uint32
+
+	HTTPS []uint32
+}
+
+type listenPort struct{}
+
+func (l listenPort) Parse(annotations Annotations, config *Ingress, _ *GlobalContext) error {
+	if !needListenPortConfig(annotations) {
+		return nil
+	}
+
+	var errs error
+	listenPortConfig := &ListenPortConfig{
+		HTTP:  []uint32{defaultHTTPPort},
+		HTTPS: []uint32{defaultHTTPSPort},
+	}
+	if annotations.HasHigress(listenPortHTTP) {
+		var err error
+		listenPortConfig.HTTP, err = parsePorts(annotations[buildHigressAnnotationKey(listenPortHTTP)])
+		if err != nil {
+			errs = multierror.Append(errs, annotations.invalidValue(listenPortHTTP, "the ports must be in 1-65535"))
+		}
+	}
+	if annotations.HasHigress(listenPortHTTPS) {
+		var err error
+		listenPortConfig.HTTPS, err = parsePorts(annotations[buildHigressAnnotationKey(listenPortHTTPS)])
+		if err != nil {
+			errs = multierror.Append(errs, annotations.invalidValue(listenPortHTTPS, "the ports must be in 1-65535"))
+		}
+	}
+
+	config.ListenPort = listenPortConfig
+	return errs
+}
+
+func needListenPortConfig(annotations Annotations) bool {
+	return annotations.HasHigress(listenPortHTTP) ||
+		annotations.HasHigress(listenPortHTTPS)
+}
+
+// parsePorts returns the valid ports, and the error if any port is invalid.
+// The empty value returns no ports.
+func parsePorts(raw string) ([]uint32, error) {
+	var ports []uint32
+	var err error
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	for _, item := range splitStringWithSpaceTrim(raw) {
+		port, parseErr := strconv.ParseUint(item, 10, 16)
+		if parseErr != nil || port == 0 {
+			err = ErrInvalidAnnotationValue
+			continue
+		}
+		ports = append(ports, uint32(port))
+	}
+	return ports, err
+}
+
+func containsPort(ports []uint32, target uint32) bool {
+	for _, port := range ports {
+		if port == target {
+			return true
+		}
+	}
+	return false
+}
+
+// String is used to identify the listen ports.
+func (l *ListenPortConfig) String() string {
+	var builder strings.Builder
+	builder.WriteString("http:")
+	for idx, port := range l.HTTP {
+		if idx != 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(strconv.Itoa(int(port)))
+	}
+	builder.WriteString(";https:")
+	for idx, port := range l.HTTPS {
+		if idx != 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(strconv.Itoa(int(port)))
+	}
+	return builder.String()
+}
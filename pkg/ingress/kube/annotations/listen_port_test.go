@@ -0,0 +1,101 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListenPortParse(t *testing.T) {
+	parser := listenPort{}
+
+	testCases := []struct {
+		input  Annotations
+		expect *ListenPortConfig
+	}{
+		{},
+		{
+			input: Annotations{
+				buildNginxAnnotationKey(listenPortHTTPS): "8443",
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(listenPortHTTPS): "8443",
+			},
+			expect: &ListenPortConfig{
+				HTTP:  []uint32{80},
+				HTTPS: []uint32{8443},
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(listenPortHTTP): "8080",
+			},
+			expect: &ListenPortConfig{
+				HTTP:  []uint32{8080},
+				HTTPS: []uint32{443},
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(listenPortHTTP):  "",
+				buildHigressAnnotationKey(listenPortHTTPS): "8443",
+			},
+			expect: &ListenPortConfig{
+				HTTPS: []uint32{8443},
+			},
+		},
+		{
+			input: Annotations{
+				buildHigressAnnotationKey(listenPortHTTP):  "80, 8080",
+				buildHigressAnnotationKey(listenPortHTTPS): "443,abc,70000,8443",
+			},
+			expect: &ListenPortConfig{
+				HTTP:  []uint32{80, 8080},
+				HTTPS: []uint32{443, 8443},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("", func(t *testing.T) {
+			config := &Ingress{}
+			_ = parser.Parse(testCase.input, config, nil)
+			if !reflect.DeepEqual(testCase.expect, config.ListenPort) {
+				t.Fatalf("Should be equal")
+			}
+		})
+	}
+}
+
+func TestListenPorts(t *testing.T) {
+	config := &Ingress{}
+	if !reflect.DeepEqual(config.HTTPPorts(), []uint32{80}) ||
+		!reflect.DeepEqual(config.HTTPSPorts(), []uint32{443}) ||
+		!config.ListenOnDefaultHTTPPort() {
+		t.Fatalf("Should use the default ports")
+	}
+
+	config.ListenPort = &ListenPortConfig{
+		HTTPS: []uint32{8443},
+	}
+	if len(config.HTTPPorts()) != 0 ||
+		!reflect.DeepEqual(config.HTTPSPorts(), []uint32{8443}) ||
+		config.ListenOnDefaultHTTPPort() {
+		t.Fatalf("Should only use the specified ports")
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/gogo/protobuf/types"
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 )
 
@@ -85,6 +86,7 @@ func (l loadBalance) Parse(annotations Annotations, config *Ingress, _ *GlobalCo
 		config.LoadBalance = loadBalanceConfig
 	}()
 
+	var errs error
 	if isCookieAffinity(annotations) {
 		loadBalanceConfig.cookie = &consistentHashByCookie{
 			name: defaultAffinityCookieName,
@@ -101,10 +103,14 @@ func (l loadBalance) Parse(annotations Annotations, config *Ingress, _ *GlobalCo
 			loadBalanceConfig.cookie.age = &types.Duration{
 				Seconds: int64(age),
 			}
+		} else if !IsMissingAnnotations(err) {
+			errs = multierror.Append(errs, annotations.invalidValue(sessionCookieMaxAge, "must be an integer"))
 		} else if age, err = annotations.ParseIntASAP(sessionCookieExpires); err == nil {
 			loadBalanceConfig.cookie.age = &types.Duration{
 				Seconds: int64(age),
 			}
+		} else if !IsMissingAnnotations(err) {
+			errs = multierror.Append(errs, annotations.invalidValue(sessionCookieExpires, "must be an integer"))
 		}
 	} else if isOtherAffinity(annotations) {
 		if key, err := annotations.ParseStringASAP(upstreamHashBy); err == nil &&
@@ -128,12 +134,15 @@ func (l loadBalance) Parse(annotations Annotations, config *Ingress, _ *GlobalCo
 		}
 	} else {
 		if lb, err := annotations.ParseStringASAP(loadBalanceAnnotation); err == nil {
-			lb = strings.ToUpper(lb)
-			loadBalanceConfig.simple = networking.LoadBalancerSettings_SimpleLB(networking.LoadBalancerSettings_SimpleLB_value[lb])
+			simple, exist := networking.LoadBalancerSettings_SimpleLB_value[strings.ToUpper(lb)]
+			if !exist {
+				return annotations.invalidValue(loadBalanceAnnotation, "unsupported load balance algorithm")
+			}
+			loadBalanceConfig.simple = networking.LoadBalancerSettings_SimpleLB(simple)
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func (l loadBalance) ApplyTrafficPolicy(trafficPolicy *networking.TrafficPolicy_PortTrafficPolicy, config *Ingress) {
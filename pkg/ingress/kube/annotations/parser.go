@@ -84,11 +84,17 @@ func (a Annotations) ParseBoolForHigress(key string) (bool, error) {
 	return false, ErrMissingAnnotations
 }
 
+// ParseBoolASAP parses the nginx annotation first, then the higress one. The invalid value error
+// is returned if neither of them is valid while any of them is present.
 func (a Annotations) ParseBoolASAP(key string) (bool, error) {
-	if result, err := a.ParseBool(key); err == nil {
+	result, err := a.ParseBool(key)
+	if err == nil {
 		return result, nil
 	}
-	return a.ParseBoolForHigress(key)
+	if higressResult, higressErr := a.ParseBoolForHigress(key); higressErr == nil || IsMissingAnnotations(err) {
+		return higressResult, higressErr
+	}
+	return result, err
 }
 
 func (a Annotations) ParseString(key string) (string, error) {
@@ -128,10 +134,14 @@ func (a Annotations) ParseStringForHigress(key string) (string, error) {
 // ParseStringASAP will first extra config from nginx annotation, then will
 // try to extra config from Higress annotation if the first step fails.
 func (a Annotations) ParseStringASAP(key string) (string, error) {
-	if result, err := a.ParseString(key); err == nil {
+	result, err := a.ParseString(key)
+	if err == nil {
 		return result, nil
 	}
-	return a.ParseStringForHigress(key)
+	if higressResult, higressErr := a.ParseStringForHigress(key); higressErr == nil || IsMissingAnnotations(err) {
+		return higressResult, higressErr
+	}
+	return result, err
 }
 
 func (a Annotations) ParseInt(key string) (int, error) {
@@ -215,17 +225,25 @@ func (a Annotations) ParseUint32ForHigress(key string) (uint32, error) {
 }
 
 func (a Annotations) ParseIntASAP(key string) (int, error) {
-	if result, err := a.ParseInt(key); err == nil {
+	result, err := a.ParseInt(key)
+	if err == nil {
 		return result, nil
 	}
-	return a.ParseIntForHigress(key)
+	if higressResult, higressErr := a.ParseIntForHigress(key); higressErr == nil || IsMissingAnnotations(err) {
+		return higressResult, higressErr
+	}
+	return result, err
 }
 
 func (a Annotations) ParseInt32ASAP(key string) (int32, error) {
-	if result, err := a.ParseInt32(key); err == nil {
+	result, err := a.ParseInt32(key)
+	if err == nil {
 		return result, nil
 	}
-	return a.ParseInt32ForHigress(key)
+	if higressResult, higressErr := a.ParseInt32ForHigress(key); higressErr == nil || IsMissingAnnotations(err) {
+		return higressResult, higressErr
+	}
+	return result, err
 }
 
 func (a Annotations) Has(key string) bool {
@@ -253,6 +271,20 @@ func (a Annotations) HasASAP(key string) bool {
 	return a.HasHigress(key)
 }
 
+// invalidValue returns the error of the present but invalid annotation, the value of nginx takes
+// precedence like the ASAP parsing.
+func (a Annotations) invalidValue(key, reason string) error {
+	value, exist := a[buildNginxAnnotationKey(key)]
+	if !exist {
+		value = a[buildHigressAnnotationKey(key)]
+	}
+	return &AnnotationError{
+		Key:    key,
+		Value:  value,
+		Reason: reason,
+	}
+}
+
 func buildNginxAnnotationKey(key string) string {
 	return DefaultAnnotationsPrefix + "/" + key
 }
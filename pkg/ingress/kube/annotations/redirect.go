@@ -20,6 +20,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 )
 
@@ -64,8 +65,15 @@ func (r redirect) Parse(annotations Annotations, config *Ingress, _ *GlobalConte
 
 	redirectConfig.AppRoot, _ = annotations.ParseStringASAP(appRoot)
 
-	httpsRedirect, _ := annotations.ParseBoolASAP(sslRedirect)
-	forceHTTPSRedirect, _ := annotations.ParseBoolASAP(forceSSLRedirect)
+	var errs error
+	httpsRedirect, err := annotations.ParseBoolASAP(sslRedirect)
+	if err != nil && !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(sslRedirect, "must be true or false"))
+	}
+	forceHTTPSRedirect, err := annotations.ParseBoolASAP(forceSSLRedirect)
+	if err != nil && !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(forceSSLRedirect, "must be true or false"))
+	}
 	if httpsRedirect || forceHTTPSRedirect {
 		redirectConfig.httpsRedirect = true
 	}
@@ -73,32 +81,42 @@ func (r redirect) Parse(annotations Annotations, config *Ingress, _ *GlobalConte
 	// temporal redirect is firstly applied.
 	tr, err := annotations.ParseStringASAP(temporalRedirect)
 	if err != nil && !IsMissingAnnotations(err) {
-		return nil
+		return multierror.Append(errs, annotations.invalidValue(temporalRedirect, "must be an http or https url"))
 	}
-	if tr != "" && isValidURL(tr) == nil {
-		redirectConfig.URL = tr
-		redirectConfig.Code = defaultTemporalRedirectCode
-		return nil
+	if tr != "" {
+		if isValidURL(tr) == nil {
+			redirectConfig.URL = tr
+			redirectConfig.Code = defaultTemporalRedirectCode
+			return errs
+		}
+		errs = multierror.Append(errs, annotations.invalidValue(temporalRedirect, "must be an http or https url"))
 	}
 
 	// permanent redirect
 	// url
 	pr, err := annotations.ParseStringASAP(permanentRedirect)
 	if err != nil && !IsMissingAnnotations(err) {
-		return nil
+		return multierror.Append(errs, annotations.invalidValue(permanentRedirect, "must be an http or https url"))
 	}
-	if pr != "" && isValidURL(pr) == nil {
-		redirectConfig.URL = pr
+	if pr != "" {
+		if isValidURL(pr) == nil {
+			redirectConfig.URL = pr
+		} else {
+			errs = multierror.Append(errs, annotations.invalidValue(permanentRedirect, "must be an http or https url"))
+		}
 	}
 	// code
 	if prc, err := annotations.ParseIntASAP(permanentRedirectCode); err == nil {
 		if prc < http.StatusMultipleChoices || prc > http.StatusPermanentRedirect {
+			errs = multierror.Append(errs, annotations.invalidValue(permanentRedirectCode, "must be in 300-308"))
 			prc = defaultPermanentRedirectCode
 		}
 		redirectConfig.Code = prc
+	} else if !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(permanentRedirectCode, "must be an integer"))
 	}
 
-	return nil
+	return errs
 }
 
 func (r redirect) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
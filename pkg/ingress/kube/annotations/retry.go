@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/gogo/protobuf/types"
+	"github.com/hashicorp/go-multierror"
 
 	networking "istio.io/api/networking/v1alpha3"
 )
@@ -59,14 +60,19 @@ func (r retry) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 		config.Retry = retryConfig
 	}()
 
+	var errs error
 	if count, err := annotations.ParseInt32ASAP(retryCount); err == nil {
 		retryConfig.retryCount = count
+	} else if !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(retryCount, "must be an integer"))
 	}
 
 	if timeout, err := annotations.ParseIntASAP(perRetryTimeout); err == nil {
 		retryConfig.perRetryTimeout = &types.Duration{
 			Seconds: int64(timeout),
 		}
+	} else if !IsMissingAnnotations(err) {
+		errs = multierror.Append(errs, annotations.invalidValue(perRetryTimeout, "must be an integer"))
 	}
 
 	if retryOn, err := annotations.ParseStringASAP(retryOn); err == nil {
@@ -101,7 +107,7 @@ func (r retry) Parse(annotations Annotations, config *Ingress, _ *GlobalContext)
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func (r retry) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
@@ -47,9 +47,15 @@ func (r rewrite) Parse(annotations Annotations, config *Ingress, _ *GlobalContex
 		return nil
 	}
 
+	var err error
 	rewriteConfig := &RewriteConfig{}
 	rewriteConfig.RewriteTarget, _ = annotations.ParseStringASAP(rewriteTarget)
-	rewriteConfig.UseRegex, _ = annotations.ParseBoolASAP(useRegex)
+	rewriteConfig.UseRegex, err = annotations.ParseBoolASAP(useRegex)
+	if err != nil && !IsMissingAnnotations(err) {
+		err = annotations.invalidValue(useRegex, "must be true or false")
+	} else {
+		err = nil
+	}
 	rewriteConfig.RewriteHost, _ = annotations.ParseStringASAP(upstreamVhost)
 
 	if rewriteConfig.RewriteTarget != "" {
@@ -62,7 +68,7 @@ func (r rewrite) Parse(annotations Annotations, config *Ingress, _ *GlobalContex
 	}
 
 	config.Rewrite = rewriteConfig
-	return nil
+	return err
 }
 
 func (r rewrite) ApplyRoute(route *networking.HTTPRoute, config *Ingress) {
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/gogo/protobuf/types"
+	"github.com/hashicorp/go-multierror"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/model/credentials"
 
@@ -68,17 +69,20 @@ func (u upstreamTLS) Parse(annotations Annotations, config *Ingress, _ *GlobalCo
 		config.UpstreamTLS = upstreamTLSConfig
 	}()
 
+	var errs error
 	if proto, err := annotations.ParseStringASAP(backendProtocol); err == nil {
 		proto = strings.TrimSpace(strings.ToUpper(proto))
 		if validProtocols.MatchString(proto) {
 			upstreamTLSConfig.BackendProtocol = proto
+		} else {
+			errs = multierror.Append(errs, annotations.invalidValue(backendProtocol, "unsupported backend protocol"))
 		}
 	}
 
 	secretName, _ := annotations.ParseStringASAP(proxySSLSecret)
 	namespacedName := util.SplitNamespacedName(secretName)
 	if namespacedName.Name == "" {
-		return nil
+		return errs
 	}
 
 	if namespacedName.Namespace == "" {
@@ -89,6 +93,8 @@ func (u upstreamTLS) Parse(annotations Annotations, config *Ingress, _ *GlobalCo
 	if sslVerify, err := annotations.ParseStringASAP(proxySSLVerify); err == nil {
 		if OnOffRegex.MatchString(sslVerify) {
 			upstreamTLSConfig.SSLVerify = onOffToBool(sslVerify)
+		} else {
+			errs = multierror.Append(errs, annotations.invalidValue(proxySSLVerify, "must be on or off"))
 		}
 	}
 
@@ -97,10 +103,12 @@ func (u upstreamTLS) Parse(annotations Annotations, config *Ingress, _ *GlobalCo
 	if enableSNI, err := annotations.ParseStringASAP(proxySSLServerName); err == nil {
 		if OnOffRegex.MatchString(enableSNI) {
 			upstreamTLSConfig.SSLVerify = onOffToBool(enableSNI)
+		} else {
+			errs = multierror.Append(errs, annotations.invalidValue(proxySSLServerName, "must be on or off"))
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func (u upstreamTLS) ApplyTrafficPolicy(trafficPolicy *networking.TrafficPolicy_PortTrafficPolicy, config *Ingress) {
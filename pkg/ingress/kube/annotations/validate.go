@@ -0,0 +1,102 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"istio.io/istio/pilot/pkg/util/sets"
+)
+
+// knownAnnotations are the keys of the annotations supported by the parsers.
+var knownAnnotations = sets.NewSet(
+	// auth
+	authType, authRealm, authSecretAnn, authSecretTypeAnn,
+	// canary
+	enableCanary, canaryByHeader, canaryByHeaderValue, canaryByHeaderPattern, canaryByCookie,
+	canaryWeight, canaryWeightTotal,
+	// cors
+	enableCors, allowOrigin, allowMethods, allowHeaders, exposeHeaders, allowCredentials, maxAge,
+	// default backend
+	annDefaultBackend, customHTTPError,
+	destination,
+	// downstream tls
+	authTLSSecret, sslCipher,
+	enableHTTP3,
+	// ip access control
+	whitelist,
+	listenPortHTTP, listenPortHTTPS,
+	// load balance
+	loadBalanceAnnotation, upstreamHashBy, affinity, affinityMode, affinityCanaryBehavior,
+	sessionCookieName, sessionCookiePath, sessionCookieMaxAge, sessionCookieExpires,
+	// redirect
+	appRoot, temporalRedirect, permanentRedirect, permanentRedirectCode, sslRedirect, forceSSLRedirect,
+	// retry
+	retryCount, perRetryTimeout, retryOn,
+	// rewrite
+	rewriteTarget, useRegex, upstreamVhost,
+	// upstream tls
+	backendProtocol, proxySSLSecret, proxySSLVerify, proxySSLName, proxySSLServerName,
+)
+
+// UnknownHigressAnnotations returns the sorted keys of the higress annotations which are not
+// supported, e.g. the misspelled ones. The nginx annotations are not checked, because the
+// unsupported ones are allowed to be kept for the compatibility.
+func UnknownHigressAnnotations(annotations Annotations) []string {
+	prefix := HigressAnnotationsPrefix + "/"
+	var out []string
+	for key := range annotations {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if !knownAnnotations.Contains(strings.TrimPrefix(key, prefix)) {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// filterAllowed drops the known annotations which are not allowed, and reports them by the error.
+// The unknown annotations are kept, because they are never parsed.
+func (a Annotations) filterAllowed(allowed sets.Set) (Annotations, error) {
+	if allowed == nil {
+		return a, nil
+	}
+
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs error
+	out := make(Annotations, len(a))
+	for _, key := range keys {
+		name := strings.TrimPrefix(strings.TrimPrefix(key, DefaultAnnotationsPrefix+"/"), HigressAnnotationsPrefix+"/")
+		if name != key && knownAnnotations.Contains(name) && !allowed.Contains(name) {
+			errs = multierror.Append(errs, &AnnotationError{
+				Key:    name,
+				Value:  a[key],
+				Reason: "not allowed by the ingress class",
+			})
+			continue
+		}
+		out[key] = a[key]
+	}
+	return out, errs
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pilot/pkg/util/sets"
+)
+
+func TestUnknownHigressAnnotations(t *testing.T) {
+	input := Annotations{
+		buildHigressAnnotationKey(destination):           "foo.com:80",
+		buildHigressAnnotationKey("canary-wieght"):       "10",
+		buildHigressAnnotationKey("auth-typ"):            "basic",
+		buildNginxAnnotationKey("configuration-snippet"): "",
+		"internal.higress.io/cluster-id":                 "",
+	}
+	assert.Equal(t, []string{"higress.io/auth-typ", "higress.io/canary-wieght"}, UnknownHigressAnnotations(input))
+	assert.Nil(t, UnknownHigressAnnotations(Annotations{}))
+}
+
+func TestParseWithAllowedAnnotations(t *testing.T) {
+	handler := NewAnnotationHandlerManager()
+	globalContext := &GlobalContext{
+		WatchedSecrets:     sets.NewSet(),
+		AllowedAnnotations: sets.NewSet(sslRedirect),
+		Defaults:           Defaults{retryCount: "5"},
+	}
+
+	config := &Ingress{}
+	err := handler.Parse(Annotations{
+		buildNginxAnnotationKey(sslRedirect):    "true",
+		buildHigressAnnotationKey(retryCount):   "2",
+		buildNginxAnnotationKey(enableCanary):   "true",
+		buildNginxAnnotationKey("proxy-buffer"): "on",
+		"kubernetes.io/ingress.class":           "higress",
+	}, config, globalContext)
+	assert.True(t, config.Redirect.httpsRedirect)
+	// The disallowed annotations are dropped, while the defaults of class still apply.
+	assert.Nil(t, config.Canary)
+	assert.Equal(t, int32(5), config.Retry.retryCount)
+
+	errs := AnnotationErrors(err)
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, &AnnotationError{Key: retryCount, Value: "2", Reason: "not allowed by the ingress class"}, errs[0])
+	assert.Equal(t, &AnnotationError{Key: enableCanary, Value: "true", Reason: "not allowed by the ingress class"}, errs[1])
+
+	// All the annotations are allowed without the allowed set.
+	globalContext.AllowedAnnotations = nil
+	config = &Ingress{}
+	assert.NoError(t, handler.Parse(Annotations{buildHigressAnnotationKey(retryCount): "2"}, config, globalContext))
+	assert.Equal(t, int32(2), config.Retry.retryCount)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"istio.io/istio/pkg/config"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// HostSetting is a setting which takes effect on the whole host, so all
+// ingresses sharing the host should agree on its value.
+type HostSetting string
+
+const (
+	TLSSecretSetting HostSetting = "tls-secret"
+
+	MTLSCASetting HostSetting = "mtls-ca"
+
+	CipherSuitesSetting HostSetting = "cipher-suites"
+
+	AppRootSetting HostSetting = "app-root"
+
+	ListenPortSetting HostSetting = "listen-port"
+
+	HTTP3Setting HostSetting = "http3"
+
+	GatewaySelectorSetting HostSetting = "gateway-selector"
+)
+
+var hostSettingOrder = []HostSetting{
+	TLSSecretSetting,
+	MTLSCASetting,
+	CipherSuitesSetting,
+	AppRootSetting,
+	ListenPortSetting,
+	HTTP3Setting,
+	GatewaySelectorSetting,
+}
+
+// HostSettings records the host-scoped settings contributed by one ingress.
+type HostSettings struct {
+	ClusterId string
+	Ingress   *config.Config
+	Values    map[HostSetting]string
+}
+
+// NewHostSettings extracts the host-scoped settings from the ingress.
+// The tlsSecret format is cluster id/namespace/name.
+func NewHostSettings(wrapper *WrapperConfig, clusterId, tlsSecret string) *HostSettings {
+	values := map[HostSetting]string{}
+	if tlsSecret != "" {
+		values[TLSSecretSetting] = tlsSecret
+	}
+
+	annotationsConfig := wrapper.AnnotationsConfig
+	if downstreamTLS := annotationsConfig.DownstreamTLS; downstreamTLS != nil {
+		if downstreamTLS.CASecretName.Name != "" {
+			values[MTLSCASetting] = downstreamTLS.CASecretName.String()
+		}
+		if len(downstreamTLS.CipherSuites) != 0 {
+			values[CipherSuitesSetting] = strings.Join(downstreamTLS.CipherSuites, ":")
+		}
+	}
+	if redirect := annotationsConfig.Redirect; redirect != nil && redirect.AppRoot != "" {
+		values[AppRootSetting] = redirect.AppRoot
+	}
+	if listenPort := annotationsConfig.ListenPort; listenPort != nil {
+		values[ListenPortSetting] = listenPort.String()
+	}
+	if http3 := annotationsConfig.HTTP3; http3 != nil {
+		values[HTTP3Setting] = strconv.FormatBool(http3.Enabled)
+	}
+	// The ingresses without the gateway selector of the ingress class never conflict.
+	if wrapper.GatewaySelector != nil {
+		values[GatewaySelectorSetting] = labels.Set(wrapper.GatewaySelector).String()
+	}
+
+	return &HostSettings{
+		ClusterId: clusterId,
+		Ingress:   wrapper.Config,
+		Values:    values,
+	}
+}
+
+type HostSettingsCache struct {
+	// host as key, ingresses are kept in the order of conversion.
+	hosts map[string][]*HostSettings
+}
+
+func NewHostSettingsCache() *HostSettingsCache {
+	return &HostSettingsCache{
+		hosts: map[string][]*HostSettings{},
+	}
+}
+
+func (h *HostSettingsCache) Add(host string, settings *HostSettings) {
+	for _, exist := range h.hosts[host] {
+		// The same ingress may define several rules for one host.
+		if exist.Ingress == settings.Ingress {
+			return
+		}
+	}
+	h.hosts[host] = append(h.hosts[host], settings)
+}
+
+type ConflictSource struct {
+	ClusterId string         `json:"cluster"`
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Value     string         `json:"value"`
+	Ingress   *config.Config `json:"-"`
+}
+
+// HostConflict describes a host-scoped setting on which the ingresses of one host disagree.
+type HostConflict struct {
+	Host    string      `json:"host"`
+	Setting HostSetting `json:"setting"`
+	// Effective is the value actually applied to the host.
+	Effective string           `json:"effective"`
+	Sources   []ConflictSource `json:"sources"`
+}
+
+// Key identifies the conflict, it changes when any involved ingress or value changes.
+func (h HostConflict) Key() string {
+	var builder strings.Builder
+	builder.WriteString(h.Host + "/" + string(h.Setting))
+	for _, source := range h.Sources {
+		builder.WriteString("/" + source.ClusterId + "/" + source.Namespace + "/" + source.Name + "=" + source.Value)
+	}
+	return builder.String()
+}
+
+func (h HostConflict) Message() string {
+	var sources []string
+	for _, source := range h.Sources {
+		sources = append(sources, fmt.Sprintf("ingress %s/%s within cluster %s sets %q",
+			source.Namespace, source.Name, source.ClusterId, source.Value))
+	}
+	return fmt.Sprintf("%s of host %s is conflicted: %s, only %q takes effect",
+		h.Setting, h.Host, strings.Join(sources, ", "), h.Effective)
+}
+
+// Analyze reports the host-scoped settings whose values are different across ingresses.
+// Ingresses which don't set a setting never conflict with others.
+func (h *HostSettingsCache) Analyze() []HostConflict {
+	var out []HostConflict
+	for host, ingresses := range h.hosts {
+		if len(ingresses) < 2 {
+			continue
+		}
+
+		for _, setting := range hostSettingOrder {
+			var sources []ConflictSource
+			values := map[string]struct{}{}
+			for _, settings := range ingresses {
+				value, exist := settings.Values[setting]
+				if !exist {
+					continue
+				}
+				values[value] = struct{}{}
+				sources = append(sources, ConflictSource{
+					ClusterId: settings.ClusterId,
+					Namespace: settings.Ingress.Namespace,
+					Name:      settings.Ingress.Name,
+					Value:     value,
+					Ingress:   settings.Ingress,
+				})
+			}
+			if len(values) < 2 {
+				continue
+			}
+
+			// The app root of the latest ingress wins, for others the first one wins.
+			effective := sources[0].Value
+			if setting == AppRootSetting {
+				effective = sources[len(sources)-1].Value
+			}
+			out = append(out, HostConflict{
+				Host:      host,
+				Setting:   setting,
+				Effective: effective,
+				Sources:   sources,
+			})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Host == out[j].Host {
+			return out[i].Setting < out[j].Setting
+		}
+		return out[i].Host < out[j].Host
+	})
+	return out
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"istio.io/istio/pkg/config"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWrapper(name string, annotationsConfig *annotations.Ingress) *WrapperConfig {
+	return &WrapperConfig{
+		Config: &config.Config{
+			Meta: config.Meta{
+				Name:      name,
+				Namespace: "default",
+			},
+		},
+		AnnotationsConfig: annotationsConfig,
+	}
+}
+
+func TestHostSettingsCacheAnalyze(t *testing.T) {
+	foo := &annotations.Ingress{
+		DownstreamTLS: &annotations.DownstreamTLSConfig{
+			CipherSuites: []string{"ECDHE-RSA-AES128-GCM-SHA256", "AES256-SHA"},
+		},
+	}
+	bar := &annotations.Ingress{
+		DownstreamTLS: &annotations.DownstreamTLSConfig{
+			CipherSuites: []string{"AES256-SHA"},
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		input  func(cache *HostSettingsCache)
+		expect []HostConflict
+	}{
+		{
+			name: "same tls secret",
+			input: func(cache *HostSettingsCache) {
+				cache.Add("a.com", NewHostSettings(newTestWrapper("a", &annotations.Ingress{}), "c1", "c1/default/foo"))
+				cache.Add("a.com", NewHostSettings(newTestWrapper("b", &annotations.Ingress{}), "c1", "c1/default/foo"))
+			},
+		},
+		{
+			name: "only one ingress sets tls secret",
+			input: func(cache *HostSettingsCache) {
+				cache.Add("a.com", NewHostSettings(newTestWrapper("a", &annotations.Ingress{}), "c1", "c1/default/foo"))
+				cache.Add("a.com", NewHostSettings(newTestWrapper("b", &annotations.Ingress{}), "c1", ""))
+			},
+		},
+		{
+			name: "different hosts",
+			input: func(cache *HostSettingsCache) {
+				cache.Add("a.com", NewHostSettings(newTestWrapper("a", &annotations.Ingress{}), "c1", "c1/default/foo"))
+				cache.Add("b.com", NewHostSettings(newTestWrapper("b", &annotations.Ingress{}), "c1", "c1/default/bar"))
+			},
+		},
+		{
+			name: "same ingress added twice",
+			input: func(cache *HostSettingsCache) {
+				wrapper := newTestWrapper("a", &annotations.Ingress{})
+				cache.Add("a.com", NewHostSettings(wrapper, "c1", "c1/default/foo"))
+				cache.Add("a.com", NewHostSettings(wrapper, "c1", "c1/default/bar"))
+			},
+		},
+		{
+			name: "tls secret and cipher suites conflict",
+			input: func(cache *HostSettingsCache) {
+				cache.Add("a.com", NewHostSettings(newTestWrapper("a", foo), "c1", "c1/default/foo"))
+				cache.Add("a.com", NewHostSettings(newTestWrapper("b", bar), "c1", "c1/default/bar"))
+			},
+			expect: []HostConflict{
+				{
+					Host:      "a.com",
+					Setting:   CipherSuitesSetting,
+					Effective: "ECDHE-RSA-AES128-GCM-SHA256:AES256-SHA",
+					Sources: []ConflictSource{
+						{ClusterId: "c1", Namespace: "default", Name: "a", Value: "ECDHE-RSA-AES128-GCM-SHA256:AES256-SHA"},
+						{ClusterId: "c1", Namespace: "default", Name: "b", Value: "AES256-SHA"},
+					},
+				},
+				{
+					Host:      "a.com",
+					Setting:   TLSSecretSetting,
+					Effective: "c1/default/foo",
+					Sources: []ConflictSource{
+						{ClusterId: "c1", Namespace: "default", Name: "a", Value: "c1/default/foo"},
+						{ClusterId: "c1", Namespace: "default", Name: "b", Value: "c1/default/bar"},
+					},
+				},
+			},
+		},
+		{
+			name: "http3 conflict",
+			input: func(cache *HostSettingsCache) {
+				cache.Add("a.com", NewHostSettings(newTestWrapper("a", &annotations.Ingress{
+					HTTP3: &annotations.HTTP3Config{Enabled: true},
+				}), "c1", ""))
+				cache.Add("a.com", NewHostSettings(newTestWrapper("b", &annotations.Ingress{}), "c1", ""))
+				cache.Add("a.com", NewHostSettings(newTestWrapper("c", &annotations.Ingress{
+					HTTP3: &annotations.HTTP3Config{Enabled: false},
+				}), "c1", ""))
+			},
+			expect: []HostConflict{
+				{
+					Host:      "a.com",
+					Setting:   HTTP3Setting,
+					Effective: "true",
+					Sources: []ConflictSource{
+						{ClusterId: "c1", Namespace: "default", Name: "a", Value: "true"},
+						{ClusterId: "c1", Namespace: "default", Name: "c", Value: "false"},
+					},
+				},
+			},
+		},
+		{
+			name: "the last app root wins",
+			input: func(cache *HostSettingsCache) {
+				cache.Add("a.com", NewHostSettings(newTestWrapper("a", &annotations.Ingress{
+					Redirect: &annotations.RedirectConfig{AppRoot: "/foo"},
+				}), "c1", ""))
+				cache.Add("a.com", NewHostSettings(newTestWrapper("b", &annotations.Ingress{
+					Redirect: &annotations.RedirectConfig{AppRoot: "/bar"},
+				}), "c2", ""))
+			},
+			expect: []HostConflict{
+				{
+					Host:      "a.com",
+					Setting:   AppRootSetting,
+					Effective: "/bar",
+					Sources: []ConflictSource{
+						{ClusterId: "c1", Namespace: "default", Name: "a", Value: "/foo"},
+						{ClusterId: "c2", Namespace: "default", Name: "b", Value: "/bar"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cache := NewHostSettingsCache()
+			testCase.input(cache)
+			result := cache.Analyze()
+			for i := range result {
+				for j := range result[i].Sources {
+					result[i].Sources[j].Ingress = nil
+				}
+			}
+			assert.Equal(t, testCase.expect, result)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config"
 	gatewaytool "istio.io/istio/pkg/config/gateway"
+	"k8s.io/apimachinery/pkg/runtime"
 	listerv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
@@ -36,6 +37,11 @@ type ServiceKey struct {
 type WrapperConfig struct {
 	Config            *config.Config
 	AnnotationsConfig *annotations.Ingress
+	// AnnotationErrors are the errors of the invalid annotations skipped by the parsers.
+	AnnotationErrors []*annotations.AnnotationError
+	// GatewaySelector is set by the parameters of the ingress class or the label of the namespace,
+	// it's nil if the gateway selector of the options is used.
+	GatewaySelector map[string]string
 }
 
 type WrapperGateway struct {
@@ -43,6 +49,9 @@ type WrapperGateway struct {
 	WrapperConfig *WrapperConfig
 	ClusterId     string
 	Host          string
+	// Scope separates the gateways of the same host, e.g. the tcp listeners of different gateways,
+	// and the gateway is named after the host if it is empty.
+	Scope string
 }
 
 func (w *WrapperGateway) IsHTTPS() bool {
@@ -84,6 +93,22 @@ func (w *WrapperHTTPRoute) PathFormat() string {
 	return strings.Join([]string{w.Host, string(w.OriginPathType), w.OriginPath}, "-")
 }
 
+// WrapperTLSRoute is the tls route of the host, which is routed by sni without terminating tls.
+type WrapperTLSRoute struct {
+	TLSRoute      *networking.TLSRoute
+	WrapperConfig *WrapperConfig
+	ClusterId     string
+	Host          string
+}
+
+// WrapperTCPRoute is the tcp route of the host, which is routed by port.
+type WrapperTCPRoute struct {
+	TCPRoute      *networking.TCPRoute
+	WrapperConfig *WrapperConfig
+	ClusterId     string
+	Host          string
+}
+
 type WrapperVirtualService struct {
 	VirtualService           *networking.VirtualService
 	WrapperConfig            *WrapperConfig
@@ -96,6 +121,12 @@ type WrapperTrafficPolicy struct {
 	WrapperConfig *WrapperConfig
 }
 
+// WrapperExternalName is the external name of the ExternalName service used as the backend.
+type WrapperExternalName struct {
+	Host          string
+	WrapperConfig *WrapperConfig
+}
+
 type WrapperDestinationRule struct {
 	DestinationRule *networking.DestinationRule
 	WrapperConfig   *WrapperConfig
@@ -109,6 +140,18 @@ type IngressController interface {
 
 	List() []config.Config
 
+	// ToConfig converts the object to the config in the same way as List, it returns false if the
+	// object is not processed by the controller.
+	ToConfig(obj runtime.Object) (*config.Config, bool)
+
+	// ClassParamsName returns the name of the IngressClassParams referenced by the IngressClass
+	// of the ingress, it's empty if the class doesn't reference any.
+	ClassParamsName(cfg *config.Config) string
+
+	// NamespaceGatewaySelector returns the gateway selector set by the label of the namespace, it's
+	// nil if the namespace doesn't set any. The changes are notified by the handlers of Namespace.
+	NamespaceGatewaySelector(namespace string) map[string]string
+
 	ServiceLister() listerv1.ServiceLister
 
 	SecretLister() listerv1.SecretLister
@@ -123,6 +166,10 @@ type IngressController interface {
 
 	ConvertTrafficPolicy(convertOptions *ConvertOptions, wrapper *WrapperConfig) error
 
+	// ConvertServiceEntry records the ExternalName services used as the backends, which are
+	// converted to the dns resolved service entries.
+	ConvertServiceEntry(convertOptions *ConvertOptions, wrapper *WrapperConfig) error
+
 	// Run until a signal is received
 	Run(stop <-chan struct{})
 
@@ -0,0 +1,127 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"istio.io/istio/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	EventComponent = "higress-controller"
+
+	IngressV1APIVersion = "networking.k8s.io/v1"
+
+	IngressV1Beta1APIVersion = "networking.k8s.io/v1beta1"
+
+	// HostSettingConflictReason is used when ingresses of one host disagree on a host-scoped setting.
+	HostSettingConflictReason = "HostSettingConflict"
+
+	// InvalidIngressReason is used for the conversion problems without a specific reason.
+	InvalidIngressReason = "InvalidIngress"
+
+	// eventBurstSize and eventQPS limit the events on one ingress, the excess ones are dropped.
+	eventBurstSize = 10
+	eventQPS       = 1.0 / 60
+)
+
+var eventReasons = map[Event]string{
+	EmptyRule:             "EmptyRule",
+	MissingSecret:         "MissingSecret",
+	InvalidBackendService: "InvalidBackendService",
+	DuplicatedRoute:       "DuplicatedRoute",
+	DuplicatedTls:         "DuplicatedTLS",
+	PortNameResolveError:  "PortNameResolveError",
+	InvalidAnnotation:     "InvalidAnnotation",
+	InvalidClassParams:    "InvalidClassParams",
+}
+
+// EventReason returns the reason of the kubernetes event reporting the conversion event.
+func EventReason(event Event) string {
+	if reason, exist := eventReasons[event]; exist {
+		return reason
+	}
+	return InvalidIngressReason
+}
+
+// IngressProblem is a problem found when converting an ingress, which is reported by
+// an event on the ingress.
+type IngressProblem struct {
+	ClusterId string
+	// Host is empty if the problem is about the whole ingress.
+	Host    string
+	Event   Event
+	Message string
+	Ingress *config.Config
+	// PreIngress is the ingress conflicting with this one if any.
+	PreIngress *config.Config
+}
+
+// Key identifies the problem, it changes when the ingress or the message changes.
+func (p IngressProblem) Key() string {
+	return p.ClusterId + "/" + p.Ingress.Namespace + "/" + p.Ingress.Name + "/" + p.Ingress.UID + "/" +
+		string(p.Event) + "/" + p.Message
+}
+
+// IngressEventRecorder emits kubernetes events on the ingresses of one cluster.
+type IngressEventRecorder struct {
+	recorder   record.EventRecorder
+	apiVersion string
+}
+
+func NewIngressEventRecorder(client kubernetes.Interface, apiVersion string) *IngressEventRecorder {
+	broadcaster := record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{
+		BurstSize: eventBurstSize,
+		QPS:       eventQPS,
+	})
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return &IngressEventRecorder{
+		recorder:   broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: EventComponent}),
+		apiVersion: apiVersion,
+	}
+}
+
+// NewIngressEventRecorderWithRecorder wraps the given recorder, e.g. a fake one in the tests.
+func NewIngressEventRecorderWithRecorder(recorder record.EventRecorder, apiVersion string) *IngressEventRecorder {
+	return &IngressEventRecorder{
+		recorder:   recorder,
+		apiVersion: apiVersion,
+	}
+}
+
+// Warning records a warning event on the ingress, it is safe to be called on a nil recorder.
+// The configs with group version kind, e.g. the routes of gateway api, are referred by their own kind.
+func (r *IngressEventRecorder) Warning(ingress *config.Config, reason, message string) {
+	if r == nil || ingress == nil {
+		return
+	}
+
+	kind, apiVersion := "Ingress", r.apiVersion
+	if ingress.GroupVersionKind.Kind != "" {
+		kind, apiVersion = ingress.GroupVersionKind.Kind, ingress.GroupVersionKind.GroupVersion()
+	}
+	r.recorder.Event(&v1.ObjectReference{
+		Kind:       kind,
+		APIVersion: apiVersion,
+		Namespace:  ingress.Namespace,
+		Name:       ingress.Name,
+		UID:        types.UID(ingress.UID),
+	}, v1.EventTypeWarning, reason, message)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEventReason(t *testing.T) {
+	assert.Equal(t, "DuplicatedRoute", EventReason(DuplicatedRoute))
+	assert.Equal(t, "DuplicatedTLS", EventReason(DuplicatedTls))
+	assert.Equal(t, "EmptyRule", EventReason(EmptyRule))
+	assert.Equal(t, InvalidIngressReason, EventReason(Unknown))
+}
+
+func TestIngressEventRecorderWarning(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	recorder := NewIngressEventRecorderWithRecorder(fakeRecorder, IngressV1APIVersion)
+	recorder.Warning(&config.Config{Meta: config.Meta{Name: "foo", Namespace: "default"}}, "EmptyRule", "no rules")
+	recorder.Warning(&config.Config{
+		Meta: config.Meta{GroupVersionKind: gvk.HTTPRoute, Name: "bar", Namespace: "default"},
+	}, "DuplicatedRoute", "duplicated")
+	recorder.Warning(nil, "EmptyRule", "ignored")
+	assert.Equal(t, "Warning EmptyRule no rules", <-fakeRecorder.Events)
+	assert.Equal(t, "Warning DuplicatedRoute duplicated", <-fakeRecorder.Events)
+	assert.Len(t, fakeRecorder.Events, 0)
+
+	// It is safe to be called on a nil recorder.
+	var nilRecorder *IngressEventRecorder
+	nilRecorder.Warning(&config.Config{}, "EmptyRule", "no rules")
+}
+
+func TestIngressProblemKey(t *testing.T) {
+	ingress := &config.Config{Meta: config.Meta{Name: "foo", Namespace: "default", UID: "1"}}
+	problem := IngressProblem{ClusterId: "c1", Event: EmptyRule, Message: "no rules", Ingress: ingress}
+	same := problem
+	assert.Equal(t, problem.Key(), same.Key())
+
+	recreated := problem
+	recreated.Ingress = &config.Config{Meta: config.Meta{Name: "foo", Namespace: "default", UID: "2"}}
+	assert.NotEqual(t, problem.Key(), recreated.Key())
+
+	changed := problem
+	changed.Message = "other"
+	assert.NotEqual(t, problem.Key(), changed.Key())
+}
@@ -34,11 +34,17 @@ const (
 	DuplicatedTls Event = "duplicated-tls"
 
 	PortNameResolveError Event = "port-name-resolve-error"
+
+	InvalidAnnotation Event = "invalid-annotation"
+
+	InvalidClassParams Event = "invalid-class-params"
 )
 
 var (
 	clusterTag  = monitoring.MustCreateLabel("cluster")
 	invalidType = monitoring.MustCreateLabel("type")
+	// annotationTag is the key of the invalid annotation, which is empty for the other invalid types.
+	annotationTag = monitoring.MustCreateLabel("annotation")
 
 	// totalIngresses tracks the total number of ingress
 	totalIngresses = monitoring.NewGauge(
@@ -50,7 +56,7 @@ var (
 	totalInvalidIngress = monitoring.NewSum(
 		"pilot_total_invalid_ingresses",
 		"Total invalid ingresses known to pilot.",
-		monitoring.WithLabels(clusterTag, invalidType),
+		monitoring.WithLabels(clusterTag, invalidType, annotationTag),
 	)
 )
 
@@ -64,5 +70,29 @@ func RecordIngressNumber(cluster string, number int) {
 }
 
 func IncrementInvalidIngress(cluster string, event Event) {
-	totalInvalidIngress.With(clusterTag.Value(cluster), invalidType.Value(string(event))).Increment()
+	totalInvalidIngress.With(clusterTag.Value(cluster), invalidType.Value(string(event)), annotationTag.Value("")).Increment()
+}
+
+// InvalidIngress is an invalid ingress found by the conversion.
+type InvalidIngress struct {
+	ClusterId string
+	Event     Event
+}
+
+// AddInvalidIngress records the invalid ingress found by the conversion, see RecordInvalidIngresses.
+func (c *ConvertOptions) AddInvalidIngress(cluster string, event Event) {
+	c.InvalidIngresses = append(c.InvalidIngresses, InvalidIngress{ClusterId: cluster, Event: event})
+}
+
+// RecordInvalidIngresses increments the metrics of the invalid ingresses found by the conversion.
+func RecordInvalidIngresses(invalidIngresses []InvalidIngress) {
+	for _, invalid := range invalidIngresses {
+		IncrementInvalidIngress(invalid.ClusterId, invalid.Event)
+	}
+}
+
+// IncrementInvalidAnnotation records an invalid annotation, whose key is without the prefix.
+func IncrementInvalidAnnotation(cluster string, annotation string) {
+	totalInvalidIngress.With(clusterTag.Value(cluster), invalidType.Value(string(InvalidAnnotation)),
+		annotationTag.Value(annotation)).Increment()
 }
@@ -28,6 +28,7 @@ import (
 	"istio.io/istio/pkg/config/schema/collections"
 	"k8s.io/apimachinery/pkg/labels"
 
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
 	. "github.com/alibaba/higress/pkg/ingress/log"
 )
 
@@ -66,6 +67,9 @@ const (
 	AppKey            = "app"
 	AppValue          = "higress-gateway"
 	SvcHostNameSuffix = ".multiplenic"
+
+	// HTTPSPort is the port of the ExternalName services whose upstream tls is originated by default.
+	HTTPSPort = 443
 )
 
 var (
@@ -78,6 +82,7 @@ var (
 		collections.IstioNetworkingV1Alpha3Gateways,
 		collections.IstioNetworkingV1Alpha3Destinationrules,
 		collections.IstioNetworkingV1Alpha3Envoyfilters,
+		collections.IstioNetworkingV1Alpha3Serviceentries,
 	)
 
 	clusterPrefix    string
@@ -101,6 +106,27 @@ type Options struct {
 	SystemNamespace      string
 	GatewaySelectorKey   string
 	GatewaySelectorValue string
+	// NamespaceGatewayLabel is the label of the namespaces whose value replaces the GatewaySelectorValue
+	// for the ingresses in them, so that they are served by the other gateway deployments.
+	NamespaceGatewayLabel string
+	// WatchNamespaceSelector is the label selector of the watched namespaces, which are also
+	// restricted to the comma separated names of WatchNamespace if it's set.
+	WatchNamespaceSelector string
+	// EnableHTTP3 enables HTTP/3 for all https hosts, unless it's overridden by annotation.
+	EnableHTTP3 bool
+	// TCPServicesConfigMap and UDPServicesConfigMap are in the form of namespace/name,
+	// the namespace defaults to the system namespace.
+	TCPServicesConfigMap string
+	UDPServicesConfigMap string
+	// GatewayOptionsConfigMap is in the form of namespace/name, it holds the listener options of gateway.
+	GatewayOptionsConfigMap string
+	// AnnotationDefaultsConfigMap is in the form of namespace/name, it holds the default values of
+	// the annotations for all the ingresses.
+	AnnotationDefaultsConfigMap string
+	// EnableIngressClassParams applies the IngressClassParams referenced by the ingress classes.
+	EnableIngressClassParams bool
+	// EnableGatewayAPI enables converting the gateway api resources of the GatewayClass managed by higress.
+	EnableGatewayAPI bool
 }
 
 type BasicAuthRules struct {
@@ -118,7 +144,7 @@ type IngressDomainCache struct {
 	// host as key
 	Valid map[string]*IngressDomainBuilder
 
-	Invalid []model.IngressDomain
+	Invalid []*IngressDomainBuilder
 }
 
 func NewIngressDomainCache() *IngressDomainCache {
@@ -134,10 +160,31 @@ func (i *IngressDomainCache) Extract() model.IngressDomainCollection {
 		valid = append(valid, builder.Build())
 	}
 
+	var invalid []model.IngressDomain
+	for _, builder := range i.Invalid {
+		invalid = append(invalid, builder.Build())
+	}
+
 	return model.IngressDomainCollection{
 		Valid:   valid,
-		Invalid: i.Invalid,
+		Invalid: invalid,
+	}
+}
+
+// Problems returns the problems of the invalid domains.
+func (i *IngressDomainCache) Problems() []IngressProblem {
+	var out []IngressProblem
+	for _, builder := range i.Invalid {
+		out = append(out, IngressProblem{
+			ClusterId:  builder.ClusterId,
+			Host:       builder.Host,
+			Event:      builder.Event,
+			Message:    builder.Build().Error,
+			Ingress:    builder.Ingress,
+			PreIngress: builder.PreIngress,
+		})
 	}
+	return out
 }
 
 type ConvertOptions struct {
@@ -159,11 +206,29 @@ type ConvertOptions struct {
 	// host -> routes
 	HTTPRoutes map[string][]*WrapperHTTPRoute
 
+	// IngressProblems are the problems of the ingresses rejected as a whole, e.g. with empty rules.
+	IngressProblems []IngressProblem
+
+	// InvalidIngresses are recorded to the metrics by the caller of the conversion, so that the
+	// conversion has no side effect, e.g. when it's a dry run.
+	InvalidIngresses []InvalidIngress
+
+	// host -> routes
+	TLSRoutes map[string][]*WrapperTLSRoute
+
+	// scope -> routes, the tcp routes of different listeners have different scopes.
+	TCPRoutes map[string][]*WrapperTCPRoute
+
 	CanaryIngresses []*WrapperConfig
 
 	Service2TrafficPolicy map[ServiceKey]*WrapperTrafficPolicy
 
+	Service2ExternalName map[ServiceKey]*WrapperExternalName
+
 	HasDefaultBackend bool
+
+	// Record host-scoped settings from ingress, used to detect conflicts
+	HostSettingsCache *HostSettingsCache
 }
 
 // CreateOptions obtain options from cluster id.
@@ -201,7 +266,7 @@ func CreateOptions(clusterId cluster.ID) Options {
 
 type IngressRouteCache struct {
 	routes  map[string]*IngressRouteBuilder
-	invalid []model.IngressRoute
+	invalid []*IngressRouteBuilder
 }
 
 func NewIngressRouteCache() *IngressRouteCache {
@@ -219,6 +284,8 @@ func (i *IngressRouteCache) New(route *WrapperHTTPRoute) *IngressRouteBuilder {
 		Host:      route.Host,
 		Event:     Normal,
 		Ingress:   route.WrapperConfig.Config,
+		// The routes are still valid with the invalid annotations skipped.
+		AnnotationErrors: route.WrapperConfig.AnnotationErrors,
 	}
 }
 
@@ -231,6 +298,8 @@ func (i *IngressRouteCache) NewAndAdd(route *WrapperHTTPRoute) {
 		Host:      route.Host,
 		Event:     Normal,
 		Ingress:   route.WrapperConfig.Config,
+		// The routes are still valid with the invalid annotations skipped.
+		AnnotationErrors: route.WrapperConfig.AnnotationErrors,
 	}
 
 	// Only care about the first destination
@@ -251,7 +320,7 @@ func (i *IngressRouteCache) NewAndAdd(route *WrapperHTTPRoute) {
 func (i *IngressRouteCache) Add(builder *IngressRouteBuilder) {
 	if builder.Event != Normal {
 		builder.RouteName = "invalid-route"
-		i.invalid = append(i.invalid, builder.Build())
+		i.invalid = append(i.invalid, builder)
 		return
 	}
 
@@ -285,10 +354,42 @@ func (i *IngressRouteCache) Extract() model.IngressRouteCollection {
 		valid = append(valid, builder.Build())
 	}
 
+	var invalid []model.IngressRoute
+	for _, builder := range i.invalid {
+		invalid = append(invalid, builder.Build())
+	}
+
 	return model.IngressRouteCollection{
 		Valid:   valid,
-		Invalid: i.invalid,
+		Invalid: invalid,
+	}
+}
+
+// Problems returns the problems of the invalid routes.
+func (i *IngressRouteCache) Problems() []IngressProblem {
+	var out []IngressProblem
+	for _, builder := range i.invalid {
+		out = append(out, IngressProblem{
+			ClusterId:  builder.ClusterId,
+			Host:       builder.Host,
+			Event:      builder.Event,
+			Message:    builder.Build().Error,
+			Ingress:    builder.Ingress,
+			PreIngress: builder.PreIngress,
+		})
 	}
+	return out
+}
+
+// InvalidRoutesOf returns the invalid routes defined in the ingress.
+func (i *IngressRouteCache) InvalidRoutesOf(ingress *config.Config) []model.IngressRoute {
+	var out []model.IngressRoute
+	for _, builder := range i.invalid {
+		if builder.Ingress == ingress {
+			out = append(out, builder.Build())
+		}
+	}
+	return out
 }
 
 type IngressRouteBuilder struct {
@@ -302,6 +403,8 @@ type IngressRouteBuilder struct {
 	Event       Event
 	Ingress     *config.Config
 	PreIngress  *config.Config
+	// AnnotationErrors are reported by the route if there is no other error.
+	AnnotationErrors []*annotations.AnnotationError
 }
 
 func (i *IngressRouteBuilder) Build() model.IngressRoute {
@@ -336,6 +439,9 @@ func (i *IngressRouteBuilder) Build() model.IngressRoute {
 			i.ClusterId,
 		)
 	}
+	if errorMsg == "" && len(i.AnnotationErrors) > 0 {
+		errorMsg = annotations.JoinAnnotationErrors(i.AnnotationErrors)
+	}
 
 	ingressRoute := model.IngressRoute{
 		Name:            i.RouteName,
@@ -375,6 +481,8 @@ type IngressDomainBuilder struct {
 	SecretName string
 	Ingress    *config.Config
 	PreIngress *config.Config
+	// AnnotationErrors are reported by the domain if there is no other error.
+	AnnotationErrors []*annotations.AnnotationError
 }
 
 func (i *IngressDomainBuilder) Build() model.IngressDomain {
@@ -400,6 +508,9 @@ func (i *IngressDomainBuilder) Build() model.IngressDomain {
 			preClusterId,
 		)
 	}
+	if errorMsg == "" && len(i.AnnotationErrors) > 0 {
+		errorMsg = annotations.JoinAnnotationErrors(i.AnnotationErrors)
+	}
 
 	return model.IngressDomain{
 		Host:         i.Host,
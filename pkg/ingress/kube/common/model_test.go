@@ -0,0 +1,114 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/config"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+)
+
+func TestBuildWithAnnotationErrors(t *testing.T) {
+	ingress := &config.Config{Meta: config.Meta{Name: "foo", Namespace: "default"}}
+	preIngress := &config.Config{Meta: config.Meta{Name: "bar", Namespace: "default"}}
+	annotationErrors := []*annotations.AnnotationError{
+		{Key: "ssl-redirect", Value: "yes", Reason: "must be true or false"},
+	}
+	annotationMessage := `invalid annotation ssl-redirect="yes": must be true or false`
+
+	route := (&IngressRouteBuilder{
+		Host:             "foo.com",
+		Path:             "/",
+		Event:            Normal,
+		Ingress:          ingress,
+		AnnotationErrors: annotationErrors,
+	}).Build()
+	assert.Equal(t, annotationMessage, route.Error)
+
+	// The conversion error takes precedence.
+	route = (&IngressRouteBuilder{
+		Host:             "foo.com",
+		Path:             "/",
+		Event:            DuplicatedRoute,
+		Ingress:          ingress,
+		PreIngress:       preIngress,
+		AnnotationErrors: annotationErrors,
+	}).Build()
+	assert.Contains(t, route.Error, "is already defined in ingress default/bar")
+
+	domain := (&IngressDomainBuilder{
+		Host:             "foo.com",
+		Protocol:         HTTP,
+		Event:            Normal,
+		Ingress:          ingress,
+		AnnotationErrors: annotationErrors,
+	}).Build()
+	assert.Equal(t, annotationMessage, domain.Error)
+
+	domain = (&IngressDomainBuilder{
+		Host:    "foo.com",
+		Event:   Normal,
+		Ingress: ingress,
+	}).Build()
+	assert.Empty(t, domain.Error)
+}
+
+func TestIngressProblems(t *testing.T) {
+	ingress := &config.Config{Meta: config.Meta{Name: "foo", Namespace: "default"}}
+	preIngress := &config.Config{Meta: config.Meta{Name: "bar", Namespace: "default"}}
+
+	routeCache := NewIngressRouteCache()
+	routeCache.Add(&IngressRouteBuilder{
+		ClusterId: "c1",
+		Host:      "foo.com",
+		Path:      "/",
+		Event:     Normal,
+		Ingress:   ingress,
+	})
+	routeCache.Add(&IngressRouteBuilder{
+		ClusterId:  "c1",
+		Host:       "foo.com",
+		Path:       "/",
+		Event:      DuplicatedRoute,
+		Ingress:    ingress,
+		PreIngress: preIngress,
+	})
+	problems := routeCache.Problems()
+	assert.Len(t, problems, 1)
+	assert.Equal(t, "c1", problems[0].ClusterId)
+	assert.Equal(t, "foo.com", problems[0].Host)
+	assert.Equal(t, DuplicatedRoute, problems[0].Event)
+	assert.Same(t, ingress, problems[0].Ingress)
+	assert.Same(t, preIngress, problems[0].PreIngress)
+	assert.Contains(t, problems[0].Message, "is already defined in ingress default/bar")
+
+	domainCache := NewIngressDomainCache()
+	domainCache.Invalid = append(domainCache.Invalid, &IngressDomainBuilder{
+		ClusterId:  "c1",
+		Host:       "foo.com",
+		Protocol:   HTTPS,
+		Event:      DuplicatedTls,
+		Ingress:    ingress,
+		PreIngress: preIngress,
+	})
+	problems = domainCache.Problems()
+	assert.Len(t, problems, 1)
+	assert.Equal(t, DuplicatedTls, problems[0].Event)
+	assert.Contains(t, problems[0].Message, "is conflicted with ingress default/bar")
+	assert.Len(t, domainCache.Extract().Invalid, 1)
+}
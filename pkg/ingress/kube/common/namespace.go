@@ -0,0 +1,111 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"strings"
+
+	"istio.io/istio/pilot/pkg/util/sets"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// NamespaceFilter selects the namespaces whose ingresses are watched, by the names of WatchNamespace
+// and the label selector of WatchNamespaceSelector. The namespaces are watched only if they are
+// selected by both of them.
+type NamespaceFilter struct {
+	// names is nil if the namespaces of all names are watched.
+	names sets.Set
+	// selector is nil if the namespaces of all labels are watched.
+	selector        labels.Selector
+	namespaceLister listerv1.NamespaceLister
+}
+
+// NewNamespaceFilter creates the filter of the options, the namespaces are looked up by the lister
+// if the selector is set. None of them are watched if the selector is invalid.
+func NewNamespaceFilter(options Options, namespaceLister listerv1.NamespaceLister) *NamespaceFilter {
+	filter := &NamespaceFilter{
+		names:           WatchNamespaces(options.WatchNamespace),
+		namespaceLister: namespaceLister,
+	}
+	if options.WatchNamespaceSelector != "" {
+		selector, err := labels.Parse(options.WatchNamespaceSelector)
+		if err != nil {
+			IngressLog.Errorf("Invalid watch namespace selector %q of cluster %s, no namespace is watched: %v",
+				options.WatchNamespaceSelector, options.ClusterId, err)
+			selector = labels.Nothing()
+		}
+		filter.selector = selector
+	}
+	return filter
+}
+
+// WatchNamespaces returns the names of the comma separated namespaces, or nil if it's empty.
+func WatchNamespaces(watchNamespace string) sets.Set {
+	var names sets.Set
+	for _, name := range strings.Split(watchNamespace, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if names == nil {
+			names = sets.NewSet()
+		}
+		names.Insert(name)
+	}
+	return names
+}
+
+// HasSelector returns true if the namespaces are selected by the labels, whose changes are handled
+// by the Handler.
+func (f *NamespaceFilter) HasSelector() bool {
+	return f.selector != nil
+}
+
+// Watched returns true if the ingresses in the namespace are watched, all of them are watched by
+// the nil filter.
+func (f *NamespaceFilter) Watched(namespace string) bool {
+	if f == nil {
+		return true
+	}
+	if f.names != nil && !f.names.Contains(namespace) {
+		return false
+	}
+	if f.selector == nil {
+		return true
+	}
+	if f.namespaceLister == nil {
+		return false
+	}
+	ns, err := f.namespaceLister.Get(namespace)
+	if err != nil {
+		return false
+	}
+	return f.selector.Matches(labels.Set(ns.Labels))
+}
+
+// Handler calls the handler with the name of the namespace which is selected or unselected by the
+// labels, including the selected namespaces added or deleted.
+func (f *NamespaceFilter) Handler(handler func(namespace string)) cache.ResourceEventHandler {
+	return namespaceHandler(func(ns *v1.Namespace) string {
+		if f.selector == nil || !f.selector.Matches(labels.Set(ns.Labels)) {
+			return ""
+		}
+		return "selected"
+	}, handler)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pilot/pkg/util/sets"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestNamespaceLister(t *testing.T, namespaces ...*v1.Namespace) listerv1.NamespaceLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range namespaces {
+		assert.NoError(t, indexer.Add(ns))
+	}
+	return listerv1.NewNamespaceLister(indexer)
+}
+
+func TestWatchNamespaces(t *testing.T) {
+	assert.Nil(t, WatchNamespaces(""))
+	assert.Nil(t, WatchNamespaces(" , "))
+	assert.Equal(t, sets.NewSet("foo"), WatchNamespaces("foo"))
+	assert.Equal(t, sets.NewSet("foo", "bar"), WatchNamespaces("foo, bar,"))
+}
+
+func TestNamespaceFilter(t *testing.T) {
+	lister := newTestNamespaceLister(t,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: map[string]string{"higress.io/tenant": "enabled"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bar", Labels: map[string]string{"higress.io/tenant": "enabled"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	)
+
+	testCases := []struct {
+		name    string
+		options Options
+		expect  []string
+	}{
+		{
+			name:   "all",
+			expect: []string{"bar", "default", "foo", "missing"},
+		},
+		{
+			name:    "names",
+			options: Options{WatchNamespace: "foo,default"},
+			expect:  []string{"default", "foo"},
+		},
+		{
+			name:    "selector",
+			options: Options{WatchNamespaceSelector: "higress.io/tenant=enabled"},
+			expect:  []string{"bar", "foo"},
+		},
+		{
+			name:    "names and selector",
+			options: Options{WatchNamespace: "foo,default", WatchNamespaceSelector: "higress.io/tenant=enabled"},
+			expect:  []string{"foo"},
+		},
+		{
+			name:    "invalid selector",
+			options: Options{WatchNamespaceSelector: "higress.io/tenant in enabled"},
+			expect:  []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			filter := NewNamespaceFilter(testCase.options, lister)
+			assert.Equal(t, testCase.options.WatchNamespaceSelector != "", filter.HasSelector())
+			watched := []string{}
+			for _, namespace := range []string{"bar", "default", "foo", "missing"} {
+				if filter.Watched(namespace) {
+					watched = append(watched, namespace)
+				}
+			}
+			assert.Equal(t, testCase.expect, watched)
+		})
+	}
+
+	var filter *NamespaceFilter
+	assert.True(t, filter.Watched("foo"))
+}
+
+func TestNamespaceFilterHandler(t *testing.T) {
+	var changed []string
+	filter := NewNamespaceFilter(Options{WatchNamespaceSelector: "higress.io/tenant=enabled"}, nil)
+	handler := filter.Handler(func(namespace string) {
+		changed = append(changed, namespace)
+	})
+	newNamespace := func(name string, labels map[string]string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	enabled := map[string]string{"higress.io/tenant": "enabled"}
+
+	handler.OnAdd(newNamespace("default", nil))
+	handler.OnAdd(newNamespace("foo", enabled))
+	handler.OnUpdate(newNamespace("foo", enabled), newNamespace("foo", map[string]string{"higress.io/tenant": "enabled", "team": "foo"}))
+	handler.OnUpdate(newNamespace("bar", nil), newNamespace("bar", enabled))
+	handler.OnUpdate(newNamespace("foo", enabled), newNamespace("foo", map[string]string{"higress.io/tenant": "disabled"}))
+	handler.OnDelete(newNamespace("bar", enabled))
+	assert.Equal(t, []string{"foo", "bar", "foo", "bar"}, changed)
+}
@@ -17,17 +17,25 @@ package common
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"fmt"
 	"net"
 	"sort"
 	"strings"
 
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/util/sets"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/kube"
 	v1 "k8s.io/api/core/v1"
+	ingress "k8s.io/api/networking/v1"
+	ingressv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/util/version"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
 	. "github.com/alibaba/higress/pkg/ingress/log"
 )
 
@@ -129,6 +137,96 @@ func GetHost(annotations map[string]string) string {
 	return ""
 }
 
+// IngressHosts returns the hosts whose converted resources the ingress contributes to, the spec
+// default backend contributes to the default host. The hosts of the rules with paths are already
+// defaulted by the ingress controllers. It returns nil for the other kinds of configs,
+// whose hosts are unknown before the conversion.
+func IngressHosts(cfg *config.Config) sets.Set {
+	hosts := sets.NewSet()
+	addHost := func(host string) {
+		hosts.Insert(host)
+	}
+
+	switch spec := cfg.Spec.(type) {
+	case ingress.IngressSpec:
+		for _, rule := range spec.Rules {
+			addHost(rule.Host)
+		}
+		for _, tls := range spec.TLS {
+			for _, host := range tls.Hosts {
+				addHost(host)
+			}
+		}
+		if spec.DefaultBackend != nil {
+			addHost(DefaultHost)
+		}
+	case ingressv1beta1.IngressSpec:
+		for _, rule := range spec.Rules {
+			addHost(rule.Host)
+		}
+		for _, tls := range spec.TLS {
+			for _, host := range tls.Hosts {
+				addHost(host)
+			}
+		}
+		if spec.Backend != nil {
+			addHost(DefaultHost)
+		}
+	default:
+		return nil
+	}
+	return hosts
+}
+
+// IngressReferences returns the names of the backend services and the tls secrets referenced by the
+// spec of the ingress, which are in the namespace of the ingress.
+func IngressReferences(cfg *config.Config) (services sets.Set, secrets sets.Set) {
+	services, secrets = sets.NewSet(), sets.NewSet()
+	switch spec := cfg.Spec.(type) {
+	case ingress.IngressSpec:
+		addBackend := func(backend *ingress.IngressBackend) {
+			if backend != nil && backend.Service != nil && backend.Service.Name != "" {
+				services.Insert(backend.Service.Name)
+			}
+		}
+		addBackend(spec.DefaultBackend)
+		for _, rule := range spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				addBackend(&path.Backend)
+			}
+		}
+		for _, tls := range spec.TLS {
+			if tls.SecretName != "" {
+				secrets.Insert(tls.SecretName)
+			}
+		}
+	case ingressv1beta1.IngressSpec:
+		addBackend := func(backend *ingressv1beta1.IngressBackend) {
+			if backend != nil && backend.ServiceName != "" {
+				services.Insert(backend.ServiceName)
+			}
+		}
+		addBackend(spec.Backend)
+		for _, rule := range spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				addBackend(&path.Backend)
+			}
+		}
+		for _, tls := range spec.TLS {
+			if tls.SecretName != "" {
+				secrets.Insert(tls.SecretName)
+			}
+		}
+	}
+	return services, secrets
+}
+
 // CleanHost follow the format of mse-ops for host.
 func CleanHost(host string) string {
 	if host == "*" {
@@ -274,6 +372,21 @@ func GenerateUniqueRouteNameWithSuffix(route *WrapperHTTPRoute, suffix string) s
 	return CreateConvertedName(route.Meta(), suffix)
 }
 
+// AddAltSvcHeader adds the Alt-Svc response header to route, so that clients can upgrade to HTTP/3
+// over QUIC on the port.
+func AddAltSvcHeader(route *networking.HTTPRoute, port uint32) {
+	if route.Headers == nil {
+		route.Headers = &networking.Headers{}
+	}
+	if route.Headers.Response == nil {
+		route.Headers.Response = &networking.Headers_HeaderOperations{}
+	}
+	if route.Headers.Response.Set == nil {
+		route.Headers.Response.Set = map[string]string{}
+	}
+	route.Headers.Response.Set["alt-svc"] = fmt.Sprintf(`h3=":%d"; ma=86400`, port)
+}
+
 func SplitServiceFQDN(fqdn string) (string, string, bool) {
 	parts := strings.Split(fqdn, ".")
 	if len(parts) > 1 {
@@ -343,3 +456,134 @@ func GetLbStatusList(svcList []*v1.Service) []v1.LoadBalancerIngress {
 	sort.SliceStable(lbi, SortLbIngressList(lbi))
 	return lbi
 }
+
+// DestinationToRouteDestination routes to the host of the destination annotation instead of the
+// backend service.
+func DestinationToRouteDestination(destination *annotations.DestinationConfig, builder *IngressRouteBuilder) []*networking.HTTPRouteDestination {
+	builder.ServiceList = []model.BackendService{
+		{
+			Name:   destination.Host,
+			Port:   destination.Port,
+			Weight: 100,
+		},
+	}
+
+	return []*networking.HTTPRouteDestination{
+		{
+			Destination: &networking.Destination{
+				Host: destination.Host,
+				Port: &networking.PortSelector{
+					Number: destination.Port,
+				},
+			},
+			Weight: 100,
+		},
+	}
+}
+
+// ExternalName returns the external name of the service, it's empty if the service doesn't exist
+// or it's not an ExternalName service.
+func ExternalName(serviceLister listerv1.ServiceLister, namespace, name string) string {
+	if serviceLister == nil {
+		return ""
+	}
+	service, err := serviceLister.Services(namespace).Get(name)
+	if err != nil || service.Spec.Type != v1.ServiceTypeExternalName {
+		return ""
+	}
+	return strings.TrimSuffix(service.Spec.ExternalName, ".")
+}
+
+// ServiceHost returns the host to route to for the backend service, which is the external name
+// for the ExternalName service.
+func ServiceHost(serviceLister listerv1.ServiceLister, namespace, name string) string {
+	if externalName := ExternalName(serviceLister, namespace, name); externalName != "" {
+		return externalName
+	}
+	return util.CreateServiceFQDN(namespace, name)
+}
+
+// ExternalNameServiceEntry returns the dns resolved service entry of the external name. The tls of
+// the port 443 is originated by the destination rule, so all ports are plain http.
+func ExternalNameServiceEntry(host string, ports []uint32) *networking.ServiceEntry {
+	serviceEntry := &networking.ServiceEntry{
+		Hosts:      []string{host},
+		Location:   networking.ServiceEntry_MESH_EXTERNAL,
+		Resolution: networking.ServiceEntry_DNS,
+	}
+	for _, port := range ports {
+		serviceEntry.Ports = append(serviceEntry.Ports, &networking.Port{
+			Number:   port,
+			Protocol: "HTTP",
+			Name:     fmt.Sprintf("http-%d", port),
+		})
+	}
+	return serviceEntry
+}
+
+// ExternalNameTLSSettings returns the default upstream tls settings of the external name on the
+// port 443.
+func ExternalNameTLSSettings(host string) *networking.ClientTLSSettings {
+	return &networking.ClientTLSSettings{
+		Mode: networking.ClientTLSSettings_SIMPLE,
+		Sni:  host,
+	}
+}
+
+// NamespaceGatewaySelector returns the gateway selector whose value is the NamespaceGatewayLabel of
+// the namespace, it's nil if the namespace isn't labelled.
+func NamespaceGatewaySelector(namespaceLister listerv1.NamespaceLister, namespace string, options Options) map[string]string {
+	if namespaceLister == nil || options.NamespaceGatewayLabel == "" || options.GatewaySelectorKey == "" {
+		return nil
+	}
+	ns, err := namespaceLister.Get(namespace)
+	if err != nil {
+		return nil
+	}
+	value := ns.Labels[options.NamespaceGatewayLabel]
+	if value == "" {
+		return nil
+	}
+	return map[string]string{options.GatewaySelectorKey: value}
+}
+
+// NamespaceLabelHandler calls the handler with the name of the namespace whose value of the label
+// is changed, including the labelled namespaces added or deleted.
+func NamespaceLabelHandler(label string, handler func(namespace string)) cache.ResourceEventHandler {
+	return namespaceHandler(func(ns *v1.Namespace) string {
+		return ns.Labels[label]
+	}, handler)
+}
+
+// namespaceHandler calls the handler with the name of the namespace whose value returned by valueOf
+// is changed, including the namespaces of non-empty values added or deleted.
+func namespaceHandler(valueOf func(ns *v1.Namespace) string, handler func(namespace string)) cache.ResourceEventHandler {
+	extract := func(obj interface{}) (string, string, bool) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		ns, ok := obj.(*v1.Namespace)
+		if !ok {
+			return "", "", false
+		}
+		return ns.Name, valueOf(ns), true
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if name, value, ok := extract(obj); ok && value != "" {
+				handler(name)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			_, oldValue, _ := extract(oldObj)
+			if name, value, ok := extract(newObj); ok && value != oldValue {
+				handler(name)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if name, value, ok := extract(obj); ok && value != "" {
+				handler(name)
+			}
+		},
+	}
+}
@@ -20,7 +20,11 @@ import (
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pkg/config"
 	v1 "k8s.io/api/core/v1"
+	ingress "k8s.io/api/networking/v1"
+	ingressv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
 	"github.com/stretchr/testify/assert"
@@ -415,3 +419,194 @@ func TestSortRoutes(t *testing.T) {
 		t.Fatal("should be test-3")
 	}
 }
+
+func TestDestinationToRouteDestination(t *testing.T) {
+	builder := &IngressRouteBuilder{}
+	route := DestinationToRouteDestination(&annotations.DestinationConfig{
+		Host: "foo.DEFAULT-GROUP.public.nacos",
+		Port: 8080,
+	}, builder)
+
+	assert.Equal(t, []*networking.HTTPRouteDestination{
+		{
+			Destination: &networking.Destination{
+				Host: "foo.DEFAULT-GROUP.public.nacos",
+				Port: &networking.PortSelector{Number: 8080},
+			},
+			Weight: 100,
+		},
+	}, route)
+	assert.Equal(t, "foo.DEFAULT-GROUP.public.nacos", builder.ServiceList[0].Name)
+}
+
+func TestIngressHosts(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  config.Config
+		expect []string
+	}{
+		{
+			name: "v1 rules and tls",
+			input: config.Config{
+				Spec: ingress.IngressSpec{
+					TLS:   []ingress.IngressTLS{{Hosts: []string{"foo.com", "bar.com"}}},
+					Rules: []ingress.IngressRule{{Host: "foo.com"}, {Host: "*"}},
+				},
+			},
+			expect: []string{"*", "bar.com", "foo.com"},
+		},
+		{
+			name: "v1 default backend",
+			input: config.Config{
+				Spec: ingress.IngressSpec{
+					DefaultBackend: &ingress.IngressBackend{},
+					Rules:          []ingress.IngressRule{{Host: "foo.com"}},
+				},
+			},
+			expect: []string{"*", "foo.com"},
+		},
+		{
+			name: "v1beta1 default backend",
+			input: config.Config{
+				Spec: ingressv1beta1.IngressSpec{
+					Backend: &ingressv1beta1.IngressBackend{},
+				},
+			},
+			expect: []string{"*"},
+		},
+		{
+			name: "unknown kind",
+			input: config.Config{
+				Spec: &networking.VirtualService{},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			hosts := IngressHosts(&testCase.input)
+			if testCase.expect == nil {
+				assert.Nil(t, hosts)
+				return
+			}
+			assert.Equal(t, testCase.expect, hosts.SortedList())
+		})
+	}
+}
+
+func TestIngressReferences(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    config.Config
+		services []string
+		secrets  []string
+	}{
+		{
+			name: "v1",
+			input: config.Config{
+				Spec: ingress.IngressSpec{
+					DefaultBackend: &ingress.IngressBackend{
+						Service: &ingress.IngressServiceBackend{Name: "default"},
+					},
+					TLS: []ingress.IngressTLS{{Hosts: []string{"foo.com"}, SecretName: "foo-tls"}, {Hosts: []string{"bar.com"}}},
+					Rules: []ingress.IngressRule{
+						{
+							Host: "foo.com",
+							IngressRuleValue: ingress.IngressRuleValue{
+								HTTP: &ingress.HTTPIngressRuleValue{
+									Paths: []ingress.HTTPIngressPath{
+										{Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{Name: "foo"}}},
+										{Backend: ingress.IngressBackend{Resource: &v1.TypedLocalObjectReference{Name: "bucket"}}},
+									},
+								},
+							},
+						},
+						{Host: "bar.com"},
+					},
+				},
+			},
+			services: []string{"default", "foo"},
+			secrets:  []string{"foo-tls"},
+		},
+		{
+			name: "v1beta1",
+			input: config.Config{
+				Spec: ingressv1beta1.IngressSpec{
+					TLS: []ingressv1beta1.IngressTLS{{SecretName: "bar-tls"}},
+					Rules: []ingressv1beta1.IngressRule{
+						{
+							IngressRuleValue: ingressv1beta1.IngressRuleValue{
+								HTTP: &ingressv1beta1.HTTPIngressRuleValue{
+									Paths: []ingressv1beta1.HTTPIngressPath{
+										{Backend: ingressv1beta1.IngressBackend{ServiceName: "bar"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			services: []string{"bar"},
+			secrets:  []string{"bar-tls"},
+		},
+		{
+			name: "unknown kind",
+			input: config.Config{
+				Spec: &networking.VirtualService{},
+			},
+			services: []string{},
+			secrets:  []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			services, secrets := IngressReferences(&testCase.input)
+			assert.Equal(t, testCase.services, services.SortedList())
+			assert.Equal(t, testCase.secrets, secrets.SortedList())
+		})
+	}
+}
+
+func TestNamespaceGatewaySelector(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range []*v1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "internal", Labels: map[string]string{"higress.io/gateway": "internal-gateway"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "empty", Labels: map[string]string{"higress.io/gateway": ""}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	} {
+		assert.NoError(t, indexer.Add(ns))
+	}
+	lister := listerv1.NewNamespaceLister(indexer)
+	options := Options{GatewaySelectorKey: "higress", NamespaceGatewayLabel: "higress.io/gateway"}
+
+	assert.Equal(t, map[string]string{"higress": "internal-gateway"}, NamespaceGatewaySelector(lister, "internal", options))
+	assert.Nil(t, NamespaceGatewaySelector(lister, "empty", options))
+	assert.Nil(t, NamespaceGatewaySelector(lister, "default", options))
+	assert.Nil(t, NamespaceGatewaySelector(lister, "missing", options))
+	assert.Nil(t, NamespaceGatewaySelector(nil, "internal", options))
+	assert.Nil(t, NamespaceGatewaySelector(lister, "internal", Options{NamespaceGatewayLabel: "higress.io/gateway"}))
+}
+
+func TestNamespaceLabelHandler(t *testing.T) {
+	var changed []string
+	handler := NamespaceLabelHandler("higress.io/gateway", func(namespace string) {
+		changed = append(changed, namespace)
+	})
+	newNamespace := func(name, value string) *v1.Namespace {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": name}}}
+		if value != "" {
+			ns.Labels["higress.io/gateway"] = value
+		}
+		return ns
+	}
+
+	handler.OnAdd(newNamespace("default", ""))
+	handler.OnAdd(newNamespace("foo", "internal-gateway"))
+	handler.OnUpdate(newNamespace("foo", "internal-gateway"), newNamespace("foo", "internal-gateway"))
+	handler.OnUpdate(newNamespace("bar", ""), newNamespace("bar", "internal-gateway"))
+	handler.OnUpdate(newNamespace("foo", "internal-gateway"), newNamespace("foo", ""))
+	handler.OnDelete(newNamespace("default", ""))
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "bar", Obj: newNamespace("bar", "internal-gateway")})
+	assert.Equal(t, []string{"foo", "bar", "foo", "bar"}, changed)
+}
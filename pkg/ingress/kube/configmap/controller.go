@@ -0,0 +1,134 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmap
+
+import (
+	"strings"
+	"time"
+
+	kubeclient "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// Controller watches the specified ConfigMaps.
+type Controller interface {
+	AddEventHandler(func(types.NamespacedName))
+
+	Run(stop <-chan struct{})
+
+	HasSynced() bool
+
+	// Get returns the ConfigMap, the error is not found if it doesn't exist.
+	Get(name types.NamespacedName) (*v1.ConfigMap, error)
+}
+
+type controller struct {
+	queue    workqueue.RateLimitingInterface
+	informer cache.SharedIndexInformer
+	lister   listersv1.ConfigMapLister
+	handler  func(types.NamespacedName)
+}
+
+func NewController(client kubeclient.Client, names ...types.NamespacedName) Controller {
+	watched := map[types.NamespacedName]struct{}{}
+	for _, name := range names {
+		watched[name] = struct{}{}
+	}
+
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultItemBasedRateLimiter())
+	informer := client.KubeInformer().Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			object, ok := obj.(metav1.Object)
+			if !ok {
+				return false
+			}
+			_, exist := watched[types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}]
+			return exist
+		},
+		Handler: controllers.LatestVersionHandlerFuncs(controllers.EnqueueForSelf(q)),
+	})
+
+	return &controller{
+		queue:    q,
+		informer: informer,
+		lister:   listersv1.NewConfigMapLister(informer.GetIndexer()),
+	}
+}
+
+// ParseName parses namespace/name, the namespace defaults to the system namespace.
+func ParseName(raw, systemNamespace string) types.NamespacedName {
+	if idx := strings.Index(raw, "/"); idx >= 0 {
+		return types.NamespacedName{Namespace: raw[:idx], Name: raw[idx+1:]}
+	}
+	return types.NamespacedName{Namespace: systemNamespace, Name: raw}
+}
+
+func (c *controller) AddEventHandler(f func(types.NamespacedName)) {
+	c.handler = f
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	if !cache.WaitForCacheSync(stop, c.HasSynced) {
+		IngressLog.Errorf("Failed to sync configmap controller cache")
+		return
+	}
+	go wait.Until(c.worker, time.Second, stop)
+	<-stop
+}
+
+func (c *controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	namespacedName := key.(types.NamespacedName)
+	IngressLog.Debugf("configmap %s push to queue", namespacedName)
+	if c.handler != nil {
+		c.handler(namespacedName)
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) HasSynced() bool {
+	return c.informer.HasSynced()
+}
+
+func (c *controller) Get(name types.NamespacedName) (*v1.ConfigMap, error) {
+	return c.lister.ConfigMaps(name.Namespace).Get(name.Name)
+}
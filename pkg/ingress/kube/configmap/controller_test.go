@@ -0,0 +1,29 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseName(t *testing.T) {
+	assert.Equal(t, types.NamespacedName{Namespace: "higress-system", Name: "tcp-services"},
+		ParseName("tcp-services", "higress-system"))
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "tcp-services"},
+		ParseName("default/tcp-services", "higress-system"))
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"sync"
+
+	istioclient "istio.io/client-go/pkg/clientset/versioned"
+	istioinformer "istio.io/client-go/pkg/informers/externalversions"
+	"istio.io/istio/pkg/kube"
+	kubeExtClient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	gatewayapiclient "sigs.k8s.io/gateway-api/pkg/client/clientset/gateway/versioned"
+	gatewayapiinformer "sigs.k8s.io/gateway-api/pkg/client/informers/gateway/externalversions"
+	mcsapisClient "sigs.k8s.io/mcs-api/pkg/client/clientset/versioned"
+	mcsapisInformer "sigs.k8s.io/mcs-api/pkg/client/informers/externalversions"
+)
+
+// serverVersion supports the networking.k8s.io/v1 ingresses.
+var serverVersion = &version.Info{Major: "1", Minor: "22", GitVersion: "v1.22.0"}
+
+// Client is the in-memory kubernetes client, which holds the objects loaded from the files instead
+// of talking to an api server. Only the core kubernetes api and its informers are served, the other
+// clients and informers are nil, so the components using them must not be started in the file mode.
+type Client struct {
+	kubernetes.Interface
+	kubeInformer informers.SharedInformerFactory
+
+	mutex sync.Mutex
+	cond  *sync.Cond
+	// listing is the number of the informers which have listed the objects but not started
+	// watching yet, the objects written in between would be missed by them.
+	listing int
+}
+
+var _ kube.Client = &Client{}
+
+// NewClient returns the in-memory kubernetes client reporting a server version supporting the
+// networking.k8s.io/v1 ingresses.
+func NewClient() *Client {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.Discovery().(*fake.FakeDiscovery).FakedServerVersion = serverVersion
+	c := &Client{
+		Interface:    clientset,
+		kubeInformer: informers.NewSharedInformerFactory(clientset, 0),
+	}
+	c.cond = sync.NewCond(&c.mutex)
+
+	clientset.PrependReactor("list", "*", func(clienttesting.Action) (bool, runtime.Object, error) {
+		c.mutex.Lock()
+		c.listing++
+		c.mutex.Unlock()
+		return false, nil, nil
+	})
+	clientset.PrependWatchReactor("*", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		w, err := clientset.Tracker().Watch(action.GetResource(), action.GetNamespace())
+		c.mutex.Lock()
+		c.listing--
+		c.cond.Broadcast()
+		c.mutex.Unlock()
+		return true, w, err
+	})
+	return c
+}
+
+// write runs the writes when no informer is between listing and watching, so that no informer
+// misses the objects written.
+func (c *Client) write(f func() error) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for c.listing > 0 {
+		c.cond.Wait()
+	}
+	return f()
+}
+
+func (c *Client) RESTConfig() *rest.Config {
+	return nil
+}
+
+func (c *Client) Ext() kubeExtClient.Interface {
+	return nil
+}
+
+func (c *Client) Kube() kubernetes.Interface {
+	return c.Interface
+}
+
+func (c *Client) Dynamic() dynamic.Interface {
+	return nil
+}
+
+func (c *Client) Metadata() metadata.Interface {
+	return nil
+}
+
+func (c *Client) Istio() istioclient.Interface {
+	return nil
+}
+
+func (c *Client) GatewayAPI() gatewayapiclient.Interface {
+	return nil
+}
+
+func (c *Client) MCSApis() mcsapisClient.Interface {
+	return nil
+}
+
+func (c *Client) KubeInformer() informers.SharedInformerFactory {
+	return c.kubeInformer
+}
+
+func (c *Client) DynamicInformer() dynamicinformer.DynamicSharedInformerFactory {
+	return nil
+}
+
+func (c *Client) MetadataInformer() metadatainformer.SharedInformerFactory {
+	return nil
+}
+
+func (c *Client) IstioInformer() istioinformer.SharedInformerFactory {
+	return nil
+}
+
+func (c *Client) GatewayAPIInformer() gatewayapiinformer.SharedInformerFactory {
+	return nil
+}
+
+func (c *Client) MCSApisInformer() mcsapisInformer.SharedInformerFactory {
+	return nil
+}
+
+// RunAndWait starts the informers, and waits for them to sync and watch, so that the objects
+// written after it returns are not missed.
+func (c *Client) RunAndWait(stop <-chan struct{}) {
+	c.kubeInformer.Start(stop)
+	c.kubeInformer.WaitForCacheSync(stop)
+	c.mutex.Lock()
+	for c.listing > 0 {
+		c.cond.Wait()
+	}
+	c.mutex.Unlock()
+}
+
+func (c *Client) GetKubernetesVersion() (*version.Info, error) {
+	return serverVersion, nil
+}
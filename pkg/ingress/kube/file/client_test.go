@@ -0,0 +1,65 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+)
+
+func TestClient(t *testing.T) {
+	client := NewClient()
+	assert.True(t, common.V1Available(client))
+	// Only the core kubernetes api is served without an api server.
+	assert.Nil(t, client.DynamicInformer())
+	assert.Nil(t, client.GatewayAPI())
+
+	// The objects written while the informer lists and watches are not missed.
+	lister := client.KubeInformer().Core().V1().Services().Lister()
+	stop := make(chan struct{})
+	defer close(stop)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, client.write(func() error {
+				_, err := client.Kube().CoreV1().Services("default").Create(context.Background(),
+					&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("foo-%d", i), Namespace: "default"}},
+					metav1.CreateOptions{})
+				return err
+			}))
+		}(i)
+	}
+	client.RunAndWait(stop)
+	wg.Wait()
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		services, err := lister.List(labels.Everything())
+		return len(services) == 50, err
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+const (
+	watchDebounceDelay = 100 * time.Millisecond
+	// resyncInterval reloads the files periodically in case of any missed file event.
+	resyncInterval = 30 * time.Second
+)
+
+// controller loads the ingresses, services and secrets from the files in the directory into the
+// in-memory client, and the wrapped ingress controller converts them as if they were watched from
+// a kubernetes cluster. The files are reloaded on change.
+type controller struct {
+	common.IngressController
+
+	client *Client
+	dir    string
+
+	mutex   sync.Mutex
+	objects map[objectKey]runtime.Object
+	synced  bool
+	// version is the resource version of the last applied object, the in-memory client doesn't
+	// maintain the resource versions as an api server.
+	version uint64
+}
+
+// NewController wraps the ingress controller watching the in-memory client created by NewClient.
+func NewController(ingressController common.IngressController, client *Client, dir string) common.IngressController {
+	return &controller{
+		IngressController: ingressController,
+		client:            client,
+		dir:               dir,
+		objects:           map[objectKey]runtime.Object{},
+	}
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	if err := c.sync(); err != nil {
+		IngressLog.Errorf("Load files from %s error: %v", c.dir, err)
+	}
+	c.mutex.Lock()
+	c.synced = true
+	c.mutex.Unlock()
+
+	go c.watch(stop)
+	c.IngressController.Run(stop)
+}
+
+func (c *controller) HasSynced() bool {
+	c.mutex.Lock()
+	synced := c.synced
+	c.mutex.Unlock()
+	return synced && c.IngressController.HasSynced()
+}
+
+func (c *controller) watch(stop <-chan struct{}) {
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		IngressLog.Errorf("Watch files of %s error: %v, fall back to reload every %v", c.dir, err, resyncInterval)
+	} else {
+		defer watcher.Close()
+		c.addWatches(watcher)
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-events:
+			if debounce == nil {
+				debounce = time.After(watchDebounceDelay)
+			}
+		case err := <-watchErrors:
+			IngressLog.Warnf("Watch files of %s error: %v", c.dir, err)
+		case <-debounce:
+			debounce = nil
+			c.reload(watcher)
+		case <-ticker.C:
+			c.reload(watcher)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *controller) reload(watcher *fsnotify.Watcher) {
+	if err := c.sync(); err != nil {
+		IngressLog.Errorf("Reload files from %s error: %v", c.dir, err)
+	}
+	if watcher != nil {
+		c.addWatches(watcher)
+	}
+}
+
+// addWatches watches the directory and the sub directories created since the last time.
+func (c *controller) addWatches(watcher *fsnotify.Watcher) {
+	_ = filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			IngressLog.Warnf("Watch directory %s error: %v", path, err)
+		}
+		return nil
+	})
+}
+
+// sync applies the difference between the files and the objects loaded last time to the
+// in-memory client. The objects failed to apply are retried in the next sync.
+func (c *controller) sync() error {
+	objects, err := LoadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var errs error
+	for key, obj := range objects {
+		prev, exist := c.objects[key]
+		if exist && reflect.DeepEqual(prev, obj) {
+			continue
+		}
+		if err := c.apply(obj); err != nil {
+			errs = multierror.Append(errs, err)
+			if exist {
+				objects[key] = prev
+			} else {
+				delete(objects, key)
+			}
+			continue
+		}
+		IngressLog.Debugf("Apply %s from files", key)
+	}
+	for key, prev := range c.objects {
+		if _, exist := objects[key]; exist {
+			continue
+		}
+		if err := c.remove(key); err != nil {
+			errs = multierror.Append(errs, err)
+			objects[key] = prev
+			continue
+		}
+		IngressLog.Debugf("Remove %s no longer in files", key)
+	}
+	c.objects = objects
+	return errs
+}
+
+func (c *controller) apply(obj runtime.Object) error {
+	c.version++
+	obj = obj.DeepCopyObject()
+	obj.(metav1.Object).SetResourceVersion(strconv.FormatUint(c.version, 10))
+	return c.client.write(func() error {
+		return c.applyObject(obj)
+	})
+}
+
+func (c *controller) applyObject(obj runtime.Object) error {
+	ctx := context.Background()
+	var err error
+	switch o := obj.(type) {
+	case *networkingv1.Ingress:
+		ingresses := c.client.Kube().NetworkingV1().Ingresses(o.Namespace)
+		if _, err = ingresses.Update(ctx, o, metav1.UpdateOptions{}); kerrors.IsNotFound(err) {
+			_, err = ingresses.Create(ctx, o, metav1.CreateOptions{})
+		}
+	case *corev1.Service:
+		services := c.client.Kube().CoreV1().Services(o.Namespace)
+		if _, err = services.Update(ctx, o, metav1.UpdateOptions{}); kerrors.IsNotFound(err) {
+			_, err = services.Create(ctx, o, metav1.CreateOptions{})
+		}
+	case *corev1.Secret:
+		secrets := c.client.Kube().CoreV1().Secrets(o.Namespace)
+		if _, err = secrets.Update(ctx, o, metav1.UpdateOptions{}); kerrors.IsNotFound(err) {
+			_, err = secrets.Create(ctx, o, metav1.CreateOptions{})
+		}
+	}
+	return err
+}
+
+func (c *controller) remove(key objectKey) error {
+	return c.client.write(func() error {
+		return c.removeObject(key)
+	})
+}
+
+func (c *controller) removeObject(key objectKey) error {
+	ctx := context.Background()
+	var err error
+	switch key.Kind {
+	case KindIngress:
+		err = c.client.Kube().NetworkingV1().Ingresses(key.Namespace).Delete(ctx, key.Name, metav1.DeleteOptions{})
+	case KindService:
+		err = c.client.Kube().CoreV1().Services(key.Namespace).Delete(ctx, key.Name, metav1.DeleteOptions{})
+	case KindSecret:
+		err = c.client.Kube().CoreV1().Secrets(key.Namespace).Delete(ctx, key.Name, metav1.DeleteOptions{})
+	}
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/kube"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/ingressv1"
+	secretkube "github.com/alibaba/higress/pkg/ingress/kube/secret/kube"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
+)
+
+func waitForIngresses(t *testing.T, client kube.Client, names ...string) {
+	t.Helper()
+	sort.Strings(names)
+	var got []string
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		got = nil
+		ingresses, err := client.KubeInformer().Networking().V1().Ingresses().Lister().List(labels.Everything())
+		if err != nil {
+			return false, err
+		}
+		for _, ing := range ingresses {
+			got = append(got, ing.Namespace+"/"+ing.Name)
+		}
+		sort.Strings(got)
+		return assert.ObjectsAreEqual(names, got), nil
+	})
+	if err != nil {
+		t.Fatalf("expect ingresses %v, got %v", names, got)
+	}
+}
+
+func TestController(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ingress.yaml"), testIngress)
+	writeFile(t, filepath.Join(dir, "backend.yaml"), testServiceAndSecret)
+
+	client := NewClient()
+	assert.True(t, common.V1Available(client))
+
+	options := common.Options{Enable: true, SystemNamespace: "wakanda"}
+	secretController := secretkube.NewController(client, options)
+	secretController.AddEventHandler(func(util.ClusterNamespacedName) {})
+	c := NewController(ingressv1.NewController(client, client, options, secretController), client, dir)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.Run(stop)
+	client.RunAndWait(stop)
+	kube.WaitForCacheSyncInterval(stop, 10*time.Millisecond, c.HasSynced)
+
+	waitForIngresses(t, client, "wakanda/foo")
+	// The resource versions are maintained for the caches of the conversion.
+	ing, err := client.KubeInformer().Networking().V1().Ingresses().Lister().Ingresses("wakanda").Get("foo")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ing.ResourceVersion)
+	_, err = c.ServiceLister().Services("default").Get("foo")
+	assert.NoError(t, err)
+
+	// Reloaded on change.
+	writeFile(t, filepath.Join(dir, "bar.yaml"), `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: bar
+spec:
+  defaultBackend:
+    service:
+      name: foo
+      port:
+        number: 80
+`)
+	waitForIngresses(t, client, "wakanda/foo", "default/bar")
+
+	// The objects are kept when any file is broken.
+	writeFile(t, filepath.Join(dir, "bar.yaml"), "kind: [")
+	assert.Error(t, c.(*controller).sync())
+	waitForIngresses(t, client, "wakanda/foo", "default/bar")
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "bar.yaml")))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "ingress.yaml")))
+	waitForIngresses(t, client)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+const (
+	KindIngress = "Ingress"
+	KindService = "Service"
+	KindSecret  = "Secret"
+
+	defaultNamespace = "default"
+)
+
+// objectKey identifies the object loaded from the files.
+type objectKey struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (k objectKey) String() string {
+	return k.Kind + "/" + k.Namespace + "/" + k.Name
+}
+
+// isConfigFile returns true for the yaml and json files, the hidden files such as the
+// swap files of the editors are ignored.
+func isConfigFile(path string) bool {
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return false
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// LoadDir loads the ingresses, services and secrets from the files in the directory and its
+// sub directories. Any unparsable file fails the whole load, so that the objects of a file
+// being written are not removed by mistake.
+func LoadDir(dir string) (map[objectKey]runtime.Object, error) {
+	objects := map[objectKey]runtime.Object{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isConfigFile(path) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		loaded, err := parseObjects(content)
+		if err != nil {
+			return fmt.Errorf("parse file %s error: %v", path, err)
+		}
+		for _, obj := range loaded {
+			key := keyOf(obj)
+			if _, exist := objects[key]; exist {
+				IngressLog.Warnf("Duplicate %s in file %s, override the previous one", key, path)
+			}
+			objects[key] = obj
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// parseObjects decodes the multi-document yaml or json content, the kinds other than
+// Ingress, Service and Secret are skipped.
+func parseObjects(content []byte) ([]runtime.Object, error) {
+	var out []runtime.Object
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		jsonDoc, err := utilyaml.ToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if string(jsonDoc) == "null" {
+			continue
+		}
+		obj, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(jsonDoc, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		switch obj.(type) {
+		case *networkingv1.Ingress, *corev1.Service, *corev1.Secret:
+		default:
+			IngressLog.Warnf("Skip unsupported kind %s, only networking.k8s.io/v1 Ingress, v1 Service and v1 Secret are loaded", gvk)
+			continue
+		}
+		// The stringData is merged into data by the api server, do the same here.
+		if secret, ok := obj.(*corev1.Secret); ok && len(secret.StringData) > 0 {
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			for k, v := range secret.StringData {
+				secret.Data[k] = []byte(v)
+			}
+			secret.StringData = nil
+		}
+		meta := obj.(metav1.Object)
+		if meta.GetName() == "" {
+			return nil, fmt.Errorf("%s without name", gvk.Kind)
+		}
+		if meta.GetNamespace() == "" {
+			meta.SetNamespace(defaultNamespace)
+		}
+		out = append(out, obj)
+	}
+}
+
+func keyOf(obj runtime.Object) objectKey {
+	meta := obj.(metav1.Object)
+	key := objectKey{Namespace: meta.GetNamespace(), Name: meta.GetName()}
+	switch obj.(type) {
+	case *networkingv1.Ingress:
+		key.Kind = KindIngress
+	case *corev1.Service:
+		key.Kind = KindService
+	case *corev1.Secret:
+		key.Kind = KindSecret
+	}
+	return key
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const testIngress = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: foo
+  namespace: wakanda
+spec:
+  rules:
+  - host: foo.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: foo
+            port:
+              number: 80
+`
+
+const testServiceAndSecret = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+spec:
+  ports:
+  - port: 80
+---
+# comment only
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: foo-tls
+  namespace: wakanda
+type: kubernetes.io/tls
+stringData:
+  tls.crt: cert
+data:
+  tls.key: a2V5
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ingress.yaml"), testIngress)
+	writeFile(t, filepath.Join(dir, "sub", "backend.yml"), testServiceAndSecret)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a config file")
+	writeFile(t, filepath.Join(dir, ".ingress.yaml.swp"), "not a config file")
+
+	objects, err := LoadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 3)
+
+	ing, ok := objects[objectKey{Kind: KindIngress, Namespace: "wakanda", Name: "foo"}].(*networkingv1.Ingress)
+	assert.True(t, ok)
+	assert.Equal(t, "foo.com", ing.Spec.Rules[0].Host)
+
+	// The namespace defaults to default.
+	_, ok = objects[objectKey{Kind: KindService, Namespace: "default", Name: "foo"}].(*corev1.Service)
+	assert.True(t, ok)
+
+	secret, ok := objects[objectKey{Kind: KindSecret, Namespace: "wakanda", Name: "foo-tls"}].(*corev1.Secret)
+	assert.True(t, ok)
+	assert.Equal(t, map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}, secret.Data)
+	assert.Empty(t, secret.StringData)
+}
+
+func TestLoadDirError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "invalid yaml",
+			content: "apiVersion: v1\nkind: Service\nmetadata: [",
+		},
+		{
+			name:    "unknown kind",
+			content: "apiVersion: v1\nkind: Unknown\nmetadata:\n  name: foo\n",
+		},
+		{
+			name:    "without name",
+			content: "apiVersion: v1\nkind: Service\nmetadata:\n  namespace: foo\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "ingress.yaml"), testIngress)
+			writeFile(t, filepath.Join(dir, "broken.yaml"), testCase.content)
+			_, err := LoadDir(dir)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := LoadDir(filepath.Join(t.TempDir(), "absent"))
+	assert.Error(t, err)
+}
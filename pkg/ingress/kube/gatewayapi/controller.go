@@ -0,0 +1,609 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/model/credentials"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/schema/gvk"
+	kubeclient "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewaylister "sigs.k8s.io/gateway-api/pkg/client/listers/gateway/apis/v1alpha2"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/secret"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// ControllerName is the controller name of the GatewayClass which is managed by higress.
+const ControllerName = "higress.io/gateway-controller"
+
+var (
+	_ common.IngressController = &controller{}
+)
+
+// IsRoute returns whether the config is converted from the route of gateway api.
+func IsRoute(kind config.GroupVersionKind) bool {
+	return kind == gvk.HTTPRoute || kind == gvk.TLSRoute || kind == gvk.TCPRoute
+}
+
+type controller struct {
+	queue                   workqueue.RateLimitingInterface
+	virtualServiceHandlers  []model.EventHandler
+	gatewayHandlers         []model.EventHandler
+	destinationRuleHandlers []model.EventHandler
+	envoyFilterHandlers     []model.EventHandler
+
+	options common.Options
+
+	gatewayClassInformer cache.SharedIndexInformer
+	gatewayClassLister   gatewaylister.GatewayClassLister
+	gatewayInformer      cache.SharedIndexInformer
+	gatewayLister        gatewaylister.GatewayLister
+	httpRouteInformer    cache.SharedIndexInformer
+	tlsRouteInformer     cache.SharedIndexInformer
+	tcpRouteInformer     cache.SharedIndexInformer
+	// The ReferencePolicies allow the references to the services and secrets across namespaces.
+	referencePolicyInformer cache.SharedIndexInformer
+	referencePolicyLister   gatewaylister.ReferencePolicyLister
+	namespaceInformer       cache.SharedIndexInformer
+	namespaceLister         listerv1.NamespaceLister
+	namespaceFilter         *common.NamespaceFilter
+	serviceInformer         cache.SharedInformer
+	serviceLister           listerv1.ServiceLister
+
+	secretController secret.Controller
+
+	statusSyncer *statusSyncer
+}
+
+// NewController creates a controller which converts the gateway api resources, it shares the
+// secret controller with the ingress controller of the same cluster.
+func NewController(client kubeclient.Client, options common.Options, secretController secret.Controller) common.IngressController {
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultItemBasedRateLimiter())
+
+	gatewayAPI := client.GatewayAPIInformer().Gateway().V1alpha2()
+	gatewayClasses := gatewayAPI.GatewayClasses()
+	gateways := gatewayAPI.Gateways()
+	httpRoutes := gatewayAPI.HTTPRoutes()
+	tlsRoutes := gatewayAPI.TLSRoutes()
+	tcpRoutes := gatewayAPI.TCPRoutes()
+	referencePolicies := gatewayAPI.ReferencePolicies()
+	// The namespaces are used to select the routes allowed by listeners.
+	namespaceInformer := client.KubeInformer().Core().V1().Namespaces()
+	serviceInformer := client.KubeInformer().Core().V1().Services()
+
+	c := &controller{
+		options:                 options,
+		queue:                   q,
+		gatewayClassInformer:    gatewayClasses.Informer(),
+		gatewayClassLister:      gatewayClasses.Lister(),
+		gatewayInformer:         gateways.Informer(),
+		gatewayLister:           gateways.Lister(),
+		httpRouteInformer:       httpRoutes.Informer(),
+		tlsRouteInformer:        tlsRoutes.Informer(),
+		tcpRouteInformer:        tcpRoutes.Informer(),
+		referencePolicyInformer: referencePolicies.Informer(),
+		referencePolicyLister:   referencePolicies.Lister(),
+		namespaceInformer:       namespaceInformer.Informer(),
+		namespaceLister:         namespaceInformer.Lister(),
+		serviceInformer:         serviceInformer.Informer(),
+		serviceLister:           serviceInformer.Lister(),
+		secretController:        secretController,
+	}
+
+	handler := controllers.LatestVersionHandlerFuncs(controllers.EnqueueForSelf(q))
+	c.gatewayClassInformer.AddEventHandler(handler)
+	c.gatewayInformer.AddEventHandler(handler)
+	c.httpRouteInformer.AddEventHandler(handler)
+	c.tlsRouteInformer.AddEventHandler(handler)
+	c.tcpRouteInformer.AddEventHandler(handler)
+	c.referencePolicyInformer.AddEventHandler(handler)
+
+	c.namespaceFilter = common.NewNamespaceFilter(options, c.namespaceLister)
+	if c.namespaceFilter.HasSelector() {
+		// The routes are listed again on any event, so the namespace is enqueued as if it's a route.
+		c.namespaceInformer.AddEventHandler(c.namespaceFilter.Handler(func(namespace string) {
+			q.Add(types.NamespacedName{Name: namespace})
+		}))
+	}
+
+	if options.EnableStatus {
+		c.statusSyncer = newStatusSyncer(client.GatewayAPI(), c)
+	}
+
+	return c
+}
+
+func (c *controller) ServiceLister() listerv1.ServiceLister {
+	return c.serviceLister
+}
+
+func (c *controller) SecretLister() listerv1.SecretLister {
+	return c.secretController.Lister()
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	if c.statusSyncer != nil {
+		go c.statusSyncer.run(stop)
+	}
+
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	if !cache.WaitForCacheSync(stop, c.HasSynced) {
+		IngressLog.Errorf("Failed to sync gateway api controller cache for cluster %s", c.options.ClusterId)
+		return
+	}
+	go wait.Until(c.worker, time.Second, stop)
+	<-stop
+}
+
+func (c *controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	namespacedName := key.(types.NamespacedName)
+	IngressLog.Debugf("gateway api resource %s push to queue", namespacedName)
+	c.onEvent(namespacedName)
+	c.queue.Forget(key)
+	return true
+}
+
+// onEvent notifies the handlers on any change of gateway classes, gateways and routes, as the
+// attachment of a route depends on all of them.
+func (c *controller) onEvent(namespacedName types.NamespacedName) {
+	meta := func(kind config.GroupVersionKind, suffix string) config.Meta {
+		return config.Meta{
+			Name:             namespacedName.Name + "-" + suffix,
+			Namespace:        namespacedName.Namespace,
+			GroupVersionKind: kind,
+			// Set this label so that we do not compare configs and just push.
+			Labels: map[string]string{constants.AlwaysPushLabel: "true"},
+		}
+	}
+
+	drmetadata := meta(gvk.DestinationRule, "destinationrule")
+	vsmetadata := meta(gvk.VirtualService, "virtualservice")
+	efmetadata := meta(gvk.EnvoyFilter, "envoyfilter")
+	gatewaymetadata := meta(gvk.Gateway, "gateway")
+
+	for _, f := range c.destinationRuleHandlers {
+		f(config.Config{Meta: drmetadata}, config.Config{Meta: drmetadata}, model.EventUpdate)
+	}
+
+	for _, f := range c.virtualServiceHandlers {
+		f(config.Config{Meta: vsmetadata}, config.Config{Meta: vsmetadata}, model.EventUpdate)
+	}
+
+	for _, f := range c.envoyFilterHandlers {
+		f(config.Config{Meta: efmetadata}, config.Config{Meta: efmetadata}, model.EventUpdate)
+	}
+
+	for _, f := range c.gatewayHandlers {
+		f(config.Config{Meta: gatewaymetadata}, config.Config{Meta: gatewaymetadata}, model.EventUpdate)
+	}
+}
+
+func (c *controller) RegisterEventHandler(kind config.GroupVersionKind, f model.EventHandler) {
+	switch kind {
+	case gvk.VirtualService:
+		c.virtualServiceHandlers = append(c.virtualServiceHandlers, f)
+	case gvk.Gateway:
+		c.gatewayHandlers = append(c.gatewayHandlers, f)
+	case gvk.DestinationRule:
+		c.destinationRuleHandlers = append(c.destinationRuleHandlers, f)
+	case gvk.EnvoyFilter:
+		c.envoyFilterHandlers = append(c.envoyFilterHandlers, f)
+	}
+}
+
+func (c *controller) SetWatchErrorHandler(handler func(r *cache.Reflector, err error)) error {
+	var errs error
+	for _, informer := range []cache.SharedInformer{c.gatewayClassInformer, c.gatewayInformer,
+		c.httpRouteInformer, c.tlsRouteInformer, c.tcpRouteInformer, c.referencePolicyInformer, c.namespaceInformer} {
+		if err := informer.SetWatchErrorHandler(handler); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (c *controller) HasSynced() bool {
+	return c.gatewayClassInformer.HasSynced() && c.gatewayInformer.HasSynced() &&
+		c.httpRouteInformer.HasSynced() && c.tlsRouteInformer.HasSynced() && c.tcpRouteInformer.HasSynced() &&
+		c.referencePolicyInformer.HasSynced() && c.namespaceInformer.HasSynced() && c.serviceInformer.HasSynced()
+}
+
+// List returns the routes which are attached to at least one listener managed by higress.
+func (c *controller) List() []config.Config {
+	var out []config.Config
+	for _, raw := range c.httpRouteInformer.GetStore().List() {
+		if route, ok := raw.(*v1alpha2.HTTPRoute); ok {
+			route = route.DeepCopy()
+			out = c.appendRoute(out, gvk.HTTPRoute, route.ObjectMeta, route.Spec.ParentRefs, route.Spec)
+		}
+	}
+	for _, raw := range c.tlsRouteInformer.GetStore().List() {
+		if route, ok := raw.(*v1alpha2.TLSRoute); ok {
+			route = route.DeepCopy()
+			out = c.appendRoute(out, gvk.TLSRoute, route.ObjectMeta, route.Spec.ParentRefs, route.Spec)
+		}
+	}
+	for _, raw := range c.tcpRouteInformer.GetStore().List() {
+		if route, ok := raw.(*v1alpha2.TCPRoute); ok {
+			route = route.DeepCopy()
+			out = c.appendRoute(out, gvk.TCPRoute, route.ObjectMeta, route.Spec.ParentRefs, route.Spec)
+		}
+	}
+	return out
+}
+
+// ToConfig returns false, the routes are not validated by the admission webhook.
+func (c *controller) ToConfig(runtime.Object) (*config.Config, bool) {
+	return nil, false
+}
+
+// ClassParamsName returns empty, the routes are configured by their GatewayClass instead.
+func (c *controller) ClassParamsName(*config.Config) string {
+	return ""
+}
+
+// NamespaceGatewaySelector returns nil, the routes are served by the gateways they attach to.
+func (c *controller) NamespaceGatewaySelector(string) map[string]string {
+	return nil
+}
+
+func (c *controller) appendRoute(out []config.Config, kind config.GroupVersionKind, meta metav1.ObjectMeta,
+	parentRefs []v1alpha2.ParentRef, spec config.Spec) []config.Config {
+	if !c.namespaceFilter.Watched(meta.Namespace) {
+		return out
+	}
+
+	if len(c.attachedListeners(kind, meta.Namespace, parentRefs)) == 0 {
+		return out
+	}
+
+	return append(out, config.Config{
+		Meta: config.Meta{
+			GroupVersionKind:  kind,
+			Name:              meta.Name,
+			Namespace:         meta.Namespace,
+			Annotations:       common.CreateOrUpdateAnnotations(meta.Annotations, c.options),
+			Labels:            meta.Labels,
+			CreationTimestamp: meta.CreationTimestamp.Time,
+			UID:               string(meta.UID),
+			ResourceVersion:   meta.ResourceVersion,
+		},
+		Spec: spec,
+	})
+}
+
+func (c *controller) ConvertGateway(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig) error {
+	switch route := wrapper.Config.Spec.(type) {
+	case v1alpha2.HTTPRouteSpec:
+		c.convertHTTPGateway(convertOptions, wrapper, route)
+	case v1alpha2.TLSRouteSpec:
+		c.convertTLSGateway(convertOptions, wrapper, route)
+	case v1alpha2.TCPRouteSpec:
+		c.convertTCPGateway(convertOptions, wrapper, route)
+	default:
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.Unknown)
+		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
+	}
+	return nil
+}
+
+func (c *controller) convertHTTPGateway(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig, route v1alpha2.HTTPRouteSpec) {
+	cfg := wrapper.Config
+	for _, l := range c.attachedListeners(gvk.HTTPRoute, cfg.Namespace, route.ParentRefs) {
+		secretNamespace, secretName, _ := c.certificate(l.gateway, l.listener)
+		if l.listener.Protocol == v1alpha2.HTTPSProtocolType && secretName == "" {
+			IngressLog.Warnf("listener %s of gateway %s/%s in cluster %s has no certificate, ignore it",
+				l.listener.Name, l.gateway.Namespace, l.gateway.Name, c.options.ClusterId)
+			continue
+		}
+
+		for _, host := range intersectHostnames(l.hostname(), route.Hostnames) {
+			domainBuilder, exist := convertOptions.IngressDomainCache.Valid[host]
+			if !exist {
+				domainBuilder = &common.IngressDomainBuilder{
+					ClusterId: c.options.ClusterId,
+					Protocol:  common.HTTP,
+					Host:      host,
+					Ingress:   cfg,
+					Event:     common.Normal,
+				}
+				convertOptions.IngressDomainCache.Valid[host] = domainBuilder
+			}
+
+			var tlsSecret string
+			if secretName != "" {
+				tlsSecret = path.Join(c.options.ClusterId, secretNamespace, secretName)
+			}
+			if convertOptions.HostSettingsCache != nil {
+				convertOptions.HostSettingsCache.Add(host, common.NewHostSettings(wrapper, c.options.ClusterId, tlsSecret))
+			}
+
+			if l.listener.Protocol != v1alpha2.HTTPSProtocolType {
+				c.addServer(convertOptions, wrapper, l, "", host, protocol.HTTP, nil)
+				continue
+			}
+
+			added := c.addServer(convertOptions, wrapper, l, "", host, protocol.HTTPS, &networking.ServerTLSSettings{
+				Mode:           networking.ServerTLSSettings_SIMPLE,
+				CredentialName: credentials.ToKubernetesIngressResource(c.options.RawClusterId, secretNamespace, secretName),
+			})
+			if added {
+				domainBuilder.Protocol = common.HTTPS
+				domainBuilder.SecretName = tlsSecret
+			}
+		}
+	}
+}
+
+// addServer adds the server of the listener to the gateway of the host, or of the scope if it is not
+// empty, and returns false if the gateway already has a server on the same port.
+func (c *controller) addServer(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig, l *attachedListener,
+	scope string, host string, protocol protocol.Instance, tls *networking.ServerTLSSettings) bool {
+	key := host
+	if scope != "" {
+		key = scope
+	}
+	wrapperGateway, exist := convertOptions.Gateways[key]
+	if !exist {
+		wrapperGateway = &common.WrapperGateway{
+			Gateway:       &networking.Gateway{},
+			WrapperConfig: wrapper,
+			ClusterId:     c.options.ClusterId,
+			Host:          host,
+			Scope:         scope,
+		}
+		if c.options.GatewaySelectorKey != "" {
+			wrapperGateway.Gateway.Selector = map[string]string{c.options.GatewaySelectorKey: c.options.GatewaySelectorValue}
+		}
+		convertOptions.Gateways[key] = wrapperGateway
+	}
+
+	port := uint32(l.listener.Port)
+	for _, server := range wrapperGateway.Gateway.Servers {
+		if server.Port.Number == port {
+			return false
+		}
+	}
+
+	wrapperGateway.Gateway.Servers = append(wrapperGateway.Gateway.Servers, &networking.Server{
+		Port: &networking.Port{
+			Number:   port,
+			Protocol: string(protocol),
+			Name: common.CreateConvertedName(fmt.Sprintf("%s-%d-gateway", strings.ToLower(string(l.listener.Protocol)), port),
+				c.options.ClusterId, l.gateway.Namespace, l.gateway.Name, string(l.listener.Name), common.CleanHost(host)),
+		},
+		Hosts: []string{host},
+		Tls:   tls,
+	})
+	return true
+}
+
+func (c *controller) ConvertHTTPRoute(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig) error {
+	switch route := wrapper.Config.Spec.(type) {
+	case v1alpha2.HTTPRouteSpec:
+		return c.convertHTTPRoute(convertOptions, wrapper, route)
+	case v1alpha2.TLSRouteSpec:
+		c.convertTLSRoute(convertOptions, wrapper, route)
+	case v1alpha2.TCPRouteSpec:
+		c.convertTCPRoute(convertOptions, wrapper, route)
+	default:
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.Unknown)
+		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
+	}
+	return nil
+}
+
+func (c *controller) convertHTTPRoute(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig, route v1alpha2.HTTPRouteSpec) error {
+	cfg := wrapper.Config
+	if len(route.Rules) == 0 {
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.EmptyRule)
+		return fmt.Errorf("invalid http route %s:%s in cluster %s, no rules defined", cfg.Namespace, cfg.Name, c.options.ClusterId)
+	}
+
+	for _, host := range c.routeHosts(cfg.Namespace, route) {
+		wrapperVS, exist := convertOptions.VirtualServices[host]
+		if !exist {
+			wrapperVS = &common.WrapperVirtualService{
+				VirtualService: &networking.VirtualService{
+					Hosts: []string{host},
+				},
+				WrapperConfig: wrapper,
+			}
+			convertOptions.VirtualServices[host] = wrapperVS
+		}
+
+		var wrapperHttpRoutes []*common.WrapperHTTPRoute
+		var tempHostAndPath []string
+		for ruleIdx, rule := range route.Rules {
+			matches := rule.Matches
+			if len(matches) == 0 {
+				// Match all requests by default.
+				matches = []v1alpha2.HTTPRouteMatch{{}}
+			}
+
+			for matchIdx, match := range matches {
+				wrapperHttpRoute := &common.WrapperHTTPRoute{
+					HTTPRoute:     &networking.HTTPRoute{},
+					WrapperConfig: wrapper,
+					Host:          host,
+					ClusterId:     c.options.ClusterId,
+				}
+				wrapperHttpRoute.HTTPRoute.Match = []*networking.HTTPMatchRequest{convertMatch(wrapperHttpRoute, match)}
+				if wrapperHttpRoute.OriginPathType == common.Prefix && wrapperHttpRoute.OriginPath == "/" {
+					wrapperVS.ConfiguredDefaultBackend = true
+				}
+				wrapperHttpRoute.HTTPRoute.Name = common.GenerateUniqueRouteNameWithSuffix(wrapperHttpRoute,
+					fmt.Sprintf("rule-%d-match-%d", ruleIdx, matchIdx))
+
+				ingressRouteBuilder := convertOptions.IngressRouteCache.New(wrapperHttpRoute)
+
+				// Only the routes matching on path can overlay each other.
+				if isPathOnlyMatch(match) {
+					hostAndPath := wrapperHttpRoute.BasePathFormat()
+					if preIngress, exist := convertOptions.HostAndPath2Ingress[hostAndPath]; exist {
+						ingressRouteBuilder.PreIngress = preIngress
+						ingressRouteBuilder.Event = common.DuplicatedRoute
+					}
+					tempHostAndPath = append(tempHostAndPath, hostAndPath)
+				}
+
+				c.applyFilters(wrapperHttpRoute.HTTPRoute, rule.Filters, cfg.Namespace)
+
+				var event common.Event
+				wrapperHttpRoute.HTTPRoute.Route, wrapperHttpRoute.WeightTotal, event = c.backendRefsToRouteDestination(rule.BackendRefs, cfg.Namespace, ingressRouteBuilder)
+				// A redirect route needs no backend.
+				if wrapperHttpRoute.HTTPRoute.Redirect != nil {
+					wrapperHttpRoute.HTTPRoute.Route = nil
+					event = common.Normal
+				}
+
+				if ingressRouteBuilder.Event != common.Normal {
+					event = ingressRouteBuilder.Event
+				}
+
+				if event != common.Normal {
+					convertOptions.AddInvalidIngress(c.options.ClusterId, event)
+					ingressRouteBuilder.Event = event
+				} else {
+					wrapperHttpRoutes = append(wrapperHttpRoutes, wrapperHttpRoute)
+				}
+
+				convertOptions.IngressRouteCache.Add(ingressRouteBuilder)
+			}
+		}
+
+		for _, item := range tempHostAndPath {
+			// We only record the first
+			if _, exist := convertOptions.HostAndPath2Ingress[item]; !exist {
+				convertOptions.HostAndPath2Ingress[item] = cfg
+			}
+		}
+
+		// The more conditions a route matches on, the higher precedence it has among the routes with
+		// the same path.
+		sort.SliceStable(wrapperHttpRoutes, func(i, j int) bool {
+			return matchConditions(wrapperHttpRoutes[i].HTTPRoute) > matchConditions(wrapperHttpRoutes[j].HTTPRoute)
+		})
+		convertOptions.HTTPRoutes[host] = append(convertOptions.HTTPRoutes[host], wrapperHttpRoutes...)
+
+		// Sort, exact -> prefix -> regex
+		routes := convertOptions.HTTPRoutes[host]
+		IngressLog.Debugf("routes of host %s is %v", host, routes)
+		common.SortHTTPRoutes(routes)
+	}
+
+	return nil
+}
+
+// ApplyDefaultBackend is a no-op, as a http route has no default backend.
+func (c *controller) ApplyDefaultBackend(*common.ConvertOptions, *common.WrapperConfig) error {
+	return nil
+}
+
+// ApplyCanaryIngress is a no-op, the traffic of a http route is split by the weights of backend refs.
+func (c *controller) ApplyCanaryIngress(*common.ConvertOptions, *common.WrapperConfig) error {
+	return nil
+}
+
+// ConvertServiceEntry is a no-op, the backend refs of the routes are routed to the services as is.
+func (c *controller) ConvertServiceEntry(*common.ConvertOptions, *common.WrapperConfig) error {
+	return nil
+}
+
+func (c *controller) ConvertTrafficPolicy(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig) error {
+	if !wrapper.AnnotationsConfig.NeedTrafficPolicy() {
+		return nil
+	}
+
+	cfg := wrapper.Config
+	// The annotations of traffic policy only take effect on http routes.
+	route, ok := cfg.Spec.(v1alpha2.HTTPRouteSpec)
+	if !ok {
+		return nil
+	}
+
+	for _, rule := range route.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			serviceKey, reason := c.resolveBackendRef(gvk.HTTPRoute, backendRef.BackendObjectReference, cfg.Namespace)
+			if reason != "" {
+				continue
+			}
+
+			if _, exist := convertOptions.Service2TrafficPolicy[serviceKey]; exist {
+				continue
+			}
+
+			convertOptions.Service2TrafficPolicy[serviceKey] = &common.WrapperTrafficPolicy{
+				TrafficPolicy: &networking.TrafficPolicy_PortTrafficPolicy{
+					Port: &networking.PortSelector{
+						Number: uint32(serviceKey.Port),
+					},
+				},
+				WrapperConfig: wrapper,
+			}
+		}
+	}
+
+	return nil
+}
+
+// routeHosts returns the hosts of the route on all the listeners it attaches to, in order.
+func (c *controller) routeHosts(namespace string, route v1alpha2.HTTPRouteSpec) []string {
+	var hosts []string
+	seen := map[string]bool{}
+	for _, l := range c.attachedListeners(gvk.HTTPRoute, namespace, route.ParentRefs) {
+		for _, host := range intersectHostnames(l.hostname(), route.Hostnames) {
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
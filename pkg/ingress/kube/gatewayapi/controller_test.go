@@ -0,0 +1,642 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+)
+
+func newTestController(t *testing.T) *controller {
+	c := NewController(kube.NewFakeClient(), common.Options{
+		ClusterId:    "gw",
+		RawClusterId: "gw__",
+	}, nil).(*controller)
+
+	hostname := v1alpha2.Hostname("*.foo.com")
+	passthrough := v1alpha2.TLSModePassthrough
+	objects := []struct {
+		store  cache.Store
+		object interface{}
+	}{
+		{
+			store: c.gatewayClassInformer.GetStore(),
+			object: &v1alpha2.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "higress"},
+				Spec:       v1alpha2.GatewayClassSpec{ControllerName: ControllerName},
+			},
+		},
+		{
+			store: c.gatewayClassInformer.GetStore(),
+			object: &v1alpha2.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "other"},
+				Spec:       v1alpha2.GatewayClassSpec{ControllerName: "example.com/gateway-controller"},
+			},
+		},
+		{
+			store: c.gatewayInformer.GetStore(),
+			object: &v1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "default"},
+				Spec: v1alpha2.GatewaySpec{
+					GatewayClassName: "higress",
+					Listeners: []v1alpha2.Listener{
+						{
+							Name:     "http",
+							Port:     80,
+							Protocol: v1alpha2.HTTPProtocolType,
+						},
+						{
+							Name:     "https",
+							Hostname: &hostname,
+							Port:     443,
+							Protocol: v1alpha2.HTTPSProtocolType,
+							TLS: &v1alpha2.GatewayTLSConfig{
+								CertificateRefs: []*v1alpha2.SecretObjectReference{{Name: "foo-cert"}},
+							},
+						},
+						{
+							Name:     "tcp",
+							Port:     9000,
+							Protocol: v1alpha2.TCPProtocolType,
+						},
+						{
+							Name:     "tls",
+							Port:     8443,
+							Protocol: v1alpha2.TLSProtocolType,
+							TLS:      &v1alpha2.GatewayTLSConfig{Mode: &passthrough},
+						},
+					},
+				},
+			},
+		},
+		{
+			store: c.gatewayInformer.GetStore(),
+			object: &v1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+				Spec: v1alpha2.GatewaySpec{
+					GatewayClassName: "other",
+					Listeners: []v1alpha2.Listener{
+						{
+							Name:     "http",
+							Port:     80,
+							Protocol: v1alpha2.HTTPProtocolType,
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, item := range objects {
+		if err := item.store.Add(item.object); err != nil {
+			t.Fatalf("add object error %v", err)
+		}
+	}
+	return c
+}
+
+func newTestRoute(name string, spec v1alpha2.HTTPRouteSpec) *v1alpha2.HTTPRoute {
+	return &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       spec,
+	}
+}
+
+func newTestWrapper(route *v1alpha2.HTTPRoute) *common.WrapperConfig {
+	return &common.WrapperConfig{
+		Config: &config.Config{
+			Meta: config.Meta{
+				Name:      route.Name,
+				Namespace: route.Namespace,
+			},
+			Spec: route.Spec,
+		},
+		AnnotationsConfig: &annotations.Ingress{},
+	}
+}
+
+func parentRefs(gateway string, section string) []v1alpha2.ParentRef {
+	ref := v1alpha2.ParentRef{Name: v1alpha2.ObjectName(gateway)}
+	if section != "" {
+		sectionName := v1alpha2.SectionName(section)
+		ref.SectionName = &sectionName
+	}
+	return []v1alpha2.ParentRef{ref}
+}
+
+func TestList(t *testing.T) {
+	c := newTestController(t)
+	routes := []*v1alpha2.HTTPRoute{
+		newTestRoute("managed", v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "")},
+		}),
+		newTestRoute("other-class", v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("other", "")},
+		}),
+		newTestRoute("tcp-listener", v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "tcp")},
+		}),
+		newTestRoute("not-found", v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("not-found", "")},
+		}),
+	}
+	for _, route := range routes {
+		if err := c.httpRouteInformer.GetStore().Add(route); err != nil {
+			t.Fatalf("add route error %v", err)
+		}
+	}
+
+	result := c.List()
+	assert.Len(t, result, 1)
+	assert.Equal(t, "managed", result[0].Name)
+	assert.True(t, IsRoute(result[0].GroupVersionKind))
+	assert.Equal(t, "gw", common.GetClusterId(result[0].Annotations))
+}
+
+func TestConvertGateway(t *testing.T) {
+	c := newTestController(t)
+	route := newTestRoute("route", v1alpha2.HTTPRouteSpec{
+		CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "")},
+		Hostnames:       []v1alpha2.Hostname{"a.foo.com", "bar.com"},
+	})
+
+	convertOptions := &common.ConvertOptions{
+		IngressDomainCache: common.NewIngressDomainCache(),
+		Gateways:           map[string]*common.WrapperGateway{},
+	}
+	assert.NoError(t, c.ConvertGateway(convertOptions, newTestWrapper(route)))
+
+	assert.Len(t, convertOptions.Gateways, 2)
+	assert.Equal(t, []*networking.Server{
+		{
+			Port: &networking.Port{
+				Number:   80,
+				Protocol: "HTTP",
+				Name:     "http-80-gateway-gw-default-gateway-http-a-foo-com",
+			},
+			Hosts: []string{"a.foo.com"},
+		},
+		{
+			Port: &networking.Port{
+				Number:   443,
+				Protocol: "HTTPS",
+				Name:     "https-443-gateway-gw-default-gateway-https-a-foo-com",
+			},
+			Hosts: []string{"a.foo.com"},
+			Tls: &networking.ServerTLSSettings{
+				Mode:           networking.ServerTLSSettings_SIMPLE,
+				CredentialName: "kubernetes-ingress://gw__/default/foo-cert",
+			},
+		},
+	}, convertOptions.Gateways["a.foo.com"].Gateway.Servers)
+	// bar.com does not match the hostname of https listener.
+	assert.Len(t, convertOptions.Gateways["bar.com"].Gateway.Servers, 1)
+	assert.Equal(t, common.HTTPS, convertOptions.IngressDomainCache.Valid["a.foo.com"].Protocol)
+	assert.Equal(t, common.HTTP, convertOptions.IngressDomainCache.Valid["bar.com"].Protocol)
+}
+
+func TestConvertHTTPRoute(t *testing.T) {
+	c := newTestController(t)
+
+	exact := v1alpha2.PathMatchExact
+	prefix := v1alpha2.PathMatchPathPrefix
+	regex := v1alpha2.HeaderMatchRegularExpression
+	post := v1alpha2.HTTPMethodPost
+	port := v1alpha2.PortNumber(8080)
+	weight := int32(3)
+	scheme := "https"
+	statusCode := 301
+	route := newTestRoute("route", v1alpha2.HTTPRouteSpec{
+		CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "http")},
+		Hostnames:       []v1alpha2.Hostname{"a.com"},
+		Rules: []v1alpha2.HTTPRouteRule{
+			{
+				Matches: []v1alpha2.HTTPRouteMatch{
+					{
+						Path: &v1alpha2.HTTPPathMatch{Type: &prefix, Value: stringPtr("/api/")},
+					},
+					{
+						Path:        &v1alpha2.HTTPPathMatch{Type: &prefix, Value: stringPtr("/api")},
+						Headers:     []v1alpha2.HTTPHeaderMatch{{Name: "X-Version", Value: "v2", Type: &regex}},
+						QueryParams: []v1alpha2.HTTPQueryParamMatch{{Name: "user", Value: "foo"}},
+						Method:      &post,
+					},
+				},
+				Filters: []v1alpha2.HTTPRouteFilter{
+					{
+						Type: v1alpha2.HTTPRouteFilterRequestHeaderModifier,
+						RequestHeaderModifier: &v1alpha2.HTTPRequestHeaderFilter{
+							Set:    []v1alpha2.HTTPHeader{{Name: "x-set", Value: "a"}},
+							Add:    []v1alpha2.HTTPHeader{{Name: "x-add", Value: "b"}},
+							Remove: []string{"x-remove"},
+						},
+					},
+				},
+				BackendRefs: []v1alpha2.HTTPBackendRef{
+					{BackendRef: v1alpha2.BackendRef{BackendObjectReference: v1alpha2.BackendObjectReference{Name: "v1", Port: &port}}},
+					{BackendRef: v1alpha2.BackendRef{BackendObjectReference: v1alpha2.BackendObjectReference{Name: "v2", Port: &port}, Weight: &weight}},
+				},
+			},
+			{
+				Matches: []v1alpha2.HTTPRouteMatch{
+					{
+						Path: &v1alpha2.HTTPPathMatch{Type: &exact, Value: stringPtr("/old")},
+					},
+				},
+				Filters: []v1alpha2.HTTPRouteFilter{
+					{
+						Type:            v1alpha2.HTTPRouteFilterRequestRedirect,
+						RequestRedirect: &v1alpha2.HTTPRequestRedirectFilter{Scheme: &scheme, StatusCode: &statusCode},
+					},
+				},
+			},
+			{
+				// No backend refs.
+				Matches: []v1alpha2.HTTPRouteMatch{
+					{
+						Path: &v1alpha2.HTTPPathMatch{Type: &exact, Value: stringPtr("/invalid")},
+					},
+				},
+			},
+		},
+	})
+
+	convertOptions := &common.ConvertOptions{
+		HostAndPath2Ingress: map[string]*config.Config{},
+		IngressRouteCache:   common.NewIngressRouteCache(),
+		VirtualServices:     map[string]*common.WrapperVirtualService{},
+		HTTPRoutes:          map[string][]*common.WrapperHTTPRoute{},
+	}
+	assert.NoError(t, c.ConvertHTTPRoute(convertOptions, newTestWrapper(route)))
+
+	routes := convertOptions.HTTPRoutes["a.com"]
+	assert.Len(t, routes, 3)
+	assert.Equal(t, "default/route-rule-1-match-0", routes[0].HTTPRoute.Name)
+	assert.Equal(t, &networking.HTTPRedirect{Scheme: "https", RedirectCode: 301}, routes[0].HTTPRoute.Redirect)
+	assert.Nil(t, routes[0].HTTPRoute.Route)
+
+	// The route with more conditions goes first.
+	withConditions, pathOnly := routes[1], routes[2]
+	assert.Equal(t, "default/route-rule-0-match-1", withConditions.HTTPRoute.Name)
+	assert.Equal(t, &networking.HTTPMatchRequest{
+		Uri: &networking.StringMatch{
+			MatchType: &networking.StringMatch_Regex{Regex: "/api" + common.PrefixMatchRegex},
+		},
+		Headers: map[string]*networking.StringMatch{
+			"x-version": {MatchType: &networking.StringMatch_Regex{Regex: "v2"}},
+		},
+		QueryParams: map[string]*networking.StringMatch{
+			"user": {MatchType: &networking.StringMatch_Exact{Exact: "foo"}},
+		},
+		Method: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "POST"}},
+	}, withConditions.HTTPRoute.Match[0])
+	assert.Equal(t, "default/route-rule-0-match-0", pathOnly.HTTPRoute.Name)
+	assert.Equal(t, "/api", pathOnly.OriginPath)
+
+	assert.Equal(t, int32(4), pathOnly.WeightTotal)
+	assert.Equal(t, []*networking.HTTPRouteDestination{
+		{
+			Destination: &networking.Destination{
+				Host: "v1.default.svc.cluster.local",
+				Port: &networking.PortSelector{Number: 8080},
+			},
+			Weight: 1,
+		},
+		{
+			Destination: &networking.Destination{
+				Host: "v2.default.svc.cluster.local",
+				Port: &networking.PortSelector{Number: 8080},
+			},
+			Weight: 3,
+		},
+	}, pathOnly.HTTPRoute.Route)
+	assert.Equal(t, &networking.Headers{
+		Request: &networking.Headers_HeaderOperations{
+			Set:    map[string]string{"x-set": "a"},
+			Add:    map[string]string{"x-add": "b"},
+			Remove: []string{"x-remove"},
+		},
+	}, pathOnly.HTTPRoute.Headers)
+
+	invalid := convertOptions.IngressRouteCache.Extract().Invalid
+	assert.Len(t, invalid, 1)
+}
+
+func TestConvertL4Route(t *testing.T) {
+	c := newTestController(t)
+	port := v1alpha2.PortNumber(8080)
+	weight := int32(2)
+	backendRefs := []v1alpha2.BackendRef{
+		{BackendObjectReference: v1alpha2.BackendObjectReference{Name: "v1", Port: &port}},
+		{BackendObjectReference: v1alpha2.BackendObjectReference{Name: "v2", Port: &port}, Weight: &weight},
+	}
+	tlsRoute := &config.Config{
+		Meta: config.Meta{Name: "tls", Namespace: "default"},
+		Spec: v1alpha2.TLSRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "")},
+			Hostnames:       []v1alpha2.Hostname{"a.com"},
+			Rules:           []v1alpha2.TLSRouteRule{{BackendRefs: backendRefs}},
+		},
+	}
+	tcpRoute := &config.Config{
+		Meta: config.Meta{Name: "tcp", Namespace: "default"},
+		Spec: v1alpha2.TCPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "")},
+			Rules:           []v1alpha2.TCPRouteRule{{BackendRefs: backendRefs}},
+		},
+	}
+
+	convertOptions := &common.ConvertOptions{
+		IngressDomainCache: common.NewIngressDomainCache(),
+		Gateways:           map[string]*common.WrapperGateway{},
+		TLSRoutes:          map[string][]*common.WrapperTLSRoute{},
+		TCPRoutes:          map[string][]*common.WrapperTCPRoute{},
+	}
+	for _, cfg := range []*config.Config{tlsRoute, tcpRoute} {
+		wrapper := &common.WrapperConfig{Config: cfg, AnnotationsConfig: &annotations.Ingress{}}
+		assert.NoError(t, c.ConvertGateway(convertOptions, wrapper))
+		assert.NoError(t, c.ConvertHTTPRoute(convertOptions, wrapper))
+	}
+
+	assert.Equal(t, []*networking.Server{
+		{
+			Port: &networking.Port{
+				Number:   8443,
+				Protocol: "TLS",
+				Name:     "tls-8443-gateway-gw-default-gateway-tls-a-com",
+			},
+			Hosts: []string{"a.com"},
+			Tls:   &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_PASSTHROUGH},
+		},
+	}, convertOptions.Gateways["a.com"].Gateway.Servers)
+	assert.Equal(t, []*networking.Server{
+		{
+			Port: &networking.Port{
+				Number:   9000,
+				Protocol: "TCP",
+				Name:     "tcp-9000-gateway-gw-default-gateway-tcp-global",
+			},
+			Hosts: []string{"*"},
+		},
+	}, convertOptions.Gateways["tcp-default-gateway-tcp"].Gateway.Servers)
+	assert.Equal(t, "tcp-default-gateway-tcp", convertOptions.Gateways["tcp-default-gateway-tcp"].Scope)
+
+	destinations := []*networking.RouteDestination{
+		{
+			Destination: &networking.Destination{
+				Host: "v1.default.svc.cluster.local",
+				Port: &networking.PortSelector{Number: 8080},
+			},
+			Weight: 33,
+		},
+		{
+			Destination: &networking.Destination{
+				Host: "v2.default.svc.cluster.local",
+				Port: &networking.PortSelector{Number: 8080},
+			},
+			Weight: 67,
+		},
+	}
+	assert.Len(t, convertOptions.TLSRoutes["a.com"], 1)
+	assert.Equal(t, &networking.TLSRoute{
+		Match: []*networking.TLSMatchAttributes{{SniHosts: []string{"a.com"}, Port: 8443}},
+		Route: destinations,
+	}, convertOptions.TLSRoutes["a.com"][0].TLSRoute)
+	assert.Len(t, convertOptions.TCPRoutes["tcp-default-gateway-tcp"], 1)
+	assert.Equal(t, &networking.TCPRoute{
+		Match: []*networking.L4MatchAttributes{{Port: 9000}},
+		Route: destinations,
+	}, convertOptions.TCPRoutes["tcp-default-gateway-tcp"][0].TCPRoute)
+}
+
+func TestConvertTCPRouteScope(t *testing.T) {
+	c := newTestController(t)
+	gateway, err := c.gatewayLister.Gateways("default").Get("gateway")
+	if err != nil {
+		t.Fatalf("get gateway error %v", err)
+	}
+	other := gateway.DeepCopy()
+	other.Name = "other"
+	if err := c.gatewayInformer.GetStore().Add(other); err != nil {
+		t.Fatalf("add gateway error %v", err)
+	}
+
+	port := v1alpha2.PortNumber(8080)
+	tcpRoute := func(name string, gateway string) *config.Config {
+		return &config.Config{
+			Meta: config.Meta{Name: name, Namespace: "default"},
+			Spec: v1alpha2.TCPRouteSpec{
+				CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs(gateway, "tcp")},
+				Rules: []v1alpha2.TCPRouteRule{{BackendRefs: []v1alpha2.BackendRef{
+					{BackendObjectReference: v1alpha2.BackendObjectReference{Name: v1alpha2.ObjectName(name), Port: &port}},
+				}}},
+			},
+		}
+	}
+
+	convertOptions := &common.ConvertOptions{
+		IngressDomainCache: common.NewIngressDomainCache(),
+		Gateways:           map[string]*common.WrapperGateway{},
+		TLSRoutes:          map[string][]*common.WrapperTLSRoute{},
+		TCPRoutes:          map[string][]*common.WrapperTCPRoute{},
+	}
+	for _, cfg := range []*config.Config{tcpRoute("a", "gateway"), tcpRoute("b", "other")} {
+		wrapper := &common.WrapperConfig{Config: cfg, AnnotationsConfig: &annotations.Ingress{}}
+		assert.NoError(t, c.ConvertGateway(convertOptions, wrapper))
+		assert.NoError(t, c.ConvertHTTPRoute(convertOptions, wrapper))
+	}
+
+	// The routes of the same port on different gateways are not merged.
+	assert.Len(t, convertOptions.Gateways, 2)
+	assert.Len(t, convertOptions.TCPRoutes, 2)
+	for scope, backend := range map[string]string{
+		"tcp-default-gateway-tcp": "a.default.svc.cluster.local",
+		"tcp-default-other-tcp":   "b.default.svc.cluster.local",
+	} {
+		assert.Equal(t, []string{"*"}, convertOptions.Gateways[scope].Gateway.Servers[0].Hosts)
+		assert.Len(t, convertOptions.TCPRoutes[scope], 1)
+		assert.Equal(t, "*", convertOptions.TCPRoutes[scope][0].Host)
+		assert.Equal(t, backend, convertOptions.TCPRoutes[scope][0].TCPRoute.Route[0].Destination.Host)
+	}
+}
+
+func TestPercentWeights(t *testing.T) {
+	testCases := []struct {
+		name    string
+		weights []int32
+		expect  []int32
+	}{
+		{
+			name:    "single",
+			weights: []int32{5},
+			expect:  []int32{100},
+		},
+		{
+			name:    "largest remainder",
+			weights: []int32{1, 1, 1},
+			expect:  []int32{34, 33, 33},
+		},
+		{
+			name:    "later remainder",
+			weights: []int32{2, 1, 3, 3},
+			expect:  []int32{22, 11, 34, 33},
+		},
+		{
+			name:    "small weight",
+			weights: []int32{1, 1000},
+			expect:  []int32{1, 99},
+		},
+		{
+			name:    "small weights",
+			weights: []int32{1000, 1, 1, 1000},
+			expect:  []int32{49, 1, 1, 49},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var total int32
+			for _, weight := range testCase.weights {
+				total += weight
+			}
+			assert.Equal(t, testCase.expect, percentWeights(testCase.weights, total))
+		})
+	}
+}
+
+func TestAllowedRoutes(t *testing.T) {
+	c := newTestController(t)
+	if err := c.namespaceInformer.GetStore().Add(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}},
+	}); err != nil {
+		t.Fatalf("add namespace error %v", err)
+	}
+	gateway := &v1alpha2.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "default"}}
+
+	all := v1alpha2.NamespacesFromAll
+	selector := v1alpha2.NamespacesFromSelector
+	testCases := []struct {
+		name       string
+		namespaces *v1alpha2.RouteNamespaces
+		namespace  string
+		expect     bool
+	}{
+		{
+			name:      "same by default",
+			namespace: "default",
+			expect:    true,
+		},
+		{
+			name:      "other namespace by default",
+			namespace: "team-a",
+		},
+		{
+			name:       "all",
+			namespaces: &v1alpha2.RouteNamespaces{From: &all},
+			namespace:  "team-a",
+			expect:     true,
+		},
+		{
+			name: "selector matches",
+			namespaces: &v1alpha2.RouteNamespaces{
+				From:     &selector,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			},
+			namespace: "team-a",
+			expect:    true,
+		},
+		{
+			name: "selector does not match",
+			namespaces: &v1alpha2.RouteNamespaces{
+				From:     &selector,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}},
+			},
+			namespace: "team-a",
+		},
+		{
+			name:       "selector is missing",
+			namespaces: &v1alpha2.RouteNamespaces{From: &selector},
+			namespace:  "team-a",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			listener := v1alpha2.Listener{AllowedRoutes: &v1alpha2.AllowedRoutes{Namespaces: testCase.namespaces}}
+			assert.Equal(t, testCase.expect, c.allowsNamespace(gateway, listener, testCase.namespace))
+		})
+	}
+
+	tcpKind := v1alpha2.RouteGroupKind{Kind: "TCPRoute"}
+	listener := v1alpha2.Listener{
+		Protocol:      v1alpha2.TCPProtocolType,
+		AllowedRoutes: &v1alpha2.AllowedRoutes{Kinds: []v1alpha2.RouteGroupKind{tcpKind}},
+	}
+	assert.True(t, acceptsRoute(listener, gvk.TCPRoute))
+	assert.False(t, acceptsRoute(listener, gvk.HTTPRoute))
+	listener.AllowedRoutes.Kinds = []v1alpha2.RouteGroupKind{{Kind: "UDPRoute"}}
+	assert.False(t, acceptsRoute(listener, gvk.TCPRoute))
+}
+
+func TestIntersectHostnames(t *testing.T) {
+	testCases := []struct {
+		listener string
+		route    []v1alpha2.Hostname
+		expect   []string
+	}{
+		{
+			expect: []string{"*"},
+		},
+		{
+			listener: "a.com",
+			expect:   []string{"a.com"},
+		},
+		{
+			route:  []v1alpha2.Hostname{"a.com", "b.com"},
+			expect: []string{"a.com", "b.com"},
+		},
+		{
+			listener: "*.foo.com",
+			route:    []v1alpha2.Hostname{"a.foo.com", "foo.com", "*.bar.foo.com", "a.com"},
+			expect:   []string{"a.foo.com", "*.bar.foo.com"},
+		},
+		{
+			listener: "a.foo.com",
+			route:    []v1alpha2.Hostname{"*.foo.com", "b.foo.com"},
+			expect:   []string{"a.foo.com"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.listener, func(t *testing.T) {
+			assert.Equal(t, testCase.expect, intersectHostnames(testCase.listener, testCase.route))
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
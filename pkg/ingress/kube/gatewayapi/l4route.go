@@ -0,0 +1,226 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"sort"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// tcpHost is the host of tcp routes, which are routed by port only.
+const tcpHost = "*"
+
+// tcpScope returns the scope of the gateway and virtual service converted from the tcp routes
+// attached to the listener, so that the tcp routes of different listeners are kept apart.
+func tcpScope(l *attachedListener) string {
+	return common.CleanHost(common.CreateConvertedName("tcp", l.gateway.Namespace, l.gateway.Name, string(l.listener.Name)))
+}
+
+func (c *controller) convertTLSGateway(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig, route v1alpha2.TLSRouteSpec) {
+	for _, l := range c.attachedListeners(gvk.TLSRoute, wrapper.Config.Namespace, route.ParentRefs) {
+		for _, host := range intersectHostnames(l.hostname(), route.Hostnames) {
+			c.addServer(convertOptions, wrapper, l, "", host, protocol.TLS, &networking.ServerTLSSettings{
+				Mode: networking.ServerTLSSettings_PASSTHROUGH,
+			})
+		}
+	}
+}
+
+func (c *controller) convertTCPGateway(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig, route v1alpha2.TCPRouteSpec) {
+	for _, l := range c.attachedListeners(gvk.TCPRoute, wrapper.Config.Namespace, route.ParentRefs) {
+		c.addServer(convertOptions, wrapper, l, tcpScope(l), tcpHost, protocol.TCP, nil)
+	}
+}
+
+// convertTLSRoute routes the tls connections by sni on the ports of attached listeners.
+func (c *controller) convertTLSRoute(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig, route v1alpha2.TLSRouteSpec) {
+	cfg := wrapper.Config
+	type hostAndPort struct {
+		host string
+		port v1alpha2.PortNumber
+	}
+	seen := map[hostAndPort]bool{}
+	for _, l := range c.attachedListeners(gvk.TLSRoute, cfg.Namespace, route.ParentRefs) {
+		for _, host := range intersectHostnames(l.hostname(), route.Hostnames) {
+			key := hostAndPort{host: host, port: l.listener.Port}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			for _, rule := range route.Rules {
+				destinations := c.l4RouteDestinations(gvk.TLSRoute, rule.BackendRefs, cfg.Namespace)
+				if len(destinations) == 0 {
+					convertOptions.AddInvalidIngress(c.options.ClusterId, common.InvalidBackendService)
+					IngressLog.Warnf("tls route %s/%s in cluster %s has no valid backend", cfg.Namespace, cfg.Name, c.options.ClusterId)
+					continue
+				}
+
+				convertOptions.TLSRoutes[host] = append(convertOptions.TLSRoutes[host], &common.WrapperTLSRoute{
+					TLSRoute: &networking.TLSRoute{
+						Match: []*networking.TLSMatchAttributes{
+							{
+								SniHosts: []string{host},
+								Port:     uint32(l.listener.Port),
+							},
+						},
+						Route: destinations,
+					},
+					WrapperConfig: wrapper,
+					ClusterId:     c.options.ClusterId,
+					Host:          host,
+				})
+			}
+		}
+	}
+}
+
+// convertTCPRoute routes the tcp connections on the ports of attached listeners.
+func (c *controller) convertTCPRoute(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig, route v1alpha2.TCPRouteSpec) {
+	cfg := wrapper.Config
+	seen := map[string]bool{}
+	for _, l := range c.attachedListeners(gvk.TCPRoute, cfg.Namespace, route.ParentRefs) {
+		scope := tcpScope(l)
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+
+		for _, rule := range route.Rules {
+			destinations := c.l4RouteDestinations(gvk.TCPRoute, rule.BackendRefs, cfg.Namespace)
+			if len(destinations) == 0 {
+				convertOptions.AddInvalidIngress(c.options.ClusterId, common.InvalidBackendService)
+				IngressLog.Warnf("tcp route %s/%s in cluster %s has no valid backend", cfg.Namespace, cfg.Name, c.options.ClusterId)
+				continue
+			}
+
+			convertOptions.TCPRoutes[scope] = append(convertOptions.TCPRoutes[scope], &common.WrapperTCPRoute{
+				TCPRoute: &networking.TCPRoute{
+					Match: []*networking.L4MatchAttributes{
+						{
+							Port: uint32(l.listener.Port),
+						},
+					},
+					Route: destinations,
+				},
+				WrapperConfig: wrapper,
+				ClusterId:     c.options.ClusterId,
+				Host:          tcpHost,
+			})
+		}
+	}
+}
+
+// l4RouteDestinations converts the backend refs to destinations whose weights sum to 100, the
+// invalid backend refs are ignored.
+func (c *controller) l4RouteDestinations(kind config.GroupVersionKind, backendRefs []v1alpha2.BackendRef,
+	namespace string) []*networking.RouteDestination {
+	var destinations []*networking.RouteDestination
+	var weightTotal int32
+	for _, backendRef := range backendRefs {
+		weight := int32(1)
+		if backendRef.Weight != nil {
+			weight = *backendRef.Weight
+		}
+		if weight == 0 {
+			continue
+		}
+
+		serviceKey, reason := c.resolveBackendRef(kind, backendRef.BackendObjectReference, namespace)
+		if reason != "" {
+			continue
+		}
+
+		destinations = append(destinations, &networking.RouteDestination{
+			Destination: &networking.Destination{
+				Host: util.CreateServiceFQDN(serviceKey.Namespace, serviceKey.Name),
+				Port: &networking.PortSelector{
+					Number: uint32(serviceKey.Port),
+				},
+			},
+			Weight: weight,
+		})
+		weightTotal += weight
+	}
+
+	if len(destinations) == 0 {
+		return nil
+	}
+
+	weights := make([]int32, len(destinations))
+	for i, destination := range destinations {
+		weights[i] = destination.Weight
+	}
+	for i, weight := range percentWeights(weights, weightTotal) {
+		destinations[i].Weight = weight
+	}
+	return destinations
+}
+
+// percentWeights scales the non-zero weights to percentages which sum to 100 by the largest
+// remainder method, and every weight keeps at least 1 percent so that no backend is dropped.
+func percentWeights(weights []int32, weightTotal int32) []int32 {
+	percents := make([]int32, len(weights))
+	remainders := make([]int64, len(weights))
+	var sum int32
+	for i, weight := range weights {
+		scaled := int64(weight) * 100
+		percents[i] = int32(scaled / int64(weightTotal))
+		remainders[i] = scaled % int64(weightTotal)
+		if percents[i] == 0 {
+			percents[i] = 1
+			remainders[i] = 0
+		}
+		sum += percents[i]
+	}
+
+	// The percents with the largest remainders are rounded up, and the earlier one wins the tie.
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; sum < 100; i = (i + 1) % len(order) {
+		percents[order[i]]++
+		sum++
+	}
+
+	// The percents raised to the minimum are taken back from the largest ones.
+	for sum > 100 {
+		largest := 0
+		for i := range percents {
+			if percents[i] > percents[largest] {
+				largest = i
+			}
+		}
+		if percents[largest] <= 1 {
+			break
+		}
+		percents[largest]--
+		sum--
+	}
+	return percents
+}
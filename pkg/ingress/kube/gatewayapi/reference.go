@@ -0,0 +1,79 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"istio.io/istio/pkg/config"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+)
+
+const (
+	// The reasons of the ResolvedRefs condition of routes, which aren't defined in this version of gateway api.
+	routeReasonResolvedRefs    = "ResolvedRefs"
+	routeReasonRefNotPermitted = "RefNotPermitted"
+	routeReasonInvalidKind     = "InvalidKind"
+)
+
+// referenceAllowed returns whether the object of the kind in the namespace from can refer to the
+// core object of the kind toKind named toName in the namespace to. The reference across namespaces
+// is only allowed by a ReferencePolicy in the namespace to.
+func (c *controller) referenceAllowed(fromKind string, from string, toKind string, to string, toName string) bool {
+	if from == to {
+		return true
+	}
+
+	policies, err := c.referencePolicyLister.ReferencePolicies(to).List(labels.Everything())
+	if err != nil {
+		return false
+	}
+	for _, policy := range policies {
+		fromAllowed := false
+		for _, item := range policy.Spec.From {
+			if string(item.Group) == gatewayGroup && string(item.Kind) == fromKind && string(item.Namespace) == from {
+				fromAllowed = true
+				break
+			}
+		}
+		if !fromAllowed {
+			continue
+		}
+		for _, item := range policy.Spec.To {
+			if item.Group != "" || string(item.Kind) != toKind {
+				continue
+			}
+			if item.Name == nil || string(*item.Name) == toName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveBackendRef returns the service which the backend ref of the route in the namespace refers
+// to, and the reason if it can't be resolved.
+func (c *controller) resolveBackendRef(kind config.GroupVersionKind, ref v1alpha2.BackendObjectReference,
+	namespace string) (common.ServiceKey, string) {
+	serviceKey, ok := toServiceKey(ref, namespace)
+	if !ok {
+		return common.ServiceKey{}, routeReasonInvalidKind
+	}
+	if !c.referenceAllowed(kind.Kind, namespace, serviceKind, serviceKey.Namespace, serviceKey.Name) {
+		return common.ServiceKey{}, routeReasonRefNotPermitted
+	}
+	return serviceKey, ""
+}
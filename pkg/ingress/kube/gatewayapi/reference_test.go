@@ -0,0 +1,206 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+)
+
+func newTestReferencePolicy(namespace string, fromKind string, fromNamespace string, toKind string, toName string) *v1alpha2.ReferencePolicy {
+	policy := &v1alpha2.ReferencePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-" + fromNamespace, Namespace: namespace},
+		Spec: v1alpha2.ReferencePolicySpec{
+			From: []v1alpha2.ReferencePolicyFrom{
+				{Group: gatewayGroup, Kind: v1alpha2.Kind(fromKind), Namespace: v1alpha2.Namespace(fromNamespace)},
+			},
+			To: []v1alpha2.ReferencePolicyTo{
+				{Kind: v1alpha2.Kind(toKind)},
+			},
+		},
+	}
+	if toName != "" {
+		name := v1alpha2.ObjectName(toName)
+		policy.Spec.To[0].Name = &name
+	}
+	return policy
+}
+
+func TestReferenceAllowed(t *testing.T) {
+	c := newTestController(t)
+	for _, policy := range []*v1alpha2.ReferencePolicy{
+		newTestReferencePolicy("backend", "HTTPRoute", "default", "Service", ""),
+		newTestReferencePolicy("certs", "Gateway", "default", "Secret", "foo-cert"),
+	} {
+		if err := c.referencePolicyInformer.GetStore().Add(policy); err != nil {
+			t.Fatalf("add reference policy error %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		fromKind string
+		from     string
+		toKind   string
+		to       string
+		toName   string
+		expect   bool
+	}{
+		{
+			name:     "same namespace",
+			fromKind: "HTTPRoute",
+			from:     "default",
+			toKind:   "Service",
+			to:       "default",
+			toName:   "foo",
+			expect:   true,
+		},
+		{
+			name:     "allowed service",
+			fromKind: "HTTPRoute",
+			from:     "default",
+			toKind:   "Service",
+			to:       "backend",
+			toName:   "foo",
+			expect:   true,
+		},
+		{
+			name:     "other route kind",
+			fromKind: "TCPRoute",
+			from:     "default",
+			toKind:   "Service",
+			to:       "backend",
+			toName:   "foo",
+		},
+		{
+			name:     "other from namespace",
+			fromKind: "HTTPRoute",
+			from:     "other",
+			toKind:   "Service",
+			to:       "backend",
+			toName:   "foo",
+		},
+		{
+			name:     "no policy",
+			fromKind: "HTTPRoute",
+			from:     "default",
+			toKind:   "Service",
+			to:       "other",
+			toName:   "foo",
+		},
+		{
+			name:     "allowed secret",
+			fromKind: "Gateway",
+			from:     "default",
+			toKind:   "Secret",
+			to:       "certs",
+			toName:   "foo-cert",
+			expect:   true,
+		},
+		{
+			name:     "other secret",
+			fromKind: "Gateway",
+			from:     "default",
+			toKind:   "Secret",
+			to:       "certs",
+			toName:   "bar-cert",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expect,
+				c.referenceAllowed(testCase.fromKind, testCase.from, testCase.toKind, testCase.to, testCase.toName))
+		})
+	}
+}
+
+func TestConvertCrossNamespaceReference(t *testing.T) {
+	c := newTestController(t)
+	port := v1alpha2.PortNumber(8080)
+	backend := v1alpha2.Namespace("backend")
+	certs := v1alpha2.Namespace("certs")
+	route := newTestRoute("route", v1alpha2.HTTPRouteSpec{
+		CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "")},
+		Hostnames:       []v1alpha2.Hostname{"a.foo.com"},
+		Rules: []v1alpha2.HTTPRouteRule{
+			{
+				Filters: []v1alpha2.HTTPRouteFilter{
+					{
+						Type: v1alpha2.HTTPRouteFilterRequestMirror,
+						RequestMirror: &v1alpha2.HTTPRequestMirrorFilter{
+							BackendRef: v1alpha2.BackendObjectReference{Name: "mirror", Namespace: &backend, Port: &port},
+						},
+					},
+				},
+				BackendRefs: []v1alpha2.HTTPBackendRef{
+					{BackendRef: v1alpha2.BackendRef{BackendObjectReference: v1alpha2.BackendObjectReference{Name: "foo", Namespace: &backend, Port: &port}}},
+				},
+			},
+		},
+	})
+	gateway, err := c.gatewayLister.Gateways("default").Get("gateway")
+	if err != nil {
+		t.Fatalf("get gateway error %v", err)
+	}
+	gateway = gateway.DeepCopy()
+	gateway.Spec.Listeners[1].TLS.CertificateRefs[0].Namespace = &certs
+	if err := c.gatewayInformer.GetStore().Update(gateway); err != nil {
+		t.Fatalf("update gateway error %v", err)
+	}
+
+	convert := func() *common.ConvertOptions {
+		convertOptions := &common.ConvertOptions{
+			IngressDomainCache:  common.NewIngressDomainCache(),
+			Gateways:            map[string]*common.WrapperGateway{},
+			HostAndPath2Ingress: map[string]*config.Config{},
+			IngressRouteCache:   common.NewIngressRouteCache(),
+			VirtualServices:     map[string]*common.WrapperVirtualService{},
+			HTTPRoutes:          map[string][]*common.WrapperHTTPRoute{},
+		}
+		assert.NoError(t, c.ConvertGateway(convertOptions, newTestWrapper(route)))
+		assert.NoError(t, c.ConvertHTTPRoute(convertOptions, newTestWrapper(route)))
+		return convertOptions
+	}
+
+	// The references across namespaces are denied without ReferencePolicy.
+	convertOptions := convert()
+	assert.Len(t, convertOptions.Gateways["a.foo.com"].Gateway.Servers, 1)
+	assert.Equal(t, uint32(80), convertOptions.Gateways["a.foo.com"].Gateway.Servers[0].Port.Number)
+	assert.Empty(t, convertOptions.HTTPRoutes["a.foo.com"])
+
+	for _, policy := range []*v1alpha2.ReferencePolicy{
+		newTestReferencePolicy("backend", "HTTPRoute", "default", "Service", ""),
+		newTestReferencePolicy("certs", "Gateway", "default", "Secret", ""),
+	} {
+		if err := c.referencePolicyInformer.GetStore().Add(policy); err != nil {
+			t.Fatalf("add reference policy error %v", err)
+		}
+	}
+	convertOptions = convert()
+	servers := convertOptions.Gateways["a.foo.com"].Gateway.Servers
+	assert.Len(t, servers, 2)
+	assert.Equal(t, "kubernetes-ingress://gw__/certs/foo-cert", servers[1].Tls.CredentialName)
+	routes := convertOptions.HTTPRoutes["a.foo.com"]
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "foo.backend.svc.cluster.local", routes[0].HTTPRoute.Route[0].Destination.Host)
+	assert.Equal(t, "mirror.backend.svc.cluster.local", routes[0].HTTPRoute.Mirror.Host)
+}
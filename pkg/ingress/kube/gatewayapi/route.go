@@ -0,0 +1,470 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"regexp"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+const (
+	gatewayGroup = "gateway.networking.k8s.io"
+	gatewayKind  = "Gateway"
+	serviceKind  = "Service"
+	secretKind   = "Secret"
+)
+
+// attachedListener is a listener of the gateway managed by higress which a route attaches to.
+type attachedListener struct {
+	gateway  *v1alpha2.Gateway
+	listener v1alpha2.Listener
+}
+
+func (l *attachedListener) hostname() string {
+	if l.listener.Hostname == nil {
+		return ""
+	}
+	return string(*l.listener.Hostname)
+}
+
+// certificate returns the namespace and name of the secret referred by the listener of the gateway,
+// and the reason if the reference can't be resolved.
+func (c *controller) certificate(gateway *v1alpha2.Gateway, listener v1alpha2.Listener) (string, string, v1alpha2.ListenerConditionReason) {
+	tls := listener.TLS
+	if tls == nil || len(tls.CertificateRefs) == 0 || tls.CertificateRefs[0] == nil {
+		return "", "", ""
+	}
+
+	ref := tls.CertificateRefs[0]
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != secretKind) {
+		return "", "", v1alpha2.ListenerReasonInvalidCertificateRef
+	}
+
+	namespace := gateway.Namespace
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		namespace = string(*ref.Namespace)
+	}
+	if !c.referenceAllowed(gatewayKind, gateway.Namespace, secretKind, namespace, string(ref.Name)) {
+		return "", "", v1alpha2.ListenerReasonRefNotPermitted
+	}
+	return namespace, string(ref.Name), ""
+}
+
+// attachedListeners returns the listeners of the gateways managed by higress which the parent refs
+// of the route in the namespace select, and which allow the route.
+func (c *controller) attachedListeners(kind config.GroupVersionKind, namespace string, parentRefs []v1alpha2.ParentRef) []*attachedListener {
+	var out []*attachedListener
+	for _, parentRef := range parentRefs {
+		gateway := c.parentGateway(namespace, parentRef)
+		if gateway == nil {
+			continue
+		}
+
+		for _, listener := range gateway.Spec.Listeners {
+			if parentRef.SectionName != nil && *parentRef.SectionName != listener.Name {
+				continue
+			}
+			if !acceptsRoute(listener, kind) || !c.allowsNamespace(gateway, listener, namespace) {
+				continue
+			}
+			out = append(out, &attachedListener{
+				gateway:  gateway,
+				listener: listener,
+			})
+		}
+	}
+	return out
+}
+
+// parentGateway returns the gateway managed by higress which the parent ref of the route in the
+// namespace refers to, or nil if there isn't one.
+func (c *controller) parentGateway(namespace string, parentRef v1alpha2.ParentRef) *v1alpha2.Gateway {
+	if parentRef.Group != nil && *parentRef.Group != gatewayGroup {
+		return nil
+	}
+	if parentRef.Kind != nil && *parentRef.Kind != gatewayKind {
+		return nil
+	}
+
+	gatewayNamespace := namespace
+	if parentRef.Namespace != nil && *parentRef.Namespace != "" {
+		gatewayNamespace = string(*parentRef.Namespace)
+	}
+	gateway, err := c.gatewayLister.Gateways(gatewayNamespace).Get(string(parentRef.Name))
+	if err != nil || !c.isManaged(gateway) {
+		return nil
+	}
+	return gateway
+}
+
+func (c *controller) isManaged(gateway *v1alpha2.Gateway) bool {
+	class, err := c.gatewayClassLister.Get(string(gateway.Spec.GatewayClassName))
+	if err != nil {
+		return false
+	}
+	return class.Spec.ControllerName == ControllerName
+}
+
+// acceptsRoute returns whether the kind of route is supported by the protocol of the listener, and
+// is allowed by the allowed routes of the listener. Only the passthrough mode of tls listener is
+// supported.
+func acceptsRoute(listener v1alpha2.Listener, kind config.GroupVersionKind) bool {
+	switch listener.Protocol {
+	case v1alpha2.HTTPProtocolType, v1alpha2.HTTPSProtocolType:
+		if kind != gvk.HTTPRoute {
+			return false
+		}
+	case v1alpha2.TLSProtocolType:
+		if kind != gvk.TLSRoute || listener.TLS == nil || listener.TLS.Mode == nil ||
+			*listener.TLS.Mode != v1alpha2.TLSModePassthrough {
+			return false
+		}
+	case v1alpha2.TCPProtocolType:
+		if kind != gvk.TCPRoute {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if listener.AllowedRoutes == nil || len(listener.AllowedRoutes.Kinds) == 0 {
+		return true
+	}
+	for _, routeKind := range listener.AllowedRoutes.Kinds {
+		group := gatewayGroup
+		if routeKind.Group != nil {
+			group = string(*routeKind.Group)
+		}
+		if group == kind.Group && string(routeKind.Kind) == kind.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsNamespace returns whether the routes in the namespace are allowed by the listener, only the
+// routes in the same namespace as the gateway are allowed by default.
+func (c *controller) allowsNamespace(gateway *v1alpha2.Gateway, listener v1alpha2.Listener, namespace string) bool {
+	from := v1alpha2.NamespacesFromSame
+	var namespaces *v1alpha2.RouteNamespaces
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil {
+		namespaces = listener.AllowedRoutes.Namespaces
+		if namespaces.From != nil {
+			from = *namespaces.From
+		}
+	}
+
+	switch from {
+	case v1alpha2.NamespacesFromAll:
+		return true
+	case v1alpha2.NamespacesFromSelector:
+		if namespaces.Selector == nil {
+			return false
+		}
+		selector, err := metav1.LabelSelectorAsSelector(namespaces.Selector)
+		if err != nil {
+			IngressLog.Errorf("invalid namespace selector of listener %s in gateway %s/%s, err %v",
+				listener.Name, gateway.Namespace, gateway.Name, err)
+			return false
+		}
+		ns, err := c.namespaceLister.Get(namespace)
+		if err != nil {
+			return false
+		}
+		return selector.Matches(labels.Set(ns.Labels))
+	default:
+		return namespace == gateway.Namespace
+	}
+}
+
+// intersectHostnames returns the hosts which match both the hostname of the listener and the
+// hostnames of the route, the more specific one is used if both match. It returns "*" if neither
+// specifies a hostname.
+func intersectHostnames(listenerHostname string, routeHostnames []v1alpha2.Hostname) []string {
+	if len(routeHostnames) == 0 {
+		if listenerHostname == "" {
+			return []string{"*"}
+		}
+		return []string{listenerHostname}
+	}
+
+	var out []string
+	for _, item := range routeHostnames {
+		routeHostname := string(item)
+		switch {
+		case listenerHostname == "" || listenerHostname == "*" || listenerHostname == routeHostname:
+			out = append(out, routeHostname)
+		case matchWildcard(listenerHostname, routeHostname):
+			out = append(out, routeHostname)
+		case matchWildcard(routeHostname, listenerHostname):
+			out = append(out, listenerHostname)
+		}
+	}
+	return out
+}
+
+// matchWildcard returns whether the host is covered by the wildcard hostname like *.example.com.
+func matchWildcard(wildcard, host string) bool {
+	if !strings.HasPrefix(wildcard, "*.") {
+		return false
+	}
+	return strings.HasSuffix(host, wildcard[1:]) && len(host) > len(wildcard)-1
+}
+
+// convertMatch converts the match of gateway api, and records the path match on the route.
+func convertMatch(route *common.WrapperHTTPRoute, match v1alpha2.HTTPRouteMatch) *networking.HTTPMatchRequest {
+	httpMatch := &networking.HTTPMatchRequest{}
+
+	matchType, value := v1alpha2.PathMatchPathPrefix, "/"
+	if match.Path != nil {
+		if match.Path.Type != nil {
+			matchType = *match.Path.Type
+		}
+		if match.Path.Value != nil {
+			value = *match.Path.Value
+		}
+	}
+	switch matchType {
+	case v1alpha2.PathMatchExact:
+		route.OriginPathType = common.Exact
+		httpMatch.Uri = &networking.StringMatch{
+			MatchType: &networking.StringMatch_Exact{Exact: value},
+		}
+	case v1alpha2.PathMatchRegularExpression:
+		route.OriginPathType = common.Regex
+		httpMatch.Uri = &networking.StringMatch{
+			MatchType: &networking.StringMatch_Regex{Regex: value},
+		}
+	default:
+		route.OriginPathType = common.Prefix
+		if value == "/" {
+			// Optimize common case of / to not needed regex
+			httpMatch.Uri = &networking.StringMatch{
+				MatchType: &networking.StringMatch_Prefix{Prefix: value},
+			}
+		} else {
+			value = strings.TrimSuffix(value, "/")
+			httpMatch.Uri = &networking.StringMatch{
+				MatchType: &networking.StringMatch_Regex{Regex: regexp.QuoteMeta(value) + common.PrefixMatchRegex},
+			}
+		}
+	}
+	route.OriginPath = value
+
+	for _, header := range match.Headers {
+		if httpMatch.Headers == nil {
+			httpMatch.Headers = map[string]*networking.StringMatch{}
+		}
+		regex := header.Type != nil && *header.Type == v1alpha2.HeaderMatchRegularExpression
+		httpMatch.Headers[strings.ToLower(string(header.Name))] = stringMatch(header.Value, regex)
+	}
+
+	for _, query := range match.QueryParams {
+		if httpMatch.QueryParams == nil {
+			httpMatch.QueryParams = map[string]*networking.StringMatch{}
+		}
+		regex := query.Type != nil && *query.Type == v1alpha2.QueryParamMatchRegularExpression
+		httpMatch.QueryParams[query.Name] = stringMatch(query.Value, regex)
+	}
+
+	if match.Method != nil {
+		httpMatch.Method = stringMatch(string(*match.Method), false)
+	}
+
+	return httpMatch
+}
+
+func stringMatch(value string, regex bool) *networking.StringMatch {
+	if regex {
+		return &networking.StringMatch{
+			MatchType: &networking.StringMatch_Regex{Regex: value},
+		}
+	}
+	return &networking.StringMatch{
+		MatchType: &networking.StringMatch_Exact{Exact: value},
+	}
+}
+
+func isPathOnlyMatch(match v1alpha2.HTTPRouteMatch) bool {
+	return len(match.Headers) == 0 && len(match.QueryParams) == 0 && match.Method == nil
+}
+
+// matchConditions returns the number of conditions besides path of the route.
+func matchConditions(route *networking.HTTPRoute) int {
+	var count int
+	for _, match := range route.Match {
+		count += len(match.Headers) + len(match.QueryParams)
+		if match.Method != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// applyFilters converts the filters of the rule on the route. The rewrite is not a filter in this
+// version of gateway api, so it is configured with the rewrite annotations on the http route.
+func (c *controller) applyFilters(route *networking.HTTPRoute, filters []v1alpha2.HTTPRouteFilter, namespace string) {
+	for _, filter := range filters {
+		switch filter.Type {
+		case v1alpha2.HTTPRouteFilterRequestHeaderModifier:
+			if filter.RequestHeaderModifier != nil {
+				applyRequestHeaderModifier(route, filter.RequestHeaderModifier)
+			}
+		case v1alpha2.HTTPRouteFilterRequestRedirect:
+			if filter.RequestRedirect != nil {
+				route.Redirect = convertRedirect(filter.RequestRedirect)
+			}
+		case v1alpha2.HTTPRouteFilterRequestMirror:
+			if filter.RequestMirror == nil {
+				continue
+			}
+			serviceKey, reason := c.resolveBackendRef(gvk.HTTPRoute, filter.RequestMirror.BackendRef, namespace)
+			if reason != "" {
+				IngressLog.Warnf("ignore the mirror backend %s of http route in namespace %s: %s",
+					filter.RequestMirror.BackendRef.Name, namespace, reason)
+				continue
+			}
+			route.Mirror = &networking.Destination{
+				Host: util.CreateServiceFQDN(serviceKey.Namespace, serviceKey.Name),
+				Port: &networking.PortSelector{
+					Number: uint32(serviceKey.Port),
+				},
+			}
+		default:
+			IngressLog.Warnf("unsupported filter type %s of http route in namespace %s", filter.Type, namespace)
+		}
+	}
+}
+
+func applyRequestHeaderModifier(route *networking.HTTPRoute, modifier *v1alpha2.HTTPRequestHeaderFilter) {
+	if route.Headers == nil {
+		route.Headers = &networking.Headers{}
+	}
+	if route.Headers.Request == nil {
+		route.Headers.Request = &networking.Headers_HeaderOperations{}
+	}
+
+	operations := route.Headers.Request
+	for _, header := range modifier.Set {
+		if operations.Set == nil {
+			operations.Set = map[string]string{}
+		}
+		operations.Set[string(header.Name)] = header.Value
+	}
+	for _, header := range modifier.Add {
+		if operations.Add == nil {
+			operations.Add = map[string]string{}
+		}
+		operations.Add[string(header.Name)] = header.Value
+	}
+	operations.Remove = append(operations.Remove, modifier.Remove...)
+}
+
+func convertRedirect(filter *v1alpha2.HTTPRequestRedirectFilter) *networking.HTTPRedirect {
+	redirect := &networking.HTTPRedirect{}
+	if filter.Scheme != nil {
+		redirect.Scheme = *filter.Scheme
+	}
+	if filter.Hostname != nil {
+		redirect.Authority = string(*filter.Hostname)
+	}
+	if filter.Port != nil {
+		redirect.RedirectPort = &networking.HTTPRedirect_Port{Port: uint32(*filter.Port)}
+	}
+	if filter.StatusCode != nil {
+		redirect.RedirectCode = uint32(*filter.StatusCode)
+	}
+	return redirect
+}
+
+// toServiceKey returns the service which the backend object reference refers to. Only services
+// with explicit port are supported.
+func toServiceKey(ref v1alpha2.BackendObjectReference, namespace string) (common.ServiceKey, bool) {
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != serviceKind) {
+		return common.ServiceKey{}, false
+	}
+	if ref.Name == "" || ref.Port == nil {
+		return common.ServiceKey{}, false
+	}
+
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		namespace = string(*ref.Namespace)
+	}
+	return common.ServiceKey{
+		Namespace: namespace,
+		Name:      string(ref.Name),
+		Port:      int32(*ref.Port),
+	}, true
+}
+
+// backendRefsToRouteDestination converts the backend refs to weighted destinations, and returns
+// the total weight of them.
+func (c *controller) backendRefsToRouteDestination(backendRefs []v1alpha2.HTTPBackendRef, namespace string,
+	builder *common.IngressRouteBuilder) ([]*networking.HTTPRouteDestination, int32, common.Event) {
+	var destinations []*networking.HTTPRouteDestination
+	var serviceList []model.BackendService
+	var weightTotal int32
+	for _, backendRef := range backendRefs {
+		weight := int32(1)
+		if backendRef.Weight != nil {
+			weight = *backendRef.Weight
+		}
+		// The backend with zero weight receives no traffic.
+		if weight == 0 {
+			continue
+		}
+
+		serviceKey, reason := c.resolveBackendRef(gvk.HTTPRoute, backendRef.BackendObjectReference, namespace)
+		if reason != "" {
+			return nil, 0, common.InvalidBackendService
+		}
+
+		destinations = append(destinations, &networking.HTTPRouteDestination{
+			Destination: &networking.Destination{
+				Host: util.CreateServiceFQDN(serviceKey.Namespace, serviceKey.Name),
+				Port: &networking.PortSelector{
+					Number: uint32(serviceKey.Port),
+				},
+			},
+			Weight: weight,
+		})
+		serviceList = append(serviceList, model.BackendService{
+			Namespace: serviceKey.Namespace,
+			Name:      serviceKey.Name,
+			Port:      uint32(serviceKey.Port),
+			Weight:    weight,
+		})
+		weightTotal += weight
+	}
+
+	if len(destinations) == 0 {
+		return nil, 0, common.InvalidBackendService
+	}
+
+	builder.ServiceList = serviceList
+	return destinations, weightTotal, common.Normal
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayclient "sigs.k8s.io/gateway-api/pkg/client/clientset/gateway/versioned"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+const (
+	routeReasonAccepted              = "Accepted"
+	routeReasonNotAllowedByListeners = "NotAllowedByListeners"
+)
+
+// statusSyncer keeps the listener status of the gateways managed by higress, and the parent status
+// of the http routes attached to them updated.
+type statusSyncer struct {
+	client     gatewayclient.Interface
+	controller *controller
+}
+
+func newStatusSyncer(client gatewayclient.Interface, controller *controller) *statusSyncer {
+	return &statusSyncer{
+		client:     client,
+		controller: controller,
+	}
+}
+
+func (s *statusSyncer) run(stopCh <-chan struct{}) {
+	cache.WaitForCacheSync(stopCh, s.controller.HasSynced)
+
+	ticker := time.NewTicker(common.DefaultStatusUpdateInterval)
+	for {
+		select {
+		case <-stopCh:
+			ticker.Stop()
+			return
+		case <-ticker.C:
+			if err := s.runUpdateStatus(); err != nil {
+				IngressLog.Errorf("update gateway api status task fail, err %v", err)
+			}
+		}
+	}
+}
+
+func (s *statusSyncer) runUpdateStatus() error {
+	gateways, err := s.controller.gatewayLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	attachedRoutes := s.controller.attachedRoutes()
+	for _, gateway := range gateways {
+		if !s.controller.isManaged(gateway) {
+			continue
+		}
+		listeners := s.controller.listenerStatuses(gateway, attachedRoutes)
+		if reflect.DeepEqual(listeners, gateway.Status.Listeners) {
+			continue
+		}
+
+		gateway = gateway.DeepCopy()
+		gateway.Status.Listeners = listeners
+		IngressLog.Infof("Update gateway %s/%s within cluster %s status", gateway.Namespace, gateway.Name, s.controller.options.ClusterId)
+		_, err = s.client.GatewayV1alpha2().Gateways(gateway.Namespace).UpdateStatus(context.TODO(), gateway, metav1.UpdateOptions{})
+		if err != nil {
+			IngressLog.Warnf("error updating gateway %s/%s within cluster %s status: %v",
+				gateway.Namespace, gateway.Name, s.controller.options.ClusterId, err)
+		}
+	}
+
+	for _, raw := range s.controller.httpRouteInformer.GetStore().List() {
+		route, ok := raw.(*v1alpha2.HTTPRoute)
+		if !ok || !s.controller.namespaceFilter.Watched(route.Namespace) {
+			continue
+		}
+		parents := s.controller.routeParentStatuses(route)
+		if reflect.DeepEqual(parents, route.Status.Parents) {
+			continue
+		}
+
+		route = route.DeepCopy()
+		route.Status.Parents = parents
+		IngressLog.Infof("Update http route %s/%s within cluster %s status", route.Namespace, route.Name, s.controller.options.ClusterId)
+		_, err = s.client.GatewayV1alpha2().HTTPRoutes(route.Namespace).UpdateStatus(context.TODO(), route, metav1.UpdateOptions{})
+		if err != nil {
+			IngressLog.Warnf("error updating http route %s/%s within cluster %s status: %v",
+				route.Namespace, route.Name, s.controller.options.ClusterId, err)
+		}
+	}
+
+	return nil
+}
+
+type listenerKey struct {
+	namespace string
+	gateway   string
+	listener  v1alpha2.SectionName
+}
+
+// attachedRoutes returns the number of routes attached to each listener.
+func (c *controller) attachedRoutes() map[listenerKey]int32 {
+	out := map[listenerKey]int32{}
+	count := func(kind config.GroupVersionKind, namespace string, parentRefs []v1alpha2.ParentRef) {
+		if !c.namespaceFilter.Watched(namespace) {
+			return
+		}
+		seen := map[listenerKey]bool{}
+		for _, l := range c.attachedListeners(kind, namespace, parentRefs) {
+			key := listenerKey{namespace: l.gateway.Namespace, gateway: l.gateway.Name, listener: l.listener.Name}
+			if !seen[key] {
+				seen[key] = true
+				out[key]++
+			}
+		}
+	}
+	for _, raw := range c.httpRouteInformer.GetStore().List() {
+		if route, ok := raw.(*v1alpha2.HTTPRoute); ok {
+			count(gvk.HTTPRoute, route.Namespace, route.Spec.ParentRefs)
+		}
+	}
+	for _, raw := range c.tlsRouteInformer.GetStore().List() {
+		if route, ok := raw.(*v1alpha2.TLSRoute); ok {
+			count(gvk.TLSRoute, route.Namespace, route.Spec.ParentRefs)
+		}
+	}
+	for _, raw := range c.tcpRouteInformer.GetStore().List() {
+		if route, ok := raw.(*v1alpha2.TCPRoute); ok {
+			count(gvk.TCPRoute, route.Namespace, route.Spec.ParentRefs)
+		}
+	}
+	return out
+}
+
+// listenerStatuses returns the status of the listeners of the gateway, with the ResolvedRefs condition
+// reporting whether the certificate ref is resolved.
+func (c *controller) listenerStatuses(gateway *v1alpha2.Gateway, attachedRoutes map[listenerKey]int32) []v1alpha2.ListenerStatus {
+	previous := map[v1alpha2.SectionName][]metav1.Condition{}
+	for _, status := range gateway.Status.Listeners {
+		previous[status.Name] = status.Conditions
+	}
+
+	var out []v1alpha2.ListenerStatus
+	for _, listener := range gateway.Spec.Listeners {
+		condition := metav1.Condition{
+			Type:               string(v1alpha2.ListenerConditionResolvedRefs),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(v1alpha2.ListenerReasonResolvedRefs),
+			Message:            "All references are resolved",
+			ObservedGeneration: gateway.Generation,
+		}
+		if _, _, reason := c.certificate(gateway, listener); reason != "" {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = string(reason)
+			if reason == v1alpha2.ListenerReasonRefNotPermitted {
+				condition.Message = "The certificate ref to another namespace is not permitted by any ReferencePolicy"
+			} else {
+				condition.Message = "Only the certificate ref to a Secret is supported"
+			}
+		}
+
+		out = append(out, v1alpha2.ListenerStatus{
+			Name:           listener.Name,
+			SupportedKinds: supportedKinds(listener),
+			AttachedRoutes: attachedRoutes[listenerKey{namespace: gateway.Namespace, gateway: gateway.Name, listener: listener.Name}],
+			Conditions:     setConditions(previous[listener.Name], condition),
+		})
+	}
+	return out
+}
+
+func supportedKinds(listener v1alpha2.Listener) []v1alpha2.RouteGroupKind {
+	group := v1alpha2.Group(gatewayGroup)
+	var kind config.GroupVersionKind
+	switch listener.Protocol {
+	case v1alpha2.HTTPProtocolType, v1alpha2.HTTPSProtocolType:
+		kind = gvk.HTTPRoute
+	case v1alpha2.TLSProtocolType:
+		kind = gvk.TLSRoute
+	case v1alpha2.TCPProtocolType:
+		kind = gvk.TCPRoute
+	default:
+		return []v1alpha2.RouteGroupKind{}
+	}
+	return []v1alpha2.RouteGroupKind{{Group: &group, Kind: v1alpha2.Kind(kind.Kind)}}
+}
+
+// routeParentStatuses returns the status of the http route for the parents managed by higress, with
+// the Accepted and ResolvedRefs conditions, the status written by other controllers is kept.
+func (c *controller) routeParentStatuses(route *v1alpha2.HTTPRoute) []v1alpha2.RouteParentStatus {
+	var out []v1alpha2.RouteParentStatus
+	for _, parent := range route.Status.Parents {
+		if parent.ControllerName != ControllerName {
+			out = append(out, parent)
+		}
+	}
+
+	resolved := metav1.Condition{
+		Type:               string(v1alpha2.ConditionRouteResolvedRefs),
+		Status:             metav1.ConditionTrue,
+		Reason:             routeReasonResolvedRefs,
+		Message:            "All references are resolved",
+		ObservedGeneration: route.Generation,
+	}
+	if reason, message := c.httpRouteRefsReason(route.Namespace, route.Spec); reason != "" {
+		resolved.Status = metav1.ConditionFalse
+		resolved.Reason = reason
+		resolved.Message = message
+	}
+
+	for _, parentRef := range route.Spec.ParentRefs {
+		if c.parentGateway(route.Namespace, parentRef) == nil {
+			continue
+		}
+
+		var previous []metav1.Condition
+		for _, parent := range route.Status.Parents {
+			if parent.ControllerName == ControllerName && reflect.DeepEqual(parent.ParentRef, parentRef) {
+				previous = parent.Conditions
+			}
+		}
+
+		accepted := metav1.Condition{
+			Type:               string(v1alpha2.ConditionRouteAccepted),
+			Status:             metav1.ConditionTrue,
+			Reason:             routeReasonAccepted,
+			Message:            "The route is accepted",
+			ObservedGeneration: route.Generation,
+		}
+		if len(c.attachedListeners(gvk.HTTPRoute, route.Namespace, []v1alpha2.ParentRef{parentRef})) == 0 {
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = routeReasonNotAllowedByListeners
+			accepted.Message = "No listener of the gateway allows the route"
+		}
+
+		out = append(out, v1alpha2.RouteParentStatus{
+			ParentRef:      parentRef,
+			ControllerName: ControllerName,
+			Conditions:     setConditions(previous, accepted, resolved),
+		})
+	}
+	return out
+}
+
+// httpRouteRefsReason returns the reason and message if any backend ref of the http route, including
+// the one of mirror filter, can't be resolved.
+func (c *controller) httpRouteRefsReason(namespace string, route v1alpha2.HTTPRouteSpec) (string, string) {
+	var refs []v1alpha2.BackendObjectReference
+	for _, rule := range route.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			refs = append(refs, backendRef.BackendObjectReference)
+		}
+		for _, filter := range rule.Filters {
+			if filter.Type == v1alpha2.HTTPRouteFilterRequestMirror && filter.RequestMirror != nil {
+				refs = append(refs, filter.RequestMirror.BackendRef)
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		switch _, reason := c.resolveBackendRef(gvk.HTTPRoute, ref, namespace); reason {
+		case routeReasonRefNotPermitted:
+			return reason, fmt.Sprintf("The backend ref to %s in another namespace is not permitted by any ReferencePolicy", ref.Name)
+		case routeReasonInvalidKind:
+			return reason, fmt.Sprintf("The backend ref to %s should be a Service with port", ref.Name)
+		}
+	}
+	return "", ""
+}
+
+// setConditions sets the conditions on a copy of the previous ones, the transition time is kept if
+// the status of a condition doesn't change.
+func setConditions(previous []metav1.Condition, conditions ...metav1.Condition) []metav1.Condition {
+	out := append([]metav1.Condition(nil), previous...)
+	for _, condition := range conditions {
+		meta.SetStatusCondition(&out, condition)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestListenerStatuses(t *testing.T) {
+	c := newTestController(t)
+	route := newTestRoute("route", v1alpha2.HTTPRouteSpec{
+		CommonRouteSpec: v1alpha2.CommonRouteSpec{ParentRefs: parentRefs("gateway", "")},
+	})
+	if err := c.httpRouteInformer.GetStore().Add(route); err != nil {
+		t.Fatalf("add route error %v", err)
+	}
+
+	gateway, err := c.gatewayLister.Gateways("default").Get("gateway")
+	if err != nil {
+		t.Fatalf("get gateway error %v", err)
+	}
+	gateway = gateway.DeepCopy()
+	certs := v1alpha2.Namespace("certs")
+	gateway.Spec.Listeners[1].TLS.CertificateRefs[0].Namespace = &certs
+
+	statuses := c.listenerStatuses(gateway, c.attachedRoutes())
+	assert.Len(t, statuses, 4)
+	assert.Equal(t, v1alpha2.SectionName("http"), statuses[0].Name)
+	assert.Equal(t, int32(1), statuses[0].AttachedRoutes)
+	assert.Equal(t, v1alpha2.Kind("HTTPRoute"), statuses[0].SupportedKinds[0].Kind)
+	assert.Equal(t, metav1.ConditionTrue, statuses[0].Conditions[0].Status)
+
+	assert.Equal(t, v1alpha2.SectionName("https"), statuses[1].Name)
+	assert.Equal(t, int32(1), statuses[1].AttachedRoutes)
+	assert.Equal(t, metav1.ConditionFalse, statuses[1].Conditions[0].Status)
+	assert.Equal(t, string(v1alpha2.ListenerReasonRefNotPermitted), statuses[1].Conditions[0].Reason)
+
+	assert.Equal(t, int32(0), statuses[2].AttachedRoutes)
+	assert.Equal(t, v1alpha2.Kind("TCPRoute"), statuses[2].SupportedKinds[0].Kind)
+
+	// The transition time is kept if the status is unchanged.
+	gateway.Status.Listeners = statuses
+	assert.Equal(t, statuses, c.listenerStatuses(gateway, c.attachedRoutes()))
+}
+
+func TestRouteParentStatuses(t *testing.T) {
+	c := newTestController(t)
+	port := v1alpha2.PortNumber(8080)
+	backend := v1alpha2.Namespace("backend")
+	otherController := v1alpha2.RouteParentStatus{
+		ParentRef:      v1alpha2.ParentRef{Name: "other"},
+		ControllerName: "example.com/gateway-controller",
+	}
+	route := newTestRoute("route", v1alpha2.HTTPRouteSpec{
+		CommonRouteSpec: v1alpha2.CommonRouteSpec{
+			ParentRefs: append(append(parentRefs("gateway", ""), parentRefs("gateway", "tcp")...), parentRefs("other", "")...),
+		},
+		Rules: []v1alpha2.HTTPRouteRule{
+			{
+				BackendRefs: []v1alpha2.HTTPBackendRef{
+					{BackendRef: v1alpha2.BackendRef{BackendObjectReference: v1alpha2.BackendObjectReference{Name: "foo", Namespace: &backend, Port: &port}}},
+				},
+			},
+		},
+	})
+	route.Status.Parents = []v1alpha2.RouteParentStatus{otherController}
+
+	parents := c.routeParentStatuses(route)
+	// The parent of other controller is kept, and the one not managed by higress is skipped.
+	assert.Len(t, parents, 3)
+	assert.Equal(t, otherController, parents[0])
+
+	conditions := parents[1].Conditions
+	assert.Equal(t, ControllerName, string(parents[1].ControllerName))
+	assert.Equal(t, string(v1alpha2.ConditionRouteAccepted), conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, conditions[0].Status)
+	assert.Equal(t, string(v1alpha2.ConditionRouteResolvedRefs), conditions[1].Type)
+	assert.Equal(t, metav1.ConditionFalse, conditions[1].Status)
+	assert.Equal(t, routeReasonRefNotPermitted, conditions[1].Reason)
+
+	// The tcp listener doesn't accept http routes.
+	conditions = parents[2].Conditions
+	assert.Equal(t, metav1.ConditionFalse, conditions[0].Status)
+	assert.Equal(t, routeReasonNotAllowedByListeners, conditions[0].Reason)
+
+	if err := c.referencePolicyInformer.GetStore().Add(newTestReferencePolicy("backend", "HTTPRoute", "default", "Service", "")); err != nil {
+		t.Fatalf("add reference policy error %v", err)
+	}
+	route.Status.Parents = parents
+	parents = c.routeParentStatuses(route)
+	assert.Equal(t, metav1.ConditionTrue, parents[1].Conditions[1].Status)
+	assert.Equal(t, routeReasonResolvedRefs, parents[1].Conditions[1].Reason)
+}
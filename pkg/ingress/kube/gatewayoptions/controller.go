@@ -0,0 +1,86 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayoptions
+
+import (
+	kubeclient "istio.io/istio/pkg/kube"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/configmap"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// Controller watches the ConfigMap of gateway options.
+type Controller interface {
+	AddEventHandler(func())
+
+	Run(stop <-chan struct{})
+
+	HasSynced() bool
+
+	// Options returns nil if the ConfigMap doesn't exist or is invalid.
+	Options() *Options
+}
+
+type controller struct {
+	configMapController configmap.Controller
+	name                types.NamespacedName
+}
+
+// NewController returns nil when the gateway options ConfigMap is not specified.
+func NewController(client kubeclient.Client, options common.Options) Controller {
+	if options.GatewayOptionsConfigMap == "" {
+		return nil
+	}
+
+	name := configmap.ParseName(options.GatewayOptionsConfigMap, options.SystemNamespace)
+	return &controller{
+		configMapController: configmap.NewController(client, name),
+		name:                name,
+	}
+}
+
+func (c *controller) AddEventHandler(f func()) {
+	c.configMapController.AddEventHandler(func(types.NamespacedName) {
+		f()
+	})
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	c.configMapController.Run(stop)
+}
+
+func (c *controller) HasSynced() bool {
+	return c.configMapController.HasSynced()
+}
+
+func (c *controller) Options() *Options {
+	configMap, err := c.configMapController.Get(c.name)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			IngressLog.Errorf("Get gateway options configmap %s error %v", c.name, err)
+		}
+		return nil
+	}
+
+	options, err := Parse(configMap.Data)
+	if err != nil {
+		IngressLog.Errorf("Parse gateway options configmap %s error %v", c.name, err)
+		return nil
+	}
+	return options
+}
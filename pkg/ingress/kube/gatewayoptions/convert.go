@@ -0,0 +1,182 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayoptions
+
+import (
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	proxyprotocol "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/proxy_protocol/v3"
+	httppb "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	networking "istio.io/api/networking/v1alpha3"
+	networkingutil "istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema/gvk"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
+)
+
+// ResourceName is the name of the converted envoy filter.
+var ResourceName = common.CreateConvertedName(constants.IstioIngressGatewayName, "gateway-options")
+
+// BuildProxyProtocolPatch builds the patch which adds the proxy protocol listener filter,
+// the patch applies to all gateway listeners if port is 0.
+func BuildProxyProtocolPatch(port uint32) (*networking.EnvoyFilter_EnvoyConfigObjectPatch, error) {
+	value, err := util.MessageToGoGoStruct(&listenerv3.Listener{
+		ListenerFilters: []*listenerv3.ListenerFilter{
+			{
+				Name: wellknown.ProxyProtocol,
+				ConfigType: &listenerv3.ListenerFilter_TypedConfig{
+					TypedConfig: networkingutil.MessageToAny(&proxyprotocol.ProxyProtocol{}),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: networking.EnvoyFilter_LISTENER,
+		Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+			Context: networking.EnvoyFilter_GATEWAY,
+			ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+				Listener: &networking.EnvoyFilter_ListenerMatch{
+					PortNumber: port,
+				},
+			},
+		},
+		Patch: &networking.EnvoyFilter_Patch{
+			// Merge appends the listener filter after tls_inspector, which can't inspect the
+			// connection starting with the PROXY header.
+			Operation: networking.EnvoyFilter_Patch_INSERT_FIRST,
+			Value:     value,
+		},
+	}, nil
+}
+
+// buildXffNumTrustedHopsPatch builds the patch which sets xff_num_trusted_hops of http connection manager,
+// the patch applies to all gateway listeners if port is 0.
+func buildXffNumTrustedHopsPatch(port, hops uint32) (*networking.EnvoyFilter_EnvoyConfigObjectPatch, error) {
+	value, err := util.MessageToGoGoStruct(&listenerv3.Filter{
+		Name: wellknown.HTTPConnectionManager,
+		ConfigType: &listenerv3.Filter_TypedConfig{
+			TypedConfig: networkingutil.MessageToAny(&httppb.HttpConnectionManager{
+				XffNumTrustedHops: hops,
+			}),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: networking.EnvoyFilter_NETWORK_FILTER,
+		Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+			Context: networking.EnvoyFilter_GATEWAY,
+			ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+				Listener: &networking.EnvoyFilter_ListenerMatch{
+					PortNumber: port,
+					FilterChain: &networking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+						Filter: &networking.EnvoyFilter_ListenerMatch_FilterMatch{
+							Name: wellknown.HTTPConnectionManager,
+						},
+					},
+				},
+			},
+		},
+		Patch: &networking.EnvoyFilter_Patch{
+			Operation: networking.EnvoyFilter_Patch_MERGE,
+			Value:     value,
+		},
+	}, nil
+}
+
+// ConvertEnvoyFilter converts the options to an envoy filter, the patches of port are
+// placed after the global ones to override them.
+// The ports are the listener ports of gateways. As merging a zero xff_num_trusted_hops
+// takes no effect, the global one is applied to the ports without their own instead of
+// all listeners once any port overrides it.
+func ConvertEnvoyFilter(options *Options, ports []uint32, namespace string) (*config.Config, error) {
+	if options == nil {
+		return nil, nil
+	}
+
+	var configPatches []*networking.EnvoyFilter_EnvoyConfigObjectPatch
+	if options.UseProxyProtocol {
+		patch, err := BuildProxyProtocolPatch(0)
+		if err != nil {
+			return nil, err
+		}
+		configPatches = append(configPatches, patch)
+	}
+	if options.XffNumTrustedHops != nil {
+		overridden := map[uint32]bool{}
+		for _, portOptions := range options.Ports {
+			if portOptions.XffNumTrustedHops != nil {
+				overridden[portOptions.Port] = true
+			}
+		}
+		globalPorts := []uint32{0}
+		if len(overridden) > 0 {
+			globalPorts = nil
+			for _, port := range ports {
+				if !overridden[port] {
+					globalPorts = append(globalPorts, port)
+				}
+			}
+		}
+		for _, port := range globalPorts {
+			patch, err := buildXffNumTrustedHopsPatch(port, *options.XffNumTrustedHops)
+			if err != nil {
+				return nil, err
+			}
+			configPatches = append(configPatches, patch)
+		}
+	}
+
+	for _, portOptions := range options.Ports {
+		if portOptions.UseProxyProtocol && !options.UseProxyProtocol {
+			patch, err := BuildProxyProtocolPatch(portOptions.Port)
+			if err != nil {
+				return nil, err
+			}
+			configPatches = append(configPatches, patch)
+		}
+		if portOptions.XffNumTrustedHops != nil {
+			patch, err := buildXffNumTrustedHopsPatch(portOptions.Port, *portOptions.XffNumTrustedHops)
+			if err != nil {
+				return nil, err
+			}
+			configPatches = append(configPatches, patch)
+		}
+	}
+
+	if len(configPatches) == 0 {
+		return nil, nil
+	}
+
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.EnvoyFilter,
+			Name:             ResourceName,
+			Namespace:        namespace,
+		},
+		Spec: &networking.EnvoyFilter{
+			ConfigPatches: configPatches,
+		},
+	}, nil
+}
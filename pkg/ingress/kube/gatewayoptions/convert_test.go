@@ -0,0 +1,158 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayoptions
+
+import (
+	"testing"
+
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+func TestConvertEnvoyFilter(t *testing.T) {
+	envoyFilter, err := ConvertEnvoyFilter(nil, nil, "higress-system")
+	assert.NoError(t, err)
+	assert.Nil(t, envoyFilter)
+
+	envoyFilter, err = ConvertEnvoyFilter(&Options{}, nil, "higress-system")
+	assert.NoError(t, err)
+	assert.Nil(t, envoyFilter)
+
+	envoyFilter, err = ConvertEnvoyFilter(&Options{
+		XffNumTrustedHops: uint32Ptr(1),
+		Ports: []PortOptions{
+			{Port: 8443, UseProxyProtocol: true, XffNumTrustedHops: uint32Ptr(2)},
+		},
+	}, []uint32{80, 443, 8443}, "higress-system")
+	assert.NoError(t, err)
+	assert.Equal(t, ResourceName, envoyFilter.Name)
+
+	patches := envoyFilter.Spec.(*networking.EnvoyFilter).ConfigPatches
+	assert.Len(t, patches, 4)
+
+	// The global patch applies to the ports without their own.
+	for idx, port := range []uint32{80, 443} {
+		assert.Equal(t, networking.EnvoyFilter_NETWORK_FILTER, patches[idx].ApplyTo)
+		assert.Equal(t, port, patches[idx].Match.GetListener().PortNumber)
+		assert.Equal(t, "envoy.filters.network.http_connection_manager",
+			patches[idx].Match.GetListener().FilterChain.Filter.Name)
+		assert.Equal(t, float64(1),
+			patches[idx].Patch.Value.Fields["typed_config"].GetStructValue().Fields["xff_num_trusted_hops"].GetNumberValue())
+	}
+
+	assert.Equal(t, networking.EnvoyFilter_LISTENER, patches[2].ApplyTo)
+	assert.Equal(t, networking.EnvoyFilter_Patch_INSERT_FIRST, patches[2].Patch.Operation)
+	assert.Equal(t, uint32(8443), patches[2].Match.GetListener().PortNumber)
+
+	assert.Equal(t, networking.EnvoyFilter_NETWORK_FILTER, patches[3].ApplyTo)
+	assert.Equal(t, uint32(8443), patches[3].Match.GetListener().PortNumber)
+	assert.Equal(t, float64(2),
+		patches[3].Patch.Value.Fields["typed_config"].GetStructValue().Fields["xff_num_trusted_hops"].GetNumberValue())
+}
+
+func TestConvertEnvoyFilterWithGlobalXffNumTrustedHops(t *testing.T) {
+	// Without the port overrides, the global patch applies to all listeners.
+	envoyFilter, err := ConvertEnvoyFilter(&Options{
+		XffNumTrustedHops: uint32Ptr(1),
+	}, []uint32{80, 443}, "higress-system")
+	assert.NoError(t, err)
+	patches := envoyFilter.Spec.(*networking.EnvoyFilter).ConfigPatches
+	assert.Len(t, patches, 1)
+	assert.Equal(t, uint32(0), patches[0].Match.GetListener().PortNumber)
+
+	// The zero of port can't be merged, so the global one must not apply to the port.
+	envoyFilter, err = ConvertEnvoyFilter(&Options{
+		XffNumTrustedHops: uint32Ptr(1),
+		Ports: []PortOptions{
+			{Port: 443, XffNumTrustedHops: uint32Ptr(0)},
+		},
+	}, []uint32{80, 443}, "higress-system")
+	assert.NoError(t, err)
+	patches = envoyFilter.Spec.(*networking.EnvoyFilter).ConfigPatches
+	assert.Len(t, patches, 2)
+	assert.Equal(t, uint32(80), patches[0].Match.GetListener().PortNumber)
+	assert.Equal(t, float64(1),
+		patches[0].Patch.Value.Fields["typed_config"].GetStructValue().Fields["xff_num_trusted_hops"].GetNumberValue())
+	assert.Equal(t, uint32(443), patches[1].Match.GetListener().PortNumber)
+}
+
+func TestProxyProtocolBeforeTLSInspector(t *testing.T) {
+	envoyFilter, err := ConvertEnvoyFilter(&Options{UseProxyProtocol: true}, nil, "higress-system")
+	assert.NoError(t, err)
+
+	gateway := config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.Gateway,
+			Name:             "foo",
+			Namespace:        "higress-system",
+		},
+		Spec: &networking.Gateway{
+			Servers: []*networking.Server{
+				{
+					Port: &networking.Port{
+						Number:   443,
+						Protocol: "HTTPS",
+						Name:     "https-443",
+					},
+					Hosts: []string{"foo.com"},
+					Tls: &networking.ServerTLSSettings{
+						Mode:              networking.ServerTLSSettings_SIMPLE,
+						ServerCertificate: "/etc/certs/foo.pem",
+						PrivateKey:        "/etc/certs/foo.key",
+					},
+				},
+			},
+		},
+	}
+	cg := v1alpha3.NewConfigGenTest(t, v1alpha3.TestOptions{
+		Configs: []config.Config{gateway, *envoyFilter},
+	})
+	proxy := cg.SetupProxy(&model.Proxy{
+		Type:            model.Router,
+		ConfigNamespace: "higress-system",
+	})
+
+	var names []string
+	for _, listener := range cg.Listeners(proxy) {
+		if listener.Address.GetSocketAddress().GetPortValue() != 443 {
+			continue
+		}
+		for _, filter := range listener.ListenerFilters {
+			names = append(names, filter.Name)
+		}
+	}
+	assert.Equal(t, []string{wellknown.ProxyProtocol, wellknown.TlsInspector}, names)
+}
+
+func TestConvertEnvoyFilterWithGlobalProxyProtocol(t *testing.T) {
+	envoyFilter, err := ConvertEnvoyFilter(&Options{
+		UseProxyProtocol: true,
+		Ports: []PortOptions{
+			{Port: 8443, UseProxyProtocol: true},
+		},
+	}, nil, "higress-system")
+	assert.NoError(t, err)
+
+	// The listener filter shouldn't be added twice.
+	patches := envoyFilter.Spec.(*networking.EnvoyFilter).ConfigPatches
+	assert.Len(t, patches, 1)
+	assert.Equal(t, networking.EnvoyFilter_LISTENER, patches[0].ApplyTo)
+	assert.Equal(t, uint32(0), patches[0].Match.GetListener().PortNumber)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayoptions
+
+import (
+	"fmt"
+	"strconv"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	useProxyProtocolKey  = "use-proxy-protocol"
+	xffNumTrustedHopsKey = "xff-num-trusted-hops"
+	portsKey             = "ports"
+)
+
+// Options are the listener options of gateway, which are read from the ConfigMap like:
+//
+//	use-proxy-protocol: "true"
+//	xff-num-trusted-hops: "1"
+//	ports: |
+//	  - port: 8443
+//	    xff-num-trusted-hops: 2
+//
+// The options of port take precedence over the global ones.
+type Options struct {
+	// UseProxyProtocol accepts the PROXY protocol header from downstream on all tcp listeners.
+	UseProxyProtocol bool
+
+	// XffNumTrustedHops is the number of trusted proxies in front of gateway, the real client ip
+	// is resolved from X-Forwarded-For by skipping them.
+	XffNumTrustedHops *uint32
+
+	Ports []PortOptions
+}
+
+type PortOptions struct {
+	Port uint32 `json:"port"`
+
+	// UseProxyProtocol can only enable the PROXY protocol for the port, because
+	// it can't be disabled for a single port once it's enabled globally.
+	UseProxyProtocol bool `json:"use-proxy-protocol,omitempty"`
+
+	XffNumTrustedHops *uint32 `json:"xff-num-trusted-hops,omitempty"`
+}
+
+// Parse parses the data of ConfigMap.
+func Parse(data map[string]string) (*Options, error) {
+	options := &Options{}
+	if raw, exist := data[useProxyProtocolKey]; exist {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", useProxyProtocolKey, raw, err)
+		}
+		options.UseProxyProtocol = value
+	}
+
+	if raw, exist := data[xffNumTrustedHopsKey]; exist {
+		value, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", xffNumTrustedHopsKey, raw, err)
+		}
+		hops := uint32(value)
+		options.XffNumTrustedHops = &hops
+	}
+
+	if raw, exist := data[portsKey]; exist {
+		if err := yaml.UnmarshalStrict([]byte(raw), &options.Ports); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", portsKey, err)
+		}
+		for _, port := range options.Ports {
+			if port.Port == 0 || port.Port > 65535 {
+				return nil, fmt.Errorf("invalid %s: port %d is out of range", portsKey, port.Port)
+			}
+		}
+	}
+
+	return options, nil
+}
+
+// ProxyProtocolEnabled reports whether the listener of port accepts the PROXY protocol.
+func (o *Options) ProxyProtocolEnabled(port uint32) bool {
+	if o == nil {
+		return false
+	}
+	if o.UseProxyProtocol {
+		return true
+	}
+	for _, portOptions := range o.Ports {
+		if portOptions.Port == port && portOptions.UseProxyProtocol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayoptions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uint32Ptr(value uint32) *uint32 {
+	return &value
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  map[string]string
+		expect *Options
+		err    bool
+	}{
+		{
+			name:   "empty",
+			expect: &Options{},
+		},
+		{
+			name: "global",
+			input: map[string]string{
+				useProxyProtocolKey:  "true",
+				xffNumTrustedHopsKey: "1",
+			},
+			expect: &Options{
+				UseProxyProtocol:  true,
+				XffNumTrustedHops: uint32Ptr(1),
+			},
+		},
+		{
+			name: "ports",
+			input: map[string]string{
+				portsKey: `
+- port: 8443
+  use-proxy-protocol: true
+  xff-num-trusted-hops: 2
+- port: 80
+`,
+			},
+			expect: &Options{
+				Ports: []PortOptions{
+					{Port: 8443, UseProxyProtocol: true, XffNumTrustedHops: uint32Ptr(2)},
+					{Port: 80},
+				},
+			},
+		},
+		{
+			name:  "invalid bool",
+			input: map[string]string{useProxyProtocolKey: "yes please"},
+			err:   true,
+		},
+		{
+			name:  "invalid hops",
+			input: map[string]string{xffNumTrustedHopsKey: "-1"},
+			err:   true,
+		},
+		{
+			name:  "unknown field of port",
+			input: map[string]string{portsKey: "- port: 80\n  proxy: true"},
+			err:   true,
+		},
+		{
+			name:  "invalid port",
+			input: map[string]string{portsKey: "- port: 0"},
+			err:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := Parse(testCase.input)
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expect, options)
+		})
+	}
+}
+
+func TestProxyProtocolEnabled(t *testing.T) {
+	var options *Options
+	assert.False(t, options.ProxyProtocolEnabled(80))
+
+	options = &Options{
+		Ports: []PortOptions{{Port: 8443, UseProxyProtocol: true}},
+	}
+	assert.False(t, options.ProxyProtocolEnabled(80))
+	assert.True(t, options.ProxyProtocolEnabled(8443))
+
+	options.UseProxyProtocol = true
+	assert.True(t, options.ProxyProtocolEnabled(80))
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingressclassparams
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	kubeclient "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// Controller watches the IngressClassParams.
+type Controller interface {
+	AddEventHandler(func())
+
+	Run(stop <-chan struct{})
+
+	HasSynced() bool
+
+	// Params returns an error if the IngressClassParams doesn't exist or is invalid, the
+	// ingresses of the class should not be served without the params then.
+	Params(name string) (*Params, error)
+}
+
+type parsedParams struct {
+	resourceVersion string
+	params          *Params
+	err             error
+}
+
+type controller struct {
+	queue    workqueue.RateLimitingInterface
+	informer cache.SharedIndexInformer
+	lister   cache.GenericLister
+	handler  func()
+
+	mutex sync.Mutex
+	// The params are parsed again only if the resource version changes, so that the invalid
+	// ones are logged once instead of on every conversion.
+	parsed map[string]parsedParams
+}
+
+// NewController returns nil if the IngressClassParams CRD is not installed, e.g. the CRDs of the helm
+// chart are not upgraded, so that the informer doesn't block the controller from being ready.
+func NewController(client kubeclient.Client) Controller {
+	if !isServed(client) {
+		IngressLog.Errorf("The IngressClassParams CRD %s is not installed, ignore the params of the ingress classes",
+			GroupVersionResource)
+		return nil
+	}
+
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultItemBasedRateLimiter())
+	informer := client.DynamicInformer().ForResource(GroupVersionResource)
+	informer.Informer().AddEventHandler(controllers.LatestVersionHandlerFuncs(controllers.EnqueueForSelf(q)))
+
+	return &controller{
+		queue:    q,
+		informer: informer.Informer(),
+		lister:   informer.Lister(),
+		parsed:   map[string]parsedParams{},
+	}
+}
+
+// isServed returns false if the api server doesn't serve the IngressClassParams, it's assumed to be
+// served if the discovery fails for other reasons.
+func isServed(client kubeclient.Client) bool {
+	resources, err := client.Kube().Discovery().ServerResourcesForGroupVersion(GroupVersionResource.GroupVersion().String())
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false
+		}
+		IngressLog.Warnf("Discover the resources of %s error %v", GroupVersionResource.GroupVersion(), err)
+		return true
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == GroupVersionResource.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *controller) AddEventHandler(f func()) {
+	c.handler = f
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	if !cache.WaitForCacheSync(stop, c.HasSynced) {
+		IngressLog.Errorf("Failed to sync ingressclassparams controller cache")
+		return
+	}
+	go wait.Until(c.worker, time.Second, stop)
+	<-stop
+}
+
+func (c *controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	IngressLog.Debugf("ingressclassparams %s push to queue", key.(types.NamespacedName).Name)
+	if c.handler != nil {
+		c.handler()
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) HasSynced() bool {
+	return c.informer.HasSynced()
+}
+
+func (c *controller) Params(name string) (*Params, error) {
+	params, err := c.get(name)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			err = fmt.Errorf("ingressclassparams %s is not found", name)
+		} else {
+			IngressLog.Errorf("Get ingressclassparams %s error %v", name, err)
+		}
+		delete(c.parsed, name)
+		return nil, err
+	}
+
+	if parsed, exist := c.parsed[name]; exist && params.ResourceVersion != "" &&
+		parsed.resourceVersion == params.ResourceVersion {
+		return parsed.params, parsed.err
+	}
+	// The invalid default annotations are dropped, and the params are rejected on other errors.
+	out, err := Parse(params)
+	if err != nil {
+		IngressLog.Errorf("Parse ingressclassparams %s error %v", name, err)
+		if out != nil {
+			err = nil
+		} else {
+			err = fmt.Errorf("invalid ingressclassparams %s: %v", name, err)
+		}
+	}
+	c.parsed[name] = parsedParams{resourceVersion: params.ResourceVersion, params: out, err: err}
+	return out, err
+}
+
+func (c *controller) get(name string) (*IngressClassParams, error) {
+	obj, err := c.lister.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T of ingressclassparams %s", obj, name)
+	}
+
+	params := &IngressClassParams{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), params); err != nil {
+		return nil, fmt.Errorf("invalid ingressclassparams %s: %v", name, err)
+	}
+	return params, nil
+}
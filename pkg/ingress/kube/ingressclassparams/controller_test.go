@@ -0,0 +1,161 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingressclassparams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+)
+
+func newTestParams(name, resourceVersion string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "networking.higress.io/v1",
+		"kind":       Kind,
+		"metadata": map[string]interface{}{
+			"name":            name,
+			"resourceVersion": resourceVersion,
+		},
+		"spec": spec,
+	}}
+}
+
+func newTestClient(resources ...string) kube.Client {
+	client := kube.NewFakeClient()
+	resourceList := &metav1.APIResourceList{GroupVersion: GroupVersionResource.GroupVersion().String()}
+	for _, resource := range resources {
+		resourceList.APIResources = append(resourceList.APIResources, metav1.APIResource{Name: resource})
+	}
+	client.Kube().Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{resourceList}
+	return client
+}
+
+func TestNewController(t *testing.T) {
+	assert.NotNil(t, NewController(newTestClient("mcpbridges", GroupVersionResource.Resource)))
+	// The ingress class params are ignored without the CRD, rather than waiting for it forever.
+	assert.Nil(t, NewController(newTestClient("mcpbridges")))
+}
+
+func TestParams(t *testing.T) {
+	c := NewController(newTestClient(GroupVersionResource.Resource)).(*controller)
+	params, err := c.Params("internal")
+	assert.Nil(t, params)
+	assert.EqualError(t, err, "ingressclassparams internal is not found")
+
+	raw := newTestParams("internal", "1", map[string]interface{}{
+		"gatewaySelectorKey":   "higress",
+		"gatewaySelectorValue": "internal-gateway",
+		"defaultAnnotations": map[string]interface{}{
+			"ssl-redirect": "false",
+			"canary":       "true",
+		},
+		"allowedAnnotations": []interface{}{"ssl-redirect", "rewrite-target"},
+	})
+	if err := c.informer.GetStore().Add(raw); err != nil {
+		t.Fatalf("add ingressclassparams error %v", err)
+	}
+
+	// The invalid default annotations are dropped.
+	expect := &Params{
+		Name:               "internal",
+		GatewaySelector:    map[string]string{"higress": "internal-gateway"},
+		Defaults:           annotations.Defaults{"ssl-redirect": "false"},
+		AllowedAnnotations: sets.NewSet("ssl-redirect", "rewrite-target"),
+	}
+	params, err = c.Params("internal")
+	assert.NoError(t, err)
+	assert.Equal(t, expect, params)
+	// The params are parsed again only if the resource version changes.
+	cached, _ := c.Params("internal")
+	assert.Same(t, params, cached)
+
+	raw = newTestParams("internal", "2", map[string]interface{}{
+		"gatewaySelectorValue": "internal-gateway",
+	})
+	if err := c.informer.GetStore().Update(raw); err != nil {
+		t.Fatalf("update ingressclassparams error %v", err)
+	}
+	params, err = c.Params("internal")
+	assert.Nil(t, params)
+	assert.EqualError(t, err, `invalid ingressclassparams internal: gatewaySelectorKey is required by gatewaySelectorValue "internal-gateway"`)
+	// The invalid params are cached as well.
+	_, cachedErr := c.Params("internal")
+	assert.Same(t, err, cachedErr)
+
+	if err := c.informer.GetStore().Delete(raw); err != nil {
+		t.Fatalf("delete ingressclassparams error %v", err)
+	}
+	params, err = c.Params("internal")
+	assert.Nil(t, params)
+	assert.EqualError(t, err, "ingressclassparams internal is not found")
+	assert.Empty(t, c.parsed)
+}
+
+func TestReferencedName(t *testing.T) {
+	group := GroupName
+	otherGroup := "example.com"
+	cluster := "Cluster"
+	namespace := "Namespace"
+
+	testCases := []struct {
+		name     string
+		apiGroup *string
+		kind     string
+		scope    *string
+		expect   string
+	}{
+		{
+			name:     "cluster scope",
+			apiGroup: &group,
+			kind:     Kind,
+			scope:    &cluster,
+			expect:   "internal",
+		},
+		{
+			name:     "default scope",
+			apiGroup: &group,
+			kind:     Kind,
+			expect:   "internal",
+		},
+		{
+			name:     "namespace scope",
+			apiGroup: &group,
+			kind:     Kind,
+			scope:    &namespace,
+		},
+		{
+			name:     "other group",
+			apiGroup: &otherGroup,
+			kind:     Kind,
+		},
+		{
+			name: "core group",
+			kind: "ConfigMap",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expect, ReferencedName(testCase.apiGroup, testCase.kind, "internal", testCase.scope))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingressclassparams
+
+import (
+	"fmt"
+
+	"istio.io/istio/pilot/pkg/util/sets"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
+)
+
+const (
+	GroupName = "networking.higress.io"
+
+	Kind = "IngressClassParams"
+)
+
+// GroupVersionResource is the resource of the IngressClassParams CRD shipped in the helm chart.
+var GroupVersionResource = schema.GroupVersionResource{
+	Group:    GroupName,
+	Version:  "v1",
+	Resource: "ingressclassparams",
+}
+
+// IngressClassParams is the cluster scoped parameters referenced by the IngressClass, which
+// configure the ingresses of the class.
+type IngressClassParams struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IngressClassParamsSpec `json:"spec,omitempty"`
+}
+
+type IngressClassParamsSpec struct {
+	// GatewaySelectorKey and GatewaySelectorValue select the gateway serving the ingresses of
+	// the class, instead of the global gateway selector.
+	GatewaySelectorKey   string `json:"gatewaySelectorKey,omitempty"`
+	GatewaySelectorValue string `json:"gatewaySelectorValue,omitempty"`
+
+	// DefaultAnnotations are the default values of the annotations keyed by the names without
+	// the prefix, which take precedence over the global annotation defaults.
+	DefaultAnnotations map[string]string `json:"defaultAnnotations,omitempty"`
+
+	// AllowedAnnotations are the names of the annotations without the prefix which the ingresses
+	// of the class can set, all of them are allowed if it's empty.
+	AllowedAnnotations []string `json:"allowedAnnotations,omitempty"`
+}
+
+// Params are the parsed parameters applied to the ingresses of the class.
+type Params struct {
+	Name string
+
+	// GatewaySelector is nil if the global gateway selector is used.
+	GatewaySelector map[string]string
+
+	Defaults annotations.Defaults
+
+	// AllowedAnnotations is nil if all the annotations are allowed.
+	AllowedAnnotations sets.Set
+}
+
+// Parse parses the spec, the invalid default annotations are reported by the error and dropped,
+// the others are still returned.
+func Parse(params *IngressClassParams) (*Params, error) {
+	spec := params.Spec
+	if spec.GatewaySelectorKey == "" && spec.GatewaySelectorValue != "" {
+		return nil, fmt.Errorf("gatewaySelectorKey is required by gatewaySelectorValue %q", spec.GatewaySelectorValue)
+	}
+
+	out := &Params{Name: params.Name}
+	if spec.GatewaySelectorKey != "" {
+		out.GatewaySelector = map[string]string{spec.GatewaySelectorKey: spec.GatewaySelectorValue}
+	}
+	if len(spec.AllowedAnnotations) > 0 {
+		out.AllowedAnnotations = sets.NewSet(spec.AllowedAnnotations...)
+	}
+
+	defaults, err := annotations.ParseDefaults(spec.DefaultAnnotations)
+	if len(defaults) > 0 {
+		out.Defaults = defaults
+	}
+	if err != nil {
+		return out, fmt.Errorf("invalid defaultAnnotations: %v", err)
+	}
+	return out, nil
+}
+
+// ReferencedName returns the name of the IngressClassParams referenced by the parameters of
+// IngressClass, it's empty if the parameters are of other kinds.
+func ReferencedName(apiGroup *string, kind, name string, scope *string) string {
+	if apiGroup == nil || *apiGroup != GroupName || kind != Kind {
+		return ""
+	}
+	if scope != nil && *scope != "Cluster" {
+		return ""
+	}
+	return name
+}
@@ -38,6 +38,8 @@ import (
 	"istio.io/istio/pkg/kube/controllers"
 	ingress "k8s.io/api/networking/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -49,8 +51,8 @@ import (
 
 	"github.com/alibaba/higress/pkg/ingress/kube/annotations"
 	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/ingressclassparams"
 	"github.com/alibaba/higress/pkg/ingress/kube/secret"
-	"github.com/alibaba/higress/pkg/ingress/kube/util"
 	. "github.com/alibaba/higress/pkg/ingress/log"
 )
 
@@ -66,7 +68,10 @@ type controller struct {
 	virtualServiceHandlers  []model.EventHandler
 	gatewayHandlers         []model.EventHandler
 	destinationRuleHandlers []model.EventHandler
+	serviceEntryHandlers    []model.EventHandler
 	envoyFilterHandlers     []model.EventHandler
+	namespaceHandlers       []model.EventHandler
+	serviceHandlers         []model.EventHandler
 
 	options common.Options
 
@@ -79,6 +84,10 @@ type controller struct {
 	serviceInformer cache.SharedInformer
 	serviceLister   listerv1.ServiceLister
 	classes         networkingv1.IngressClassInformer
+	// May be nil if the namespaces are neither selected by labels nor select the gateways
+	namespaceInformer cache.SharedInformer
+	namespaceLister   listerv1.NamespaceLister
+	namespaceFilter   *common.NamespaceFilter
 
 	secretController secret.Controller
 
@@ -109,6 +118,20 @@ func NewController(localKubeClient, client kubeclient.Client, options common.Opt
 
 	handler := controllers.LatestVersionHandlerFuncs(controllers.EnqueueForSelf(q))
 	c.ingressInformer.AddEventHandler(handler)
+	c.serviceInformer.AddEventHandler(controllers.LatestVersionHandlerFuncs(c.onServiceEvent))
+
+	if options.NamespaceGatewayLabel != "" || options.WatchNamespaceSelector != "" {
+		namespaceInformer := client.KubeInformer().Core().V1().Namespaces()
+		c.namespaceInformer = namespaceInformer.Informer()
+		c.namespaceLister = namespaceInformer.Lister()
+	}
+	c.namespaceFilter = common.NewNamespaceFilter(options, c.namespaceLister)
+	if options.NamespaceGatewayLabel != "" {
+		c.namespaceInformer.AddEventHandler(common.NamespaceLabelHandler(options.NamespaceGatewayLabel, c.onNamespaceEvent))
+	}
+	if c.namespaceFilter.HasSelector() {
+		c.namespaceInformer.AddEventHandler(c.namespaceFilter.Handler(c.onNamespaceSelected))
+	}
 
 	if options.EnableStatus {
 		c.statusSyncer = newStatusSyncer(localKubeClient, client, c, options.SystemNamespace)
@@ -119,6 +142,74 @@ func NewController(localKubeClient, client kubeclient.Client, options common.Opt
 	return c
 }
 
+// ClassParamsName returns the name of the IngressClassParams referenced by the class of the ingress.
+func (c *controller) ClassParamsName(cfg *config.Config) string {
+	spec, ok := cfg.Spec.(ingress.IngressSpec)
+	if !ok || c.classes == nil {
+		return ""
+	}
+	className := cfg.Annotations[kube.IngressClassAnnotation]
+	if spec.IngressClassName != nil && *spec.IngressClassName != "" {
+		className = *spec.IngressClassName
+	}
+	if className == "" {
+		return ""
+	}
+
+	class, err := c.classes.Lister().Get(className)
+	if err != nil || class.Spec.Parameters == nil {
+		return ""
+	}
+	parameters := class.Spec.Parameters
+	return ingressclassparams.ReferencedName(parameters.APIGroup, parameters.Kind, parameters.Name, parameters.Scope)
+}
+
+// NamespaceGatewaySelector returns the gateway selector set by the NamespaceGatewayLabel of the namespace.
+func (c *controller) NamespaceGatewaySelector(namespace string) map[string]string {
+	return common.NamespaceGatewaySelector(c.namespaceLister, namespace, c.options)
+}
+
+// onServiceEvent notifies the handlers that the service is changed, which may be referenced by the ingresses.
+func (c *controller) onServiceEvent(obj controllers.Object) {
+	meta := config.Meta{
+		Name:             obj.GetName(),
+		Namespace:        obj.GetNamespace(),
+		GroupVersionKind: gvk.Service,
+		Annotations: map[string]string{
+			common.ClusterIdAnnotation: c.options.ClusterId,
+		},
+	}
+	for _, f := range c.serviceHandlers {
+		f(config.Config{Meta: meta}, config.Config{Meta: meta}, model.EventUpdate)
+	}
+}
+
+// onNamespaceEvent notifies the handlers that the gateway selector of the namespace is changed.
+func (c *controller) onNamespaceEvent(namespace string) {
+	IngressLog.Debugf("gateway selector of namespace %s is changed, cluster: %s", namespace, c.options.ClusterId)
+	meta := config.Meta{
+		Name:             namespace,
+		GroupVersionKind: gvk.Namespace,
+	}
+	for _, f := range c.namespaceHandlers {
+		f(config.Config{Meta: meta}, config.Config{Meta: meta}, model.EventUpdate)
+	}
+}
+
+// onNamespaceSelected reprocesses the ingresses in the namespace which is selected or unselected.
+func (c *controller) onNamespaceSelected(namespace string) {
+	ingresses, err := c.ingressLister.Ingresses(namespace).List(labels.Everything())
+	if err != nil {
+		IngressLog.Errorf("List ingresses of namespace %s error %v, cluster: %s", namespace, err, c.options.ClusterId)
+		return
+	}
+	IngressLog.Infof("Namespace %s is selected or unselected, reprocess %d ingresses, cluster: %s",
+		namespace, len(ingresses), c.options.ClusterId)
+	for _, ing := range ingresses {
+		c.queue.Add(types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name})
+	}
+}
+
 func (c *controller) ServiceLister() listerv1.ServiceLister {
 	return c.serviceLister
 }
@@ -208,6 +299,13 @@ func (c *controller) onEvent(namespacedName types.NamespacedName) error {
 		// Set this label so that we do not compare configs and just push.
 		Labels: map[string]string{constants.AlwaysPushLabel: "true"},
 	}
+	semetadata := config.Meta{
+		Name:             ing.Name + "-" + "serviceentry",
+		Namespace:        ing.Namespace,
+		GroupVersionKind: gvk.ServiceEntry,
+		// Set this label so that we do not compare configs and just push.
+		Labels: map[string]string{constants.AlwaysPushLabel: "true"},
+	}
 	vsmetadata := config.Meta{
 		Name:             ing.Name + "-" + "virtualservice",
 		Namespace:        ing.Namespace,
@@ -234,6 +332,10 @@ func (c *controller) onEvent(namespacedName types.NamespacedName) error {
 		f(config.Config{Meta: drmetadata}, config.Config{Meta: drmetadata}, event)
 	}
 
+	for _, f := range c.serviceEntryHandlers {
+		f(config.Config{Meta: semetadata}, config.Config{Meta: semetadata}, event)
+	}
+
 	for _, f := range c.virtualServiceHandlers {
 		f(config.Config{Meta: vsmetadata}, config.Config{Meta: vsmetadata}, event)
 	}
@@ -257,8 +359,14 @@ func (c *controller) RegisterEventHandler(kind config.GroupVersionKind, f model.
 		c.gatewayHandlers = append(c.gatewayHandlers, f)
 	case gvk.DestinationRule:
 		c.destinationRuleHandlers = append(c.destinationRuleHandlers, f)
+	case gvk.ServiceEntry:
+		c.serviceEntryHandlers = append(c.serviceEntryHandlers, f)
 	case gvk.EnvoyFilter:
 		c.envoyFilterHandlers = append(c.envoyFilterHandlers, f)
+	case gvk.Namespace:
+		c.namespaceHandlers = append(c.namespaceHandlers, f)
+	case gvk.Service:
+		c.serviceHandlers = append(c.serviceHandlers, f)
 	}
 }
 
@@ -273,6 +381,11 @@ func (c *controller) SetWatchErrorHandler(handler func(r *cache.Reflector, err e
 	if err := c.secretController.Informer().SetWatchErrorHandler(handler); err != nil {
 		errs = multierror.Append(errs, err)
 	}
+	if c.namespaceInformer != nil {
+		if err := c.namespaceInformer.SetWatchErrorHandler(handler); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
 	if err := c.classes.Informer().SetWatchErrorHandler(handler); err != nil {
 		errs = multierror.Append(errs, err)
 	}
@@ -282,11 +395,14 @@ func (c *controller) SetWatchErrorHandler(handler func(r *cache.Reflector, err e
 func (c *controller) HasSynced() bool {
 	return c.ingressInformer.HasSynced() && c.serviceInformer.HasSynced() &&
 		c.classes.Informer().HasSynced() &&
+		(c.namespaceInformer == nil || c.namespaceInformer.HasSynced()) &&
 		c.secretController.HasSynced()
 }
 
 func (c *controller) List() []config.Config {
+	c.mutex.RLock()
 	out := make([]config.Config, 0, len(c.ingresses))
+	c.mutex.RUnlock()
 
 	for _, raw := range c.ingressInformer.GetStore().List() {
 		ing, ok := raw.(*ingress.Ingress)
@@ -298,27 +414,47 @@ func (c *controller) List() []config.Config {
 			continue
 		}
 
-		copiedConfig := ing.DeepCopy()
-		setDefaultMSEIngressOptionalField(copiedConfig)
-
-		outConfig := config.Config{
-			Meta: config.Meta{
-				Name:              copiedConfig.Name,
-				Namespace:         copiedConfig.Namespace,
-				Annotations:       common.CreateOrUpdateAnnotations(copiedConfig.Annotations, c.options),
-				Labels:            copiedConfig.Labels,
-				CreationTimestamp: copiedConfig.CreationTimestamp.Time,
-			},
-			Spec: copiedConfig.Spec,
-		}
-
-		out = append(out, outConfig)
+		out = append(out, c.toConfig(ing))
 	}
 
 	common.RecordIngressNumber(c.options.ClusterId, len(out))
 	return out
 }
 
+// ToConfig converts the ingress in the same way as List, it returns false if the object is not an
+// ingress of the served version or not processed by the controller.
+func (c *controller) ToConfig(obj runtime.Object) (*config.Config, bool) {
+	ing, ok := obj.(*ingress.Ingress)
+	if !ok {
+		return nil, false
+	}
+
+	if should, err := c.shouldProcessIngress(ing); !should || err != nil {
+		return nil, false
+	}
+
+	out := c.toConfig(ing)
+	return &out, true
+}
+
+func (c *controller) toConfig(ing *ingress.Ingress) config.Config {
+	copiedConfig := ing.DeepCopy()
+	setDefaultMSEIngressOptionalField(copiedConfig)
+
+	return config.Config{
+		Meta: config.Meta{
+			Name:              copiedConfig.Name,
+			Namespace:         copiedConfig.Namespace,
+			Annotations:       common.CreateOrUpdateAnnotations(copiedConfig.Annotations, c.options),
+			Labels:            copiedConfig.Labels,
+			CreationTimestamp: copiedConfig.CreationTimestamp.Time,
+			UID:               string(copiedConfig.UID),
+			ResourceVersion:   copiedConfig.ResourceVersion,
+		},
+		Spec: copiedConfig.Spec,
+	}
+}
+
 func extractTLSSecretName(host string, tls []ingress.IngressTLS) string {
 	if len(tls) == 0 {
 		return ""
@@ -349,11 +485,17 @@ func (c *controller) ConvertGateway(convertOptions *common.ConvertOptions, wrapp
 	cfg := wrapper.Config
 	ingressV1, ok := cfg.Spec.(ingress.IngressSpec)
 	if !ok {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.Unknown)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.Unknown)
 		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
 	}
 	if len(ingressV1.Rules) == 0 && ingressV1.DefaultBackend == nil {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.EmptyRule)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.EmptyRule)
+		convertOptions.IngressProblems = append(convertOptions.IngressProblems, common.IngressProblem{
+			ClusterId: c.options.ClusterId,
+			Event:     common.EmptyRule,
+			Message:   "either `defaultBackend` or `rules` must be specified",
+			Ingress:   cfg,
+		})
 		return fmt.Errorf("invalid ingress rule %s:%s in cluster %s, either `defaultBackend` or `rules` must be specified", cfg.Namespace, cfg.Name, c.options.ClusterId)
 	}
 
@@ -361,11 +503,12 @@ func (c *controller) ConvertGateway(convertOptions *common.ConvertOptions, wrapp
 		cleanHost := common.CleanHost(rule.Host)
 		// Need create builder for every rule.
 		domainBuilder := &common.IngressDomainBuilder{
-			ClusterId: c.options.ClusterId,
-			Protocol:  common.HTTP,
-			Host:      rule.Host,
-			Ingress:   cfg,
-			Event:     common.Normal,
+			ClusterId:        c.options.ClusterId,
+			Protocol:         common.HTTP,
+			Host:             rule.Host,
+			Ingress:          cfg,
+			Event:            common.Normal,
+			AnnotationErrors: wrapper.AnnotationErrors,
 		}
 
 		// Extract the previous gateway and builder
@@ -378,18 +521,21 @@ func (c *controller) ConvertGateway(convertOptions *common.ConvertOptions, wrapp
 				ClusterId:     c.options.ClusterId,
 				Host:          rule.Host,
 			}
-			if c.options.GatewaySelectorKey != "" {
+			if wrapper.GatewaySelector != nil {
+				wrapperGateway.Gateway.Selector = wrapper.GatewaySelector
+			} else if c.options.GatewaySelectorKey != "" {
 				wrapperGateway.Gateway.Selector = map[string]string{c.options.GatewaySelectorKey: c.options.GatewaySelectorValue}
-
 			}
-			wrapperGateway.Gateway.Servers = append(wrapperGateway.Gateway.Servers, &networking.Server{
-				Port: &networking.Port{
-					Number:   80,
-					Protocol: string(protocol.HTTP),
-					Name:     common.CreateConvertedName("http-80-ingress", c.options.ClusterId, cfg.Namespace, cfg.Name, cleanHost),
-				},
-				Hosts: []string{rule.Host},
-			})
+			for _, port := range wrapper.AnnotationsConfig.HTTPPorts() {
+				wrapperGateway.Gateway.Servers = append(wrapperGateway.Gateway.Servers, &networking.Server{
+					Port: &networking.Port{
+						Number:   port,
+						Protocol: string(protocol.HTTP),
+						Name:     common.CreateConvertedName(fmt.Sprintf("http-%d-ingress", port), c.options.ClusterId, cfg.Namespace, cfg.Name, cleanHost),
+					},
+					Hosts: []string{rule.Host},
+				})
+			}
 
 			// Add new gateway, builder
 			convertOptions.Gateways[rule.Host] = wrapperGateway
@@ -401,13 +547,21 @@ func (c *controller) ConvertGateway(convertOptions *common.ConvertOptions, wrapp
 			}
 		}
 
+		// Get tls secret matching the rule host
+		secretName := extractTLSSecretName(rule.Host, ingressV1.TLS)
+		if convertOptions.HostSettingsCache != nil {
+			var tlsSecret string
+			if secretName != "" {
+				tlsSecret = path.Join(c.options.ClusterId, cfg.Namespace, secretName)
+			}
+			convertOptions.HostSettingsCache.Add(rule.Host, common.NewHostSettings(wrapper, c.options.ClusterId, tlsSecret))
+		}
+
 		// There are no tls settings, so just skip.
 		if len(ingressV1.TLS) == 0 {
 			continue
 		}
 
-		// Get tls secret matching the rule host
-		secretName := extractTLSSecretName(rule.Host, ingressV1.TLS)
 		if secretName == "" {
 			// There no matching secret, so just skip.
 			continue
@@ -421,24 +575,42 @@ func (c *controller) ConvertGateway(convertOptions *common.ConvertOptions, wrapp
 		if wrapperGateway.IsHTTPS() {
 			domainBuilder.Event = common.DuplicatedTls
 			domainBuilder.PreIngress = preDomainBuilder.Ingress
-			convertOptions.IngressDomainCache.Invalid = append(convertOptions.IngressDomainCache.Invalid,
-				domainBuilder.Build())
+			convertOptions.IngressDomainCache.Invalid = append(convertOptions.IngressDomainCache.Invalid, domainBuilder)
 			continue
 		}
 
-		// Append https server
-		wrapperGateway.Gateway.Servers = append(wrapperGateway.Gateway.Servers, &networking.Server{
-			Port: &networking.Port{
-				Number:   443,
-				Protocol: string(protocol.HTTPS),
-				Name:     common.CreateConvertedName("https-443-ingress", c.options.ClusterId, cfg.Namespace, cfg.Name, cleanHost),
-			},
-			Hosts: []string{rule.Host},
-			Tls: &networking.ServerTLSSettings{
-				Mode:           networking.ServerTLSSettings_SIMPLE,
-				CredentialName: credentials.ToKubernetesIngressResource(c.options.RawClusterId, cfg.Namespace, secretName),
-			},
-		})
+		needHTTP3 := wrapper.AnnotationsConfig.NeedHTTP3(c.options.EnableHTTP3)
+		for _, port := range wrapper.AnnotationsConfig.HTTPSPorts() {
+			// Append https server
+			wrapperGateway.Gateway.Servers = append(wrapperGateway.Gateway.Servers, &networking.Server{
+				Port: &networking.Port{
+					Number:   port,
+					Protocol: string(protocol.HTTPS),
+					Name:     common.CreateConvertedName(fmt.Sprintf("https-%d-ingress", port), c.options.ClusterId, cfg.Namespace, cfg.Name, cleanHost),
+				},
+				Hosts: []string{rule.Host},
+				Tls: &networking.ServerTLSSettings{
+					Mode:           networking.ServerTLSSettings_SIMPLE,
+					CredentialName: credentials.ToKubernetesIngressResource(c.options.RawClusterId, cfg.Namespace, secretName),
+				},
+			})
+
+			// Append quic server which shares the tls settings with https server.
+			if needHTTP3 {
+				wrapperGateway.Gateway.Servers = append(wrapperGateway.Gateway.Servers, &networking.Server{
+					Port: &networking.Port{
+						Number:   port,
+						Protocol: string(protocol.UDP),
+						Name:     common.CreateConvertedName(fmt.Sprintf("http3-%d-ingress", port), c.options.ClusterId, cfg.Namespace, cfg.Name, cleanHost),
+					},
+					Hosts: []string{rule.Host},
+					Tls: &networking.ServerTLSSettings{
+						Mode:           networking.ServerTLSSettings_SIMPLE,
+						CredentialName: credentials.ToKubernetesIngressResource(c.options.RawClusterId, cfg.Namespace, secretName),
+					},
+				})
+			}
+		}
 
 		// Update domain builder
 		convertOptions.IngressDomainCache.Valid[rule.Host] = domainBuilder
@@ -457,11 +629,11 @@ func (c *controller) ConvertHTTPRoute(convertOptions *common.ConvertOptions, wra
 	cfg := wrapper.Config
 	ingressV1, ok := cfg.Spec.(ingress.IngressSpec)
 	if !ok {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.Unknown)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.Unknown)
 		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
 	}
 	if len(ingressV1.Rules) == 0 && ingressV1.DefaultBackend == nil {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.EmptyRule)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.EmptyRule)
 		return fmt.Errorf("invalid ingress rule %s:%s in cluster %s, either `defaultBackend` or `rules` must be specified", cfg.Namespace, cfg.Name, c.options.ClusterId)
 	}
 
@@ -494,6 +666,11 @@ func (c *controller) ConvertHTTPRoute(convertOptions *common.ConvertOptions, wra
 			convertOptions.VirtualServices[rule.Host] = wrapperVS
 		}
 
+		// Only https hosts can be upgraded to HTTP/3, which is advertised on the first https port.
+		httpsPorts := wrapper.AnnotationsConfig.HTTPSPorts()
+		http3 := wrapper.AnnotationsConfig.NeedHTTP3(c.options.EnableHTTP3) && len(httpsPorts) != 0 &&
+			extractTLSSecretName(rule.Host, ingressV1.TLS) != ""
+
 		// Record the latest app root for per host.
 		redirect := wrapper.AnnotationsConfig.Redirect
 		if redirect != nil && redirect.AppRoot != "" {
@@ -543,6 +720,9 @@ func (c *controller) ConvertHTTPRoute(convertOptions *common.ConvertOptions, wra
 			wrapperHttpRoute.OriginPath = path
 			wrapperHttpRoute.HTTPRoute.Match = []*networking.HTTPMatchRequest{httpMatch}
 			wrapperHttpRoute.HTTPRoute.Name = common.GenerateUniqueRouteName(wrapperHttpRoute)
+			if http3 {
+				common.AddAltSvcHeader(wrapperHttpRoute.HTTPRoute, httpsPorts[0])
+			}
 
 			ingressRouteBuilder := convertOptions.IngressRouteCache.New(wrapperHttpRoute)
 
@@ -566,14 +746,14 @@ func (c *controller) ConvertHTTPRoute(convertOptions *common.ConvertOptions, wra
 
 			// backend service check
 			var event common.Event
-			wrapperHttpRoute.HTTPRoute.Route, event = c.backendToRouteDestination(&httpPath.Backend, cfg.Namespace, ingressRouteBuilder)
+			wrapperHttpRoute.HTTPRoute.Route, event = c.backendToRouteDestination(&httpPath.Backend, cfg.Namespace, ingressRouteBuilder, wrapper.AnnotationsConfig.Destination)
 
 			if ingressRouteBuilder.Event != common.Normal {
 				event = ingressRouteBuilder.Event
 			}
 
 			if event != common.Normal {
-				common.IncrementInvalidIngress(c.options.ClusterId, event)
+				convertOptions.AddInvalidIngress(c.options.ClusterId, event)
 				ingressRouteBuilder.Event = event
 			} else {
 				wrapperHttpRoutes = append(wrapperHttpRoutes, wrapperHttpRoute)
@@ -614,7 +794,7 @@ func (c *controller) ApplyDefaultBackend(convertOptions *common.ConvertOptions,
 	cfg := wrapper.Config
 	ingressV1, ok := cfg.Spec.(ingress.IngressSpec)
 	if !ok {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.Unknown)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.Unknown)
 		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
 	}
 
@@ -683,11 +863,11 @@ func (c *controller) ApplyCanaryIngress(convertOptions *common.ConvertOptions, w
 	cfg := wrapper.Config
 	ingressV1, ok := cfg.Spec.(ingress.IngressSpec)
 	if !ok {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.Unknown)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.Unknown)
 		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
 	}
 	if len(ingressV1.Rules) == 0 && ingressV1.DefaultBackend == nil {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.EmptyRule)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.EmptyRule)
 		return fmt.Errorf("invalid ingress rule %s:%s in cluster %s, either `defaultBackend` or `rules` must be specified", cfg.Namespace, cfg.Name, c.options.ClusterId)
 	}
 
@@ -748,9 +928,9 @@ func (c *controller) ApplyCanaryIngress(convertOptions *common.ConvertOptions, w
 			ingressRouteBuilder := convertOptions.IngressRouteCache.New(canary)
 			// backend service check
 			var event common.Event
-			canary.HTTPRoute.Route, event = c.backendToRouteDestination(&httpPath.Backend, cfg.Namespace, ingressRouteBuilder)
+			canary.HTTPRoute.Route, event = c.backendToRouteDestination(&httpPath.Backend, cfg.Namespace, ingressRouteBuilder, wrapper.AnnotationsConfig.Destination)
 			if event != common.Normal {
-				common.IncrementInvalidIngress(c.options.ClusterId, event)
+				convertOptions.AddInvalidIngress(c.options.ClusterId, event)
 				ingressRouteBuilder.Event = event
 				convertOptions.IngressRouteCache.Add(ingressRouteBuilder)
 				continue
@@ -816,11 +996,11 @@ func (c *controller) ConvertTrafficPolicy(convertOptions *common.ConvertOptions,
 	cfg := wrapper.Config
 	ingressV1, ok := cfg.Spec.(ingress.IngressSpec)
 	if !ok {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.Unknown)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.Unknown)
 		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
 	}
 	if len(ingressV1.Rules) == 0 && ingressV1.DefaultBackend == nil {
-		common.IncrementInvalidIngress(c.options.ClusterId, common.EmptyRule)
+		convertOptions.AddInvalidIngress(c.options.ClusterId, common.EmptyRule)
 		return fmt.Errorf("invalid ingress rule %s:%s in cluster %s, either `defaultBackend` or `rules` must be specified", cfg.Namespace, cfg.Name, c.options.ClusterId)
 	}
 
@@ -876,6 +1056,51 @@ func (c *controller) ConvertTrafficPolicy(convertOptions *common.ConvertOptions,
 	return nil
 }
 
+// ConvertServiceEntry records the ExternalName services of the default backend and the rules.
+func (c *controller) ConvertServiceEntry(convertOptions *common.ConvertOptions, wrapper *common.WrapperConfig) error {
+	cfg := wrapper.Config
+	ingressV1, ok := cfg.Spec.(ingress.IngressSpec)
+	if !ok {
+		return fmt.Errorf("convert type is invalid in cluster %s", c.options.ClusterId)
+	}
+
+	var services []*ingress.IngressServiceBackend
+	if ingressV1.DefaultBackend != nil {
+		services = append(services, ingressV1.DefaultBackend.Service)
+	}
+	for _, rule := range ingressV1.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, httpPath := range rule.HTTP.Paths {
+			services = append(services, httpPath.Backend.Service)
+		}
+	}
+
+	for _, service := range services {
+		if service == nil {
+			continue
+		}
+		externalName := common.ExternalName(c.serviceLister, cfg.Namespace, service.Name)
+		if externalName == "" {
+			continue
+		}
+		serviceKey, err := c.createServiceKey(service, cfg.Namespace)
+		if err != nil {
+			IngressLog.Errorf("ignore external name service %s within ingress %s/%s", service.Name, cfg.Namespace, cfg.Name)
+			continue
+		}
+		if _, exist := convertOptions.Service2ExternalName[serviceKey]; !exist {
+			convertOptions.Service2ExternalName[serviceKey] = &common.WrapperExternalName{
+				Host:          externalName,
+				WrapperConfig: wrapper,
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c *controller) createDefaultRoute(wrapper *common.WrapperConfig, backend *ingress.IngressBackend, host string) *common.WrapperHTTPRoute {
 	if backend == nil || backend.Service == nil || backend.Service.Name == "" {
 		return nil
@@ -898,7 +1123,7 @@ func (c *controller) createDefaultRoute(wrapper *common.WrapperConfig, backend *
 	routeDestination := []*networking.HTTPRouteDestination{
 		{
 			Destination: &networking.Destination{
-				Host: util.CreateServiceFQDN(namespace, service.Name),
+				Host: common.ServiceHost(c.serviceLister, namespace, service.Name),
 				Port: port,
 			},
 			Weight: 100,
@@ -951,7 +1176,11 @@ func isCanaryRoute(canary, route *common.WrapperHTTPRoute) bool {
 }
 
 func (c *controller) backendToRouteDestination(backend *ingress.IngressBackend, namespace string,
-	builder *common.IngressRouteBuilder) ([]*networking.HTTPRouteDestination, common.Event) {
+	builder *common.IngressRouteBuilder, destination *annotations.DestinationConfig) ([]*networking.HTTPRouteDestination, common.Event) {
+	if destination != nil {
+		return common.DestinationToRouteDestination(destination, builder), common.Normal
+	}
+
 	if backend == nil || backend.Service == nil {
 		return nil, common.InvalidBackendService
 	}
@@ -986,7 +1215,7 @@ func (c *controller) backendToRouteDestination(backend *ingress.IngressBackend,
 	return []*networking.HTTPRouteDestination{
 		{
 			Destination: &networking.Destination{
-				Host: util.CreateServiceFQDN(namespace, service.Name),
+				Host: common.ServiceHost(c.serviceLister, namespace, service.Name),
 				Port: port,
 			},
 			Weight: 100,
@@ -1051,12 +1280,7 @@ func (c *controller) shouldProcessIngress(i *ingress.Ingress) (bool, error) {
 	// first check ingress class
 	if c.shouldProcessIngressWithClass(i, class) {
 		// then check namespace
-		switch c.options.WatchNamespace {
-		case "":
-			return true, nil
-		default:
-			return c.options.WatchNamespace == i.Namespace, nil
-		}
+		return c.namespaceFilter.Watched(i.Namespace), nil
 	}
 
 	return false, nil
@@ -0,0 +1,149 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpbridge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kubeclient "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// Controller watches the McpBridges in the system namespace.
+type Controller interface {
+	AddEventHandler(func(types.NamespacedName))
+
+	Run(stop <-chan struct{})
+
+	HasSynced() bool
+
+	// Get returns the McpBridge, the error is not found if it doesn't exist.
+	Get(name types.NamespacedName) (*McpBridge, error)
+
+	// UpdateStatus writes the status of the McpBridge.
+	UpdateStatus(bridge *McpBridge) error
+}
+
+type controller struct {
+	queue    workqueue.RateLimitingInterface
+	informer cache.SharedIndexInformer
+	lister   cache.GenericLister
+	client   dynamic.Interface
+	handler  func(types.NamespacedName)
+}
+
+func NewController(client kubeclient.Client, namespace string) Controller {
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultItemBasedRateLimiter())
+	informer := client.DynamicInformer().ForResource(GroupVersionResource)
+	informer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			object, ok := obj.(metav1.Object)
+			return ok && object.GetNamespace() == namespace
+		},
+		Handler: controllers.LatestVersionHandlerFuncs(controllers.EnqueueForSelf(q)),
+	})
+
+	return &controller{
+		queue:    q,
+		informer: informer.Informer(),
+		lister:   informer.Lister(),
+		client:   client.Dynamic(),
+	}
+}
+
+func (c *controller) AddEventHandler(f func(types.NamespacedName)) {
+	c.handler = f
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	if !cache.WaitForCacheSync(stop, c.HasSynced) {
+		IngressLog.Errorf("Failed to sync mcpbridge controller cache")
+		return
+	}
+	go wait.Until(c.worker, time.Second, stop)
+	<-stop
+}
+
+func (c *controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	namespacedName := key.(types.NamespacedName)
+	IngressLog.Debugf("mcpbridge %s push to queue", namespacedName)
+	if c.handler != nil {
+		c.handler(namespacedName)
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) HasSynced() bool {
+	return c.informer.HasSynced()
+}
+
+func (c *controller) Get(name types.NamespacedName) (*McpBridge, error) {
+	obj, err := c.lister.ByNamespace(name.Namespace).Get(name.Name)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T of mcpbridge %s", obj, name)
+	}
+
+	bridge := &McpBridge{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), bridge); err != nil {
+		return nil, fmt.Errorf("invalid mcpbridge %s: %v", name, err)
+	}
+	return bridge, nil
+}
+
+func (c *controller) UpdateStatus(bridge *McpBridge) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(bridge)
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(GroupVersionResource.GroupVersion().WithKind("McpBridge"))
+	_, err = c.client.Resource(GroupVersionResource).Namespace(bridge.Namespace).
+		UpdateStatus(context.TODO(), u, metav1.UpdateOptions{})
+	return err
+}
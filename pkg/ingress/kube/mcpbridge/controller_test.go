@@ -0,0 +1,92 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpbridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestBridge() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "istio.aliyun.cloud.com/v1",
+		"kind":       "McpBridge",
+		"metadata": map[string]interface{}{
+			"name":      "default",
+			"namespace": "higress-system",
+		},
+		"spec": map[string]interface{}{
+			"registries": []interface{}{
+				map[string]interface{}{
+					"type":                 "nacos",
+					"domain":               "127.0.0.1",
+					"port":                 int64(8848),
+					"nacosGroups":          []interface{}{"DEFAULT_GROUP"},
+					"nacosScretKey":        "secret",
+					"nacosRefreshInterval": int64(30000000000),
+				},
+			},
+		},
+	}}
+}
+
+func TestGetAndUpdateStatus(t *testing.T) {
+	client := kube.NewFakeClient()
+	c := NewController(client, "higress-system").(*controller)
+	name := types.NamespacedName{Namespace: "higress-system", Name: "default"}
+
+	_, err := c.Get(name)
+	assert.Error(t, err)
+
+	raw := newTestBridge()
+	if err = c.informer.GetStore().Add(raw); err != nil {
+		t.Fatalf("add mcpbridge error %v", err)
+	}
+	if _, err = client.Dynamic().Resource(GroupVersionResource).Namespace(name.Namespace).
+		Create(context.TODO(), raw, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create mcpbridge error %v", err)
+	}
+
+	bridge, err := c.Get(name)
+	assert.NoError(t, err)
+	assert.Equal(t, []*RegistryConfig{
+		{
+			Type:                 "nacos",
+			Domain:               "127.0.0.1",
+			Port:                 8848,
+			NacosGroups:          []string{"DEFAULT_GROUP"},
+			NacosSecretKey:       "secret",
+			NacosRefreshInterval: 30 * time.Second,
+		},
+	}, bridge.Spec.Registries)
+	assert.Equal(t, "nacos-127.0.0.1-8848", bridge.Spec.Registries[0].RegistryName())
+
+	bridge.Status.Registries = []RegistryStatus{{Name: "nacos-127.0.0.1-8848", Type: "nacos", Phase: "Synced", ServiceCount: 2}}
+	assert.NoError(t, c.UpdateStatus(bridge))
+	updated, err := client.Dynamic().Resource(GroupVersionResource).Namespace(name.Namespace).
+		Get(context.TODO(), name.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	registries, _, _ := unstructured.NestedSlice(updated.Object, "status", "registries")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "nacos-127.0.0.1-8848", "type": "nacos", "phase": "Synced", "serviceCount": int64(2)},
+	}, registries)
+}
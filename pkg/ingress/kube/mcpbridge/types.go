@@ -0,0 +1,93 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcpbridge
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionResource is the resource of the McpBridge CRD shipped in the helm chart.
+var GroupVersionResource = schema.GroupVersionResource{
+	Group:    "istio.aliyun.cloud.com",
+	Version:  "v1",
+	Resource: "mcpbridges",
+}
+
+// McpBridge declares the external service registries whose services are exposed as service entries.
+type McpBridge struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   McpBridgeSpec   `json:"spec,omitempty"`
+	Status McpBridgeStatus `json:"status,omitempty"`
+}
+
+type McpBridgeSpec struct {
+	Registries []*RegistryConfig `json:"registries,omitempty"`
+}
+
+// RegistryConfig is the address and the options of an external service registry, the json names
+// must be kept consistent with the CRD.
+type RegistryConfig struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	// Domain is the address of the registry, for the static registry it's the comma separated
+	// ip:port list of the endpoints, and for the dns registry it's the comma separated domains.
+	Domain string `json:"domain"`
+	// Port is the port of the registry, or the service port of the static and dns registries.
+	Port int64 `json:"port"`
+
+	NacosAddressServer   string        `json:"nacosAddressServer,omitempty"`
+	NacosAccessKey       string        `json:"nacosAccessKey,omitempty"`
+	NacosSecretKey       string        `json:"nacosScretKey,omitempty"`
+	NacosNamespaceId     string        `json:"nacosNamespaceId,omitempty"`
+	NacosNamespace       string        `json:"nacosNamespace,omitempty"`
+	NacosGroups          []string      `json:"nacosGroups,omitempty"`
+	NacosRefreshInterval time.Duration `json:"nacosRefreshInterval,omitempty"`
+
+	ConsulNamespace  string `json:"consulNamespace,omitempty"`
+	ConsulDatacenter string `json:"consulDatacenter,omitempty"`
+	// ConsulServiceTags selects the services which have all the tags.
+	ConsulServiceTags []string `json:"consulServiceTags,omitempty"`
+
+	ZkServicesPath []string `json:"zkServicesPath,omitempty"`
+}
+
+// RegistryName returns the name of the registry, which defaults to type-domain-port.
+func (r *RegistryConfig) RegistryName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s-%s-%d", r.Type, r.Domain, r.Port)
+}
+
+type McpBridgeStatus struct {
+	Registries []RegistryStatus `json:"registries,omitempty"`
+}
+
+// RegistryStatus is the sync status of a registry.
+type RegistryStatus struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Phase string `json:"phase"`
+	// Message is the reason of the failure.
+	Message string `json:"message,omitempty"`
+	// ServiceCount is the number of the services synced from the registry.
+	ServiceCount int `json:"serviceCount"`
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcpservices
+
+import (
+	"fmt"
+
+	"istio.io/istio/pkg/config/protocol"
+	kubeclient "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/configmap"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// Controller watches the tcp-services and udp-services ConfigMaps, and the services
+// referenced by named ports.
+type Controller interface {
+	AddEventHandler(func())
+
+	Run(stop <-chan struct{})
+
+	HasSynced() bool
+
+	// Entries returns the valid entries of both ConfigMaps, the named service ports
+	// are resolved, and the entries whose service port can't be resolved are skipped.
+	Entries() []*Entry
+}
+
+type controller struct {
+	configMapController configmap.Controller
+
+	serviceInformer cache.SharedIndexInformer
+	serviceLister   listerv1.ServiceLister
+
+	// ConfigMap name -> protocol
+	configMaps map[types.NamespacedName]protocol.Instance
+}
+
+// NewController returns nil when neither tcp-services nor udp-services ConfigMap is specified.
+func NewController(client kubeclient.Client, options common.Options) Controller {
+	configMaps := map[types.NamespacedName]protocol.Instance{}
+	if options.TCPServicesConfigMap != "" {
+		configMaps[configmap.ParseName(options.TCPServicesConfigMap, options.SystemNamespace)] = protocol.TCP
+	}
+	if options.UDPServicesConfigMap != "" {
+		configMaps[configmap.ParseName(options.UDPServicesConfigMap, options.SystemNamespace)] = protocol.UDP
+	}
+	if len(configMaps) == 0 {
+		return nil
+	}
+
+	var names []types.NamespacedName
+	for name := range configMaps {
+		names = append(names, name)
+	}
+	serviceInformer := client.KubeInformer().Core().V1().Services()
+	return &controller{
+		configMapController: configmap.NewController(client, names...),
+		serviceInformer:     serviceInformer.Informer(),
+		serviceLister:       serviceInformer.Lister(),
+		configMaps:          configMaps,
+	}
+}
+
+func (c *controller) AddEventHandler(f func()) {
+	c.configMapController.AddEventHandler(func(types.NamespacedName) {
+		f()
+	})
+	// The port of a named service port may change with the service.
+	c.serviceInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: c.referencedByNamedPort,
+		Handler: controllers.LatestVersionHandlerFuncs(func(controllers.Object) {
+			f()
+		}),
+	})
+}
+
+func (c *controller) referencedByNamedPort(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		return false
+	}
+	service := types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}
+	for name, proto := range c.configMaps {
+		configMap, err := c.configMapController.Get(name)
+		if err != nil {
+			continue
+		}
+		entries, _ := ParseEntries(configMap.Data, proto)
+		for _, entry := range entries {
+			if entry.ServicePortName != "" && entry.Service == service {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *controller) Run(stop <-chan struct{}) {
+	c.configMapController.Run(stop)
+}
+
+func (c *controller) HasSynced() bool {
+	return c.configMapController.HasSynced() && c.serviceInformer.HasSynced()
+}
+
+func (c *controller) Entries() []*Entry {
+	var out []*Entry
+	for name, proto := range c.configMaps {
+		configMap, err := c.configMapController.Get(name)
+		if err != nil {
+			if !kerrors.IsNotFound(err) {
+				IngressLog.Errorf("Get %s services configmap %s error %v", proto, name, err)
+			}
+			continue
+		}
+
+		entries, errs := ParseEntries(configMap.Data, proto)
+		for _, err := range errs {
+			IngressLog.Errorf("Skip item of configmap %s: %v", name, err)
+		}
+		for _, entry := range entries {
+			if entry.ServicePortName != "" {
+				servicePort, err := c.resolveNamedPort(entry)
+				if err != nil {
+					IngressLog.Errorf("Skip item %s of configmap %s: resolve service port error %v", entry, name, err)
+					continue
+				}
+				entry.ServicePort = servicePort
+			}
+			out = append(out, entry)
+		}
+	}
+
+	// Keep the order stable, tcp first.
+	sortEntries(out)
+	return out
+}
+
+func (c *controller) resolveNamedPort(entry *Entry) (uint32, error) {
+	service, err := c.serviceLister.Services(entry.Service.Namespace).Get(entry.Service.Name)
+	if err != nil {
+		return 0, err
+	}
+	for _, port := range service.Spec.Ports {
+		if port.Name == entry.ServicePortName {
+			return uint32(port.Port), nil
+		}
+	}
+	return 0, fmt.Errorf("service %s has no port named %s", entry.Service, entry.ServicePortName)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcpservices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+)
+
+func TestEntriesResolveNamedPort(t *testing.T) {
+	client := kube.NewFakeClient()
+	c := NewController(client, common.Options{
+		SystemNamespace:      "higress-system",
+		UDPServicesConfigMap: "udp-services",
+	}).(*controller)
+
+	configMaps := client.KubeInformer().Core().V1().ConfigMaps().Informer().GetStore()
+	if err := configMaps.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "higress-system", Name: "udp-services"},
+		Data: map[string]string{
+			"53":   "kube-system/dns:dns",
+			"5353": "kube-system/dns:mdns",
+		},
+	}); err != nil {
+		t.Fatalf("add configmap error %v", err)
+	}
+	// The service doesn't exist yet.
+	assert.Empty(t, c.Entries())
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "dns"},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "dns-tcp", Port: 53, Protocol: v1.ProtocolTCP},
+				{Name: "dns", Port: 1053, Protocol: v1.ProtocolUDP},
+			},
+		},
+	}
+	if err := c.serviceInformer.GetStore().Add(service); err != nil {
+		t.Fatalf("add service error %v", err)
+	}
+	assert.True(t, c.referencedByNamedPort(service))
+	assert.False(t, c.referencedByNamedPort(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dns"},
+	}))
+
+	// The entry referencing the missing port is skipped.
+	entries := c.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, uint32(53), entries[0].Port)
+	assert.Equal(t, uint32(1053), entries[0].ServicePort)
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcpservices
+
+import (
+	"fmt"
+	"strings"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	udpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/udp/udp_proxy/v3"
+	upstreamproxyprotocol "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/proxy_protocol/v3"
+	rawbuffer "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/raw_buffer/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	networking "istio.io/api/networking/v1alpha3"
+	networkingutil "istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/schema/gvk"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/gatewayoptions"
+	"github.com/alibaba/higress/pkg/ingress/kube/util"
+)
+
+// ResourceName is the name of the converted gateway, virtual service and envoy filter.
+var ResourceName = common.CreateConvertedName(constants.IstioIngressGatewayName, "tcp-udp-services")
+
+func serverName(entry *Entry, clusterId string) string {
+	return common.CreateConvertedName(fmt.Sprintf("%s-%d-services", strings.ToLower(string(entry.Protocol)), entry.Port), clusterId)
+}
+
+// udpClusterName differs from the outbound cluster name of istio, which skips the udp
+// service ports when building clusters.
+func udpClusterName(entry *Entry) string {
+	return fmt.Sprintf("udp|%d||%s", entry.ServicePort, util.CreateServiceFQDN(entry.Service.Namespace, entry.Service.Name))
+}
+
+// ConvertGateway converts the tcp entries to a gateway with a tcp server per entry.
+// The udp entries are served by the listeners of ConvertEnvoyFilter, as istio builds
+// the udp servers of gateway as tcp listeners.
+func ConvertGateway(entries []*Entry, options common.Options, namespace string) *config.Config {
+	gateway := &networking.Gateway{}
+	if options.GatewaySelectorKey != "" {
+		gateway.Selector = map[string]string{options.GatewaySelectorKey: options.GatewaySelectorValue}
+	}
+	for _, entry := range entries {
+		if entry.Protocol != protocol.TCP {
+			continue
+		}
+		gateway.Servers = append(gateway.Servers, &networking.Server{
+			Port: &networking.Port{
+				Number:   entry.Port,
+				Protocol: string(entry.Protocol),
+				Name:     serverName(entry, options.ClusterId),
+			},
+			Hosts: []string{"*"},
+		})
+	}
+	if len(gateway.Servers) == 0 {
+		return nil
+	}
+
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.Gateway,
+			Name:             ResourceName,
+			Namespace:        namespace,
+			Annotations: map[string]string{
+				common.ClusterIdAnnotation: options.ClusterId,
+			},
+		},
+		Spec: gateway,
+	}
+}
+
+// ConvertVirtualService converts the tcp entries to a virtual service with a tcp route per port.
+func ConvertVirtualService(entries []*Entry, options common.Options, namespace string) *config.Config {
+	virtualService := &networking.VirtualService{
+		Hosts:    []string{"*"},
+		Gateways: []string{namespace + "/" + ResourceName},
+	}
+	for _, entry := range entries {
+		if entry.Protocol != protocol.TCP {
+			continue
+		}
+
+		virtualService.Tcp = append(virtualService.Tcp, &networking.TCPRoute{
+			Match: []*networking.L4MatchAttributes{
+				{
+					Port: entry.Port,
+				},
+			},
+			Route: []*networking.RouteDestination{
+				{
+					Destination: &networking.Destination{
+						Host: util.CreateServiceFQDN(entry.Service.Namespace, entry.Service.Name),
+						Port: &networking.PortSelector{
+							Number: entry.ServicePort,
+						},
+					},
+				},
+			},
+		})
+	}
+	if len(virtualService.Tcp) == 0 {
+		return nil
+	}
+
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.VirtualService,
+			Name:             ResourceName,
+			Namespace:        namespace,
+			Annotations: map[string]string{
+				common.ClusterIdAnnotation: options.ClusterId,
+			},
+		},
+		Spec: virtualService,
+	}
+}
+
+// ConvertEnvoyFilter converts the entries to an envoy filter.
+// For tcp entries, the PROXY options enable the proxy protocol listener filter for the port
+// to decode the downstream PROXY header, and the proxy protocol transport socket for the
+// service port to send it to upstream.
+// For udp entries, a udp listener with the udp proxy filter is added for the port, which
+// forwards the datagrams to a cluster resolving the service by DNS.
+// The patches only apply to the gateway pods selected by the options, the same as the gateway.
+func ConvertEnvoyFilter(entries []*Entry, options common.Options, namespace string) (*config.Config, error) {
+	var configPatches []*networking.EnvoyFilter_EnvoyConfigObjectPatch
+	for _, entry := range entries {
+		if entry.Protocol == protocol.UDP {
+			patches, err := buildUDPProxyPatches(entry)
+			if err != nil {
+				return nil, err
+			}
+			configPatches = append(configPatches, patches...)
+			continue
+		}
+		if entry.Protocol != protocol.TCP {
+			continue
+		}
+
+		if entry.DecodeProxyProtocol {
+			patch, err := gatewayoptions.BuildProxyProtocolPatch(entry.Port)
+			if err != nil {
+				return nil, err
+			}
+			configPatches = append(configPatches, patch)
+		}
+
+		if entry.EncodeProxyProtocol {
+			patch, err := util.MessageToGoGoStruct(&clusterv3.Cluster{
+				TransportSocket: &corev3.TransportSocket{
+					Name: "envoy.transport_sockets.upstream_proxy_protocol",
+					ConfigType: &corev3.TransportSocket_TypedConfig{
+						TypedConfig: networkingutil.MessageToAny(&upstreamproxyprotocol.ProxyProtocolUpstreamTransport{
+							Config: &corev3.ProxyProtocolConfig{
+								Version: corev3.ProxyProtocolConfig_V1,
+							},
+							TransportSocket: &corev3.TransportSocket{
+								Name: wellknown.TransportSocketRawBuffer,
+								ConfigType: &corev3.TransportSocket_TypedConfig{
+									TypedConfig: networkingutil.MessageToAny(&rawbuffer.RawBuffer{}),
+								},
+							},
+						}),
+					},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			configPatches = append(configPatches, &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				ApplyTo: networking.EnvoyFilter_CLUSTER,
+				Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+					Context: networking.EnvoyFilter_GATEWAY,
+					ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+						Cluster: &networking.EnvoyFilter_ClusterMatch{
+							Service:    util.CreateServiceFQDN(entry.Service.Namespace, entry.Service.Name),
+							PortNumber: entry.ServicePort,
+						},
+					},
+				},
+				Patch: &networking.EnvoyFilter_Patch{
+					Operation: networking.EnvoyFilter_Patch_MERGE,
+					Value:     patch,
+				},
+			})
+		}
+	}
+
+	if len(configPatches) == 0 {
+		return nil, nil
+	}
+
+	envoyFilter := &networking.EnvoyFilter{
+		ConfigPatches: configPatches,
+	}
+	if options.GatewaySelectorKey != "" {
+		envoyFilter.WorkloadSelector = &networking.WorkloadSelector{
+			Labels: map[string]string{options.GatewaySelectorKey: options.GatewaySelectorValue},
+		}
+	}
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.EnvoyFilter,
+			Name:             ResourceName,
+			Namespace:        namespace,
+		},
+		Spec: envoyFilter,
+	}, nil
+}
+
+func buildUDPProxyPatches(entry *Entry) ([]*networking.EnvoyFilter_EnvoyConfigObjectPatch, error) {
+	name := serverName(entry, "")
+	clusterName := udpClusterName(entry)
+	listener, err := util.MessageToGoGoStruct(&listenerv3.Listener{
+		Name: name,
+		Address: &corev3.Address{
+			Address: &corev3.Address_SocketAddress{
+				SocketAddress: &corev3.SocketAddress{
+					Protocol: corev3.SocketAddress_UDP,
+					Address:  "0.0.0.0",
+					PortSpecifier: &corev3.SocketAddress_PortValue{
+						PortValue: entry.Port,
+					},
+				},
+			},
+		},
+		UdpListenerConfig: &listenerv3.UdpListenerConfig{},
+		ListenerFilters: []*listenerv3.ListenerFilter{
+			{
+				Name: "envoy.filters.udp_listener.udp_proxy",
+				ConfigType: &listenerv3.ListenerFilter_TypedConfig{
+					TypedConfig: networkingutil.MessageToAny(&udpproxy.UdpProxyConfig{
+						StatPrefix: name,
+						RouteSpecifier: &udpproxy.UdpProxyConfig_Cluster{
+							Cluster: clusterName,
+						},
+					}),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := util.MessageToGoGoStruct(&clusterv3.Cluster{
+		Name: clusterName,
+		ClusterDiscoveryType: &clusterv3.Cluster_Type{
+			Type: clusterv3.Cluster_STRICT_DNS,
+		},
+		LoadAssignment: &endpointv3.ClusterLoadAssignment{
+			ClusterName: clusterName,
+			Endpoints: []*endpointv3.LocalityLbEndpoints{
+				{
+					LbEndpoints: []*endpointv3.LbEndpoint{
+						{
+							HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
+								Endpoint: &endpointv3.Endpoint{
+									Address: &corev3.Address{
+										Address: &corev3.Address_SocketAddress{
+											SocketAddress: &corev3.SocketAddress{
+												Address: util.CreateServiceFQDN(entry.Service.Namespace, entry.Service.Name),
+												PortSpecifier: &corev3.SocketAddress_PortValue{
+													PortValue: entry.ServicePort,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: networking.EnvoyFilter_LISTENER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_GATEWAY,
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_ADD,
+				Value:     listener,
+			},
+		},
+		{
+			ApplyTo: networking.EnvoyFilter_CLUSTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_GATEWAY,
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_ADD,
+				Value:     cluster,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcpservices
+
+import (
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	udpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/udp/udp_proxy/v3"
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/xds"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+)
+
+var testEntries = []*Entry{
+	{
+		Port:                3306,
+		Protocol:            protocol.TCP,
+		Service:             types.NamespacedName{Namespace: "db", Name: "mysql"},
+		ServicePort:         3306,
+		DecodeProxyProtocol: true,
+	},
+	{
+		Port:        53,
+		Protocol:    protocol.UDP,
+		Service:     types.NamespacedName{Namespace: "kube-system", Name: "dns"},
+		ServicePort: 53,
+	},
+}
+
+func TestConvertGateway(t *testing.T) {
+	assert.Nil(t, ConvertGateway(nil, common.Options{}, "higress-system"))
+	assert.Nil(t, ConvertGateway(testEntries[1:], common.Options{}, "higress-system"))
+
+	options := common.Options{
+		GatewaySelectorKey:   "higress",
+		GatewaySelectorValue: "higress-system-higress-gateway",
+	}
+	gateway := ConvertGateway(testEntries, options, "higress-system")
+	assert.Equal(t, ResourceName, gateway.Name)
+	assert.Equal(t, "higress-system", gateway.Namespace)
+	assert.Equal(t, &networking.Gateway{
+		Selector: map[string]string{"higress": "higress-system-higress-gateway"},
+		Servers: []*networking.Server{
+			{
+				Port: &networking.Port{
+					Number:   3306,
+					Protocol: "TCP",
+					Name:     "tcp-3306-services",
+				},
+				Hosts: []string{"*"},
+			},
+		},
+	}, gateway.Spec)
+}
+
+func TestConvertVirtualService(t *testing.T) {
+	assert.Nil(t, ConvertVirtualService(nil, common.Options{}, "higress-system"))
+	assert.Nil(t, ConvertVirtualService(testEntries[1:], common.Options{}, "higress-system"))
+
+	virtualService := ConvertVirtualService(testEntries, common.Options{}, "higress-system")
+	assert.Equal(t, &networking.VirtualService{
+		Hosts:    []string{"*"},
+		Gateways: []string{"higress-system/" + ResourceName},
+		Tcp: []*networking.TCPRoute{
+			{
+				Match: []*networking.L4MatchAttributes{{Port: 3306}},
+				Route: []*networking.RouteDestination{
+					{
+						Destination: &networking.Destination{
+							Host: "mysql.db.svc.cluster.local",
+							Port: &networking.PortSelector{Number: 3306},
+						},
+					},
+				},
+			},
+		},
+	}, virtualService.Spec)
+}
+
+func TestConvertEnvoyFilter(t *testing.T) {
+	options := common.Options{
+		GatewaySelectorKey:   "higress",
+		GatewaySelectorValue: "higress-system-higress-gateway",
+	}
+	envoyFilter, err := ConvertEnvoyFilter(nil, options, "higress-system")
+	assert.NoError(t, err)
+	assert.Nil(t, envoyFilter)
+
+	envoyFilter, err = ConvertEnvoyFilter(testEntries, common.Options{}, "higress-system")
+	assert.NoError(t, err)
+	assert.Nil(t, envoyFilter.Spec.(*networking.EnvoyFilter).WorkloadSelector)
+
+	// The patches only apply to the gateways of the tcp services.
+	envoyFilter, err = ConvertEnvoyFilter(testEntries, options, "higress-system")
+	assert.NoError(t, err)
+	assert.Equal(t, &networking.WorkloadSelector{Labels: map[string]string{"higress": "higress-system-higress-gateway"}},
+		envoyFilter.Spec.(*networking.EnvoyFilter).WorkloadSelector)
+	patches := envoyFilter.Spec.(*networking.EnvoyFilter).ConfigPatches
+	assert.Len(t, patches, 3)
+	assert.Equal(t, networking.EnvoyFilter_LISTENER, patches[0].ApplyTo)
+	assert.Equal(t, uint32(3306), patches[0].Match.GetListener().PortNumber)
+	assert.Equal(t, "envoy.filters.listener.proxy_protocol",
+		patches[0].Patch.Value.Fields["listener_filters"].GetListValue().Values[0].GetStructValue().Fields["name"].GetStringValue())
+
+	assert.Equal(t, networking.EnvoyFilter_LISTENER, patches[1].ApplyTo)
+	assert.Equal(t, networking.EnvoyFilter_Patch_ADD, patches[1].Patch.Operation)
+	message, err := xds.BuildXDSObjectFromStruct(patches[1].ApplyTo, patches[1].Patch.Value, true)
+	assert.NoError(t, err)
+	listener := message.(*listenerv3.Listener)
+	assert.Equal(t, "udp-53-services", listener.Name)
+	assert.Equal(t, corev3.SocketAddress_UDP, listener.Address.GetSocketAddress().Protocol)
+	assert.Equal(t, uint32(53), listener.Address.GetSocketAddress().GetPortValue())
+	assert.NotNil(t, listener.UdpListenerConfig)
+	proxyConfig := &udpproxy.UdpProxyConfig{}
+	assert.NoError(t, listener.ListenerFilters[0].GetTypedConfig().UnmarshalTo(proxyConfig))
+	assert.Equal(t, "udp|53||dns.kube-system.svc.cluster.local", proxyConfig.GetCluster())
+
+	assert.Equal(t, networking.EnvoyFilter_CLUSTER, patches[2].ApplyTo)
+	assert.Equal(t, networking.EnvoyFilter_Patch_ADD, patches[2].Patch.Operation)
+	message, err = xds.BuildXDSObjectFromStruct(patches[2].ApplyTo, patches[2].Patch.Value, true)
+	assert.NoError(t, err)
+	cluster := message.(*clusterv3.Cluster)
+	assert.Equal(t, "udp|53||dns.kube-system.svc.cluster.local", cluster.Name)
+	assert.Equal(t, clusterv3.Cluster_STRICT_DNS, cluster.GetType())
+	address := cluster.LoadAssignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress()
+	assert.Equal(t, "dns.kube-system.svc.cluster.local", address.Address)
+	assert.Equal(t, uint32(53), address.GetPortValue())
+}
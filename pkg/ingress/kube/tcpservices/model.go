@@ -0,0 +1,148 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcpservices
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"istio.io/istio/pkg/config/protocol"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const proxyProtocol = "PROXY"
+
+// Entry is an item of the tcp-services or udp-services ConfigMap, which is in
+// the form of `port: namespace/service:port[:PROXY][:PROXY]`, the same as ingress-nginx.
+// The service port can be a number or the name of a service port.
+type Entry struct {
+	Port uint32
+
+	Protocol protocol.Instance
+
+	Service types.NamespacedName
+
+	ServicePort uint32
+
+	// ServicePortName is the name of the service port, the controller resolves it to ServicePort.
+	ServicePortName string
+
+	// DecodeProxyProtocol indicates the downstream connection carries the PROXY protocol header.
+	DecodeProxyProtocol bool
+
+	// EncodeProxyProtocol indicates the PROXY protocol header should be sent to upstream.
+	EncodeProxyProtocol bool
+}
+
+func (e *Entry) String() string {
+	if e.ServicePortName != "" && e.ServicePort == 0 {
+		return fmt.Sprintf("%s %d -> %s:%s", e.Protocol, e.Port, e.Service, e.ServicePortName)
+	}
+	return fmt.Sprintf("%s %d -> %s:%d", e.Protocol, e.Port, e.Service, e.ServicePort)
+}
+
+// ParseEntries parses the data of ConfigMap, the entries are sorted by port.
+// The invalid items are skipped and returned as errors.
+func ParseEntries(data map[string]string, proto protocol.Instance) ([]*Entry, []error) {
+	var entries []*Entry
+	var errs []error
+	for key, value := range data {
+		entry, err := parseEntry(key, value, proto)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sortEntries(entries)
+	return entries, errs
+}
+
+// sortEntries sorts the entries by protocol, tcp first, then by port.
+func sortEntries(entries []*Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Protocol != entries[j].Protocol {
+			return entries[i].Protocol == protocol.TCP
+		}
+		return entries[i].Port < entries[j].Port
+	})
+}
+
+func parseEntry(key, value string, proto protocol.Instance) (*Entry, error) {
+	port, err := parsePort(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s port %q: %v", proto, key, err)
+	}
+
+	items := strings.Split(strings.TrimSpace(value), ":")
+	if len(items) < 2 || len(items) > 4 {
+		return nil, fmt.Errorf("invalid %s service %q of port %d", proto, value, port)
+	}
+
+	namespacedName := strings.Split(items[0], "/")
+	if len(namespacedName) != 2 || namespacedName[0] == "" || namespacedName[1] == "" {
+		return nil, fmt.Errorf("invalid %s service %q of port %d, it should be namespace/name", proto, value, port)
+	}
+
+	entry := &Entry{
+		Port:     port,
+		Protocol: proto,
+		Service: types.NamespacedName{
+			Namespace: namespacedName[0],
+			Name:      namespacedName[1],
+		},
+	}
+	if _, err := strconv.Atoi(items[1]); err == nil {
+		if entry.ServicePort, err = parsePort(items[1]); err != nil {
+			return nil, fmt.Errorf("invalid %s service port %q of port %d: %v", proto, items[1], port, err)
+		}
+	} else {
+		if errs := validation.IsValidPortName(items[1]); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s service port %q of port %d: %s", proto, items[1], port, strings.Join(errs, ", "))
+		}
+		entry.ServicePortName = items[1]
+	}
+
+	for idx, item := range items[2:] {
+		if item != proxyProtocol {
+			return nil, fmt.Errorf("invalid %s service %q of port %d, only PROXY is supported", proto, value, port)
+		}
+		if proto != protocol.TCP {
+			return nil, fmt.Errorf("invalid %s service %q of port %d, PROXY is only supported by tcp services", proto, value, port)
+		}
+		if idx == 0 {
+			entry.DecodeProxyProtocol = true
+		} else {
+			entry.EncodeProxyProtocol = true
+		}
+	}
+
+	return entry, nil
+}
+
+func parsePort(raw string) (uint32, error) {
+	port, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	if port == 0 {
+		return 0, fmt.Errorf("port should be greater than 0")
+	}
+	return uint32(port), nil
+}
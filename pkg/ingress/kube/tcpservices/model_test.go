@@ -0,0 +1,103 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcpservices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/config/protocol"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseEntries(t *testing.T) {
+	entries, errs := ParseEntries(map[string]string{
+		"9000": "default/example-go:8080",
+		"3306": " db/mysql:3306:PROXY ",
+		"1883": "mq/mqtt:1883:PROXY:PROXY",
+		"0":    "default/zero:80",
+		"abc":  "default/abc:80",
+		"6379": "redis:6379",
+		"8001": "default/foo:80:SSL",
+		"8002": "default/bar:70000",
+		"8003": "default/bar:HTTP_PORT",
+	}, protocol.TCP)
+
+	assert.Len(t, errs, 6)
+	assert.Equal(t, []*Entry{
+		{
+			Port:                1883,
+			Protocol:            protocol.TCP,
+			Service:             types.NamespacedName{Namespace: "mq", Name: "mqtt"},
+			ServicePort:         1883,
+			DecodeProxyProtocol: true,
+			EncodeProxyProtocol: true,
+		},
+		{
+			Port:                3306,
+			Protocol:            protocol.TCP,
+			Service:             types.NamespacedName{Namespace: "db", Name: "mysql"},
+			ServicePort:         3306,
+			DecodeProxyProtocol: true,
+		},
+		{
+			Port:        9000,
+			Protocol:    protocol.TCP,
+			Service:     types.NamespacedName{Namespace: "default", Name: "example-go"},
+			ServicePort: 8080,
+		},
+	}, entries)
+}
+
+func TestParseEntriesNamedPort(t *testing.T) {
+	entries, errs := ParseEntries(map[string]string{
+		"8000": "default/named:http",
+	}, protocol.TCP)
+	assert.Empty(t, errs)
+	assert.Equal(t, []*Entry{
+		{
+			Port:            8000,
+			Protocol:        protocol.TCP,
+			Service:         types.NamespacedName{Namespace: "default", Name: "named"},
+			ServicePortName: "http",
+		},
+	}, entries)
+}
+
+func TestParseEntriesUDPProxyProtocol(t *testing.T) {
+	entries, errs := ParseEntries(map[string]string{
+		"53":   "kube-system/dns:53",
+		"5353": "kube-system/dns:53:PROXY",
+	}, protocol.UDP)
+	assert.Len(t, errs, 1)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, uint32(53), entries[0].Port)
+}
+
+func TestSortEntries(t *testing.T) {
+	entries := []*Entry{
+		{Port: 53, Protocol: protocol.UDP},
+		{Port: 9000, Protocol: protocol.TCP},
+		{Port: 5353, Protocol: protocol.UDP},
+		{Port: 53, Protocol: protocol.TCP},
+	}
+	sortEntries(entries)
+	assert.Equal(t, []*Entry{
+		{Port: 53, Protocol: protocol.TCP},
+		{Port: 9000, Protocol: protocol.TCP},
+		{Port: 53, Protocol: protocol.UDP},
+		{Port: 5353, Protocol: protocol.UDP},
+	}, entries)
+}
@@ -29,12 +29,14 @@ import (
 
 type VirtualServiceGenerator struct {
 	Server *xds.DiscoveryServer
+	// Scopes is nil if all the proxies receive all the virtual services.
+	Scopes *GatewayScopes
 }
 
 func (c VirtualServiceGenerator) Generate(proxy *model.Proxy, push *model.PushContext, w *model.WatchedResource,
 	updates *model.PushRequest) ([]*any.Any, model.XdsLogDetails, error) {
 	resources := make([]*any.Any, 0)
-	configs := push.AllVirtualServices
+	configs := c.Scopes.VirtualServices(proxy, push)
 	for _, config := range configs {
 		body, err := types.MarshalAny(config.Spec.(*networking.VirtualService))
 		if err != nil {
@@ -146,12 +148,14 @@ func (c EnvoyFilterGenerator) GenerateDeltas(proxy *model.Proxy, push *model.Pus
 
 type GatewayGenerator struct {
 	Server *xds.DiscoveryServer
+	// Scopes is nil if all the proxies receive all the gateways.
+	Scopes *GatewayScopes
 }
 
 func (c GatewayGenerator) Generate(proxy *model.Proxy, push *model.PushContext, w *model.WatchedResource,
 	updates *model.PushRequest) ([]*any.Any, model.XdsLogDetails, error) {
 	resources := make([]*any.Any, 0)
-	configs := push.AllGateways
+	configs := c.Scopes.Gateways(proxy, push)
 	for _, config := range configs {
 		body, err := types.MarshalAny(config.Spec.(*networking.Gateway))
 		if err != nil {
@@ -221,3 +225,43 @@ func (c WasmpluginGenerator) GenerateDeltas(proxy *model.Proxy, push *model.Push
 	// TODO: delta implement
 	return nil, nil, model.DefaultXdsLogDetails, false, nil
 }
+
+type ServiceEntryGenerator struct {
+	Server *xds.DiscoveryServer
+}
+
+func (c ServiceEntryGenerator) Generate(proxy *model.Proxy, push *model.PushContext, w *model.WatchedResource,
+	updates *model.PushRequest) ([]*any.Any, model.XdsLogDetails, error) {
+	resources := make([]*any.Any, 0)
+	// The service entries are not indexed by the push context in mcp mode, so read them from the config store.
+	configs := c.Server.Env.ServiceEntries()
+	for _, config := range configs {
+		body, err := types.MarshalAny(config.Spec.(*networking.ServiceEntry))
+		if err != nil {
+			return nil, model.DefaultXdsLogDetails, err
+		}
+		createTime, err := types.TimestampProto(config.CreationTimestamp)
+		if err != nil {
+			return nil, model.DefaultXdsLogDetails, err
+		}
+		resource := &mcp.Resource{
+			Body: body,
+			Metadata: &mcp.Metadata{
+				Name:       path.Join(config.Namespace, config.Name),
+				CreateTime: createTime,
+			},
+		}
+		mcpAny, err := ptypes.MarshalAny(resource)
+		if err != nil {
+			return nil, model.DefaultXdsLogDetails, err
+		}
+		resources = append(resources, mcpAny)
+	}
+	return resources, model.DefaultXdsLogDetails, nil
+}
+
+func (c ServiceEntryGenerator) GenerateDeltas(proxy *model.Proxy, push *model.PushContext, updates *model.PushRequest,
+	w *model.WatchedResource) ([]*any.Any, []string, model.XdsLogDetails, bool, error) {
+	// TODO: delta implement
+	return nil, nil, model.DefaultXdsLogDetails, false, nil
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"strings"
+	"sync"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// GatewaySelectorAnnotation declares the gateway served by the mcp client, in the form of the labels
+// of the gateway pods, e.g. higress=higress-system-higress-gateway. It's read from the metadata of
+// the node, or else the annotations of the pod of the client in the namespace of the controller,
+// e.g. the istiod of the gateway. The clients without it receive the configs of all the gateways.
+const GatewaySelectorAnnotation = "higress.io/gateway-selector"
+
+// GatewayScopes scopes the gateways and virtual services to the mcp clients by the gateways they
+// serve, so that the clients of the different gateway deployments only receive and are only pushed
+// the configs targeted at their gateways.
+type GatewayScopes struct {
+	// pods watches the metadata of the pods in the namespace, to resolve the annotations of the pods
+	// of the clients without looking them up on the push path. It's nil if the metadata client is
+	// unavailable, e.g. in the file mode.
+	pods      informers.GenericInformer
+	namespace string
+	// push triggers a full push, it's called when the annotation of the pod of a proxy changes.
+	push func()
+	// connectedProxies returns the ids of the connected proxies, the scopes of the others are pruned.
+	connectedProxies func() sets.Set
+
+	mutex sync.Mutex
+	// key: proxy id
+	scopes map[string]*gatewayScope
+}
+
+type gatewayScope struct {
+	mutex sync.Mutex
+	// value is the annotation the selector is parsed from, and fromNode is true if it's read from
+	// the metadata of the node rather than the pod.
+	value    string
+	fromNode bool
+	resolved bool
+	// selector is nil if the proxy receives the configs of all the gateways.
+	selector labels.Set
+	// sent are the gateways and virtual services generated for the proxy last time, which are
+	// pushed to the proxy when they are changed even if they are targeted at the other gateways now.
+	sent map[config.GroupVersionKind]map[model.ConfigKey]struct{}
+}
+
+// NewGatewayScopes watches the pods of the clients in the namespace, which is the only namespace the
+// controller is allowed to read the pods from.
+func NewGatewayScopes(client kube.Client, server *xds.DiscoveryServer, namespace string) *GatewayScopes {
+	g := &GatewayScopes{
+		connectedProxies: func() sets.Set {
+			ids := sets.NewSet()
+			for _, con := range server.AllClients() {
+				// The connection id is the proxy id suffixed by the connection number.
+				if idx := strings.LastIndex(con.ConID, "-"); idx > 0 {
+					ids.Insert(con.ConID[:idx])
+				}
+			}
+			return ids
+		},
+		scopes: map[string]*gatewayScope{},
+	}
+	if server != nil {
+		g.push = func() {
+			server.ConfigUpdate(&model.PushRequest{
+				Full:   true,
+				Reason: []model.TriggerReason{model.ProxyUpdate},
+			})
+		}
+	}
+	if client != nil && client.Metadata() != nil {
+		factory := metadatainformer.NewFilteredSharedInformerFactory(client.Metadata(), 0, namespace, nil)
+		g.pods = factory.ForResource(v1.SchemeGroupVersion.WithResource("pods"))
+		g.pods.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: g.onPod,
+			UpdateFunc: func(_, obj interface{}) {
+				g.onPod(obj)
+			},
+		})
+		g.namespace = namespace
+	}
+	return g
+}
+
+// Run watches the pods of the clients until the stop channel is closed.
+func (g *GatewayScopes) Run(stop <-chan struct{}) {
+	if g.pods == nil {
+		return
+	}
+	g.pods.Informer().Run(stop)
+}
+
+// ProxyNeedsPush skips the full pushes of the gateways and virtual services which are targeted at
+// the other gateways, both before and after the changes.
+func (g *GatewayScopes) ProxyNeedsPush(proxy *model.Proxy, req *model.PushRequest) bool {
+	if !req.Full || len(req.ConfigsUpdated) == 0 || req.Push == nil {
+		return true
+	}
+	scope, selector := g.scopeOf(proxy)
+	if selector == nil {
+		return true
+	}
+
+	var filter *gatewayFilter
+	for key := range req.ConfigsUpdated {
+		if key.Kind != gvk.Gateway && key.Kind != gvk.VirtualService {
+			return true
+		}
+		if scope.wasSent(key) {
+			return true
+		}
+		if filter == nil {
+			filter = newGatewayFilter(req.Push, selector)
+		}
+		if key.Kind == gvk.Gateway && filter.isIncluded(configName(key.Namespace, key.Name)) {
+			return true
+		}
+		if key.Kind == gvk.VirtualService {
+			for idx := range req.Push.AllVirtualServices {
+				cfg := &req.Push.AllVirtualServices[idx]
+				if cfg.Name == key.Name && cfg.Namespace == key.Namespace && !filter.isExcludedVirtualService(cfg) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Gateways returns the gateways of the push context targeted at the gateway served by the proxy.
+func (g *GatewayScopes) Gateways(proxy *model.Proxy, push *model.PushContext) []config.Config {
+	if g == nil {
+		return push.AllGateways
+	}
+	scope, selector := g.scopeOf(proxy)
+	if selector == nil {
+		return push.AllGateways
+	}
+
+	filter := newGatewayFilter(push, selector)
+	out := make([]config.Config, 0, len(push.AllGateways))
+	for _, cfg := range push.AllGateways {
+		if !filter.excluded.Contains(configName(cfg.Namespace, cfg.Name)) {
+			out = append(out, cfg)
+		}
+	}
+	scope.recordSent(gvk.Gateway, out)
+	return out
+}
+
+// VirtualServices returns the virtual services of the push context, except the ones only bound to
+// the gateways targeted at the others than the gateway served by the proxy.
+func (g *GatewayScopes) VirtualServices(proxy *model.Proxy, push *model.PushContext) []config.Config {
+	if g == nil {
+		return push.AllVirtualServices
+	}
+	scope, selector := g.scopeOf(proxy)
+	if selector == nil {
+		return push.AllVirtualServices
+	}
+
+	filter := newGatewayFilter(push, selector)
+	out := make([]config.Config, 0, len(push.AllVirtualServices))
+	for idx := range push.AllVirtualServices {
+		cfg := &push.AllVirtualServices[idx]
+		if !filter.isExcludedVirtualService(cfg) {
+			out = append(out, *cfg)
+		}
+	}
+	scope.recordSent(gvk.VirtualService, out)
+	return out
+}
+
+// scopeOf returns the scope of the proxy along with the labels of the gateway it serves, which are
+// nil if the proxy receives the configs of all the gateways. The annotation is read again on every
+// call, so that the proxies whose pods are not known yet or annotated later are scoped once they are.
+func (g *GatewayScopes) scopeOf(proxy *model.Proxy) (*gatewayScope, labels.Set) {
+	value, fromNode := g.annotationOf(proxy)
+
+	g.mutex.Lock()
+	scope, exist := g.scopes[proxy.ID]
+	if !exist {
+		if g.connectedProxies != nil {
+			connected := g.connectedProxies()
+			for id := range g.scopes {
+				if !connected.Contains(id) {
+					delete(g.scopes, id)
+				}
+			}
+		}
+		scope = &gatewayScope{sent: map[config.GroupVersionKind]map[model.ConfigKey]struct{}{}}
+		g.scopes[proxy.ID] = scope
+	}
+	g.mutex.Unlock()
+	return scope, scope.resolve(proxy.ID, value, fromNode)
+}
+
+// annotationOf returns the gateway selector annotation of the node metadata of the proxy, or else
+// the one of its pod in the informer cache.
+func (g *GatewayScopes) annotationOf(proxy *model.Proxy) (string, bool) {
+	if proxy.Metadata != nil {
+		if value := proxy.Metadata.Annotations[GatewaySelectorAnnotation]; value != "" {
+			return value, true
+		}
+	}
+	if g.pods == nil || proxy.ConfigNamespace != g.namespace {
+		return "", false
+	}
+	name := strings.TrimSuffix(proxy.ID, "."+proxy.ConfigNamespace)
+	obj, err := g.pods.Lister().ByNamespace(proxy.ConfigNamespace).Get(name)
+	if err != nil {
+		return "", false
+	}
+	pod, err := meta.Accessor(obj)
+	if err != nil {
+		return "", false
+	}
+	return pod.GetAnnotations()[GatewaySelectorAnnotation], false
+}
+
+// onPod pushes the proxies again if the annotation of the pod of a proxy is changed from the one
+// its scope was resolved with.
+func (g *GatewayScopes) onPod(obj interface{}) {
+	pod, err := meta.Accessor(obj)
+	if err != nil || g.push == nil {
+		return
+	}
+	g.mutex.Lock()
+	scope, exist := g.scopes[pod.GetName()+"."+pod.GetNamespace()]
+	g.mutex.Unlock()
+	if !exist {
+		return
+	}
+
+	scope.mutex.Lock()
+	changed := scope.resolved && !scope.fromNode && scope.value != pod.GetAnnotations()[GatewaySelectorAnnotation]
+	scope.mutex.Unlock()
+	if changed {
+		g.push()
+	}
+}
+
+// resolve returns the labels of the gateway declared by the annotation, which are parsed again only
+// if the annotation changes.
+func (s *gatewayScope) resolve(id, value string, fromNode bool) labels.Set {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fromNode = fromNode
+	if s.resolved && s.value == value {
+		return s.selector
+	}
+	s.value, s.resolved, s.selector = value, true, nil
+	if value == "" {
+		return nil
+	}
+
+	selector, err := labels.ConvertSelectorToLabelsMap(value)
+	if err != nil || len(selector) == 0 {
+		IngressLog.Errorf("Invalid gateway selector %q of mcp client %s, it receives the configs of all the gateways",
+			value, id)
+		return nil
+	}
+	IngressLog.Infof("Mcp client %s serves the gateway %s", id, selector)
+	s.selector = selector
+	return selector
+}
+
+// gatewayFilter holds the names of the gateways of the push context, and the ones among them whose
+// selectors don't match the gateway served by the proxy.
+type gatewayFilter struct {
+	known    sets.Set
+	excluded sets.Set
+}
+
+// newGatewayFilter excludes the gateways whose selectors don't match the gateway served by the proxy,
+// the gateways without selector apply to all of them.
+func newGatewayFilter(push *model.PushContext, selector labels.Set) *gatewayFilter {
+	filter := &gatewayFilter{known: sets.NewSet(), excluded: sets.NewSet()}
+	for _, cfg := range push.AllGateways {
+		name := configName(cfg.Namespace, cfg.Name)
+		filter.known.Insert(name)
+		gateway, ok := cfg.Spec.(*networking.Gateway)
+		if !ok || len(gateway.Selector) == 0 {
+			continue
+		}
+		if !labels.SelectorFromSet(gateway.Selector).Matches(selector) {
+			filter.excluded.Insert(name)
+		}
+	}
+	return filter
+}
+
+func (f *gatewayFilter) isIncluded(name string) bool {
+	return f.known.Contains(name) && !f.excluded.Contains(name)
+}
+
+func (s *gatewayScope) recordSent(kind config.GroupVersionKind, configs []config.Config) {
+	sent := make(map[model.ConfigKey]struct{}, len(configs))
+	for _, cfg := range configs {
+		sent[model.ConfigKey{Kind: kind, Name: cfg.Name, Namespace: cfg.Namespace}] = struct{}{}
+	}
+	s.mutex.Lock()
+	s.sent[kind] = sent
+	s.mutex.Unlock()
+}
+
+func (s *gatewayScope) wasSent(key model.ConfigKey) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, exist := s.sent[key.Kind][key]
+	return exist
+}
+
+// isExcludedVirtualService returns true if the virtual service is bound to the excluded gateways,
+// but none of the included ones or the mesh. The gateways unknown to the push context are ignored,
+// e.g. the <cluster id>-<host> and global gateways which the converted virtual services are bound
+// to along with the generated ones. The virtual services only bound to the unknown gateways are kept.
+func (f *gatewayFilter) isExcludedVirtualService(cfg *config.Config) bool {
+	virtualService, ok := cfg.Spec.(*networking.VirtualService)
+	if !ok || len(virtualService.Gateways) == 0 {
+		return false
+	}
+	var excluded bool
+	for _, gateway := range virtualService.Gateways {
+		if gateway == constants.IstioMeshGateway {
+			return false
+		}
+		name := gateway
+		if !strings.Contains(gateway, "/") {
+			name = configName(cfg.Namespace, gateway)
+		}
+		if f.excluded.Contains(name) {
+			excluded = true
+		} else if f.known.Contains(name) {
+			return false
+		}
+	}
+	return excluded
+}
+
+func configName(namespace, name string) string {
+	return namespace + "/" + name
+}
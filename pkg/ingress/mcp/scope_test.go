@@ -0,0 +1,312 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	ingress "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	ingressconfig "github.com/alibaba/higress/pkg/ingress/config"
+	"github.com/alibaba/higress/pkg/ingress/kube/common"
+	"github.com/alibaba/higress/pkg/ingress/kube/file"
+)
+
+func newTestGateway(name string, selector map[string]string) config.Config {
+	return config.Config{
+		Meta: config.Meta{GroupVersionKind: gvk.Gateway, Name: name, Namespace: "higress-system"},
+		Spec: &networking.Gateway{Selector: selector},
+	}
+}
+
+func newTestVirtualService(name string, gateways ...string) config.Config {
+	return config.Config{
+		Meta: config.Meta{GroupVersionKind: gvk.VirtualService, Name: name, Namespace: "higress-system"},
+		Spec: &networking.VirtualService{Gateways: gateways},
+	}
+}
+
+func newTestPushContext(gateways, virtualServices []config.Config) *model.PushContext {
+	push := model.NewPushContext()
+	push.AllGateways = gateways
+	push.AllVirtualServices = virtualServices
+	return push
+}
+
+func newTestProxy(id string, annotations map[string]string) *model.Proxy {
+	return &model.Proxy{
+		ID:              id + ".higress-system",
+		ConfigNamespace: "higress-system",
+		Metadata:        &model.NodeMetadata{Annotations: annotations},
+	}
+}
+
+func names(configs []config.Config) []string {
+	out := make([]string, 0, len(configs))
+	for _, cfg := range configs {
+		out = append(out, cfg.Name)
+	}
+	return out
+}
+
+func TestScopedConfigs(t *testing.T) {
+	scopes := NewGatewayScopes(nil, nil, "")
+	scopes.connectedProxies = nil
+	push := newTestPushContext([]config.Config{
+		newTestGateway("public", map[string]string{"higress": "public-gateway"}),
+		newTestGateway("internal", map[string]string{"higress": "internal-gateway"}),
+		newTestGateway("all", nil),
+	}, []config.Config{
+		newTestVirtualService("public", "higress-system/public"),
+		newTestVirtualService("internal", "internal"),
+		newTestVirtualService("both", "higress-system/public", "higress-system/internal"),
+		newTestVirtualService("external", "istio-system/external"),
+		newTestVirtualService("mesh"),
+	})
+
+	internal := newTestProxy("internal", map[string]string{GatewaySelectorAnnotation: "higress=internal-gateway"})
+	assert.Equal(t, []string{"internal", "all"}, names(scopes.Gateways(internal, push)))
+	assert.Equal(t, []string{"internal", "both", "external", "mesh"}, names(scopes.VirtualServices(internal, push)))
+
+	// The proxies without the gateway selector receive all the configs.
+	unscoped := newTestProxy("unscoped", nil)
+	assert.Equal(t, []string{"public", "internal", "all"}, names(scopes.Gateways(unscoped, push)))
+	assert.Equal(t, 5, len(scopes.VirtualServices(unscoped, push)))
+
+	invalid := newTestProxy("invalid", map[string]string{GatewaySelectorAnnotation: "higress=a=b"})
+	assert.Equal(t, 3, len(scopes.Gateways(invalid, push)))
+
+	var nilScopes *GatewayScopes
+	assert.Equal(t, 3, len(nilScopes.Gateways(internal, push)))
+	assert.Equal(t, 5, len(nilScopes.VirtualServices(internal, push)))
+}
+
+func TestProxyNeedsPush(t *testing.T) {
+	scopes := NewGatewayScopes(nil, nil, "")
+	scopes.connectedProxies = nil
+	push := newTestPushContext([]config.Config{
+		newTestGateway("public", map[string]string{"higress": "public-gateway"}),
+		newTestGateway("internal", map[string]string{"higress": "internal-gateway"}),
+	}, []config.Config{
+		newTestVirtualService("public", "public"),
+		newTestVirtualService("internal", "internal"),
+	})
+	internal := newTestProxy("internal", map[string]string{GatewaySelectorAnnotation: "higress=internal-gateway"})
+	scopes.Gateways(internal, push)
+	scopes.VirtualServices(internal, push)
+
+	request := func(kind config.GroupVersionKind, name string) *model.PushRequest {
+		return &model.PushRequest{
+			Full:           true,
+			Push:           push,
+			ConfigsUpdated: map[model.ConfigKey]struct{}{{Kind: kind, Name: name, Namespace: "higress-system"}: {}},
+		}
+	}
+	assert.True(t, scopes.ProxyNeedsPush(internal, request(gvk.Gateway, "internal")))
+	assert.True(t, scopes.ProxyNeedsPush(internal, request(gvk.VirtualService, "internal")))
+	assert.False(t, scopes.ProxyNeedsPush(internal, request(gvk.Gateway, "public")))
+	assert.False(t, scopes.ProxyNeedsPush(internal, request(gvk.VirtualService, "public")))
+	assert.False(t, scopes.ProxyNeedsPush(internal, request(gvk.VirtualService, "deleted")))
+	assert.True(t, scopes.ProxyNeedsPush(internal, request(gvk.DestinationRule, "public")))
+	assert.True(t, scopes.ProxyNeedsPush(internal, &model.PushRequest{Full: true, Push: push}))
+	assert.True(t, scopes.ProxyNeedsPush(newTestProxy("unscoped", nil), request(gvk.Gateway, "public")))
+
+	// The gateway moved to the other gateway deployment is pushed to the proxy which received it.
+	moved := newTestPushContext([]config.Config{
+		newTestGateway("public", map[string]string{"higress": "public-gateway"}),
+		newTestGateway("internal", map[string]string{"higress": "public-gateway"}),
+	}, push.AllVirtualServices)
+	req := request(gvk.Gateway, "internal")
+	req.Push = moved
+	assert.True(t, scopes.ProxyNeedsPush(internal, req))
+	assert.Empty(t, scopes.Gateways(internal, moved))
+	assert.Empty(t, scopes.VirtualServices(internal, moved))
+	assert.False(t, scopes.ProxyNeedsPush(internal, req))
+
+	// And the gateway moved to the gateway deployment of the proxy as well.
+	req = request(gvk.Gateway, "public")
+	req.Push = newTestPushContext([]config.Config{
+		newTestGateway("public", map[string]string{"higress": "internal-gateway"}),
+	}, nil)
+	assert.True(t, scopes.ProxyNeedsPush(internal, req))
+}
+
+func TestScopedConvertedConfigs(t *testing.T) {
+	client := file.NewClient()
+	m := ingressconfig.NewIngressConfig(client, nil, "higress-system", "")
+	ingressController := m.AddLocalCluster(common.Options{
+		Enable:                true,
+		SystemNamespace:       "higress-system",
+		GatewaySelectorKey:    "higress",
+		GatewaySelectorValue:  "public-gateway",
+		NamespaceGatewayLabel: "higress.io/gateway",
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, m.InitializeCluster(ingressController, stop))
+	go m.Run(stop)
+	client.RunAndWait(stop)
+
+	_, err := client.Kube().CoreV1().Namespaces().Create(context.TODO(), &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal", Labels: map[string]string{"higress.io/gateway": "internal-gateway"}},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	pathType := ingress.PathTypePrefix
+	for _, namespace := range []string{"public", "internal"} {
+		_, err = client.Kube().NetworkingV1().Ingresses(namespace).Create(context.TODO(), &ingress.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace, Namespace: namespace},
+			Spec: ingress.IngressSpec{
+				Rules: []ingress.IngressRule{{
+					Host: namespace + ".com",
+					IngressRuleValue: ingress.IngressRuleValue{HTTP: &ingress.HTTPIngressRuleValue{
+						Paths: []ingress.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: ingress.IngressBackend{Service: &ingress.IngressServiceBackend{
+								Name: namespace,
+								Port: ingress.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	var push *model.PushContext
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		gateways, _ := m.List(gvk.Gateway, "")
+		virtualServices, _ := m.List(gvk.VirtualService, "")
+		push = newTestPushContext(gateways, virtualServices)
+		for _, gateway := range gateways {
+			if gateway.Spec.(*networking.Gateway).Selector["higress"] == "internal-gateway" {
+				return len(virtualServices) == 2, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("wait for the converted configs timeout")
+	}
+
+	// The converted virtual services are scoped by the generated gateways they are bound to.
+	scopes := NewGatewayScopes(nil, nil, "")
+	scopes.connectedProxies = nil
+	internal := newTestProxy("internal", map[string]string{GatewaySelectorAnnotation: "higress=internal-gateway"})
+	gateways := scopes.Gateways(internal, push)
+	virtualServices := scopes.VirtualServices(internal, push)
+	assert.Len(t, gateways, 1)
+	assert.Len(t, virtualServices, 1)
+	assert.Equal(t, []string{"internal.com"}, virtualServices[0].Spec.(*networking.VirtualService).Hosts)
+
+	for _, cfg := range push.AllVirtualServices {
+		req := &model.PushRequest{
+			Full:           true,
+			Push:           push,
+			ConfigsUpdated: map[model.ConfigKey]struct{}{{Kind: gvk.VirtualService, Name: cfg.Name, Namespace: cfg.Namespace}: {}},
+		}
+		assert.Equal(t, cfg.Name == virtualServices[0].Name, scopes.ProxyNeedsPush(internal, req), cfg.Name)
+	}
+}
+
+func TestGatewaySelectorOfPod(t *testing.T) {
+	client := kube.NewFakeClient()
+	connected := sets.NewSet("istiod-internal-0.higress-system")
+	scopes := NewGatewayScopes(client, nil, "higress-system")
+	scopes.connectedProxies = func() sets.Set {
+		return connected
+	}
+	var pushes int
+	scopes.push = func() {
+		pushes++
+	}
+	pods := scopes.pods.Informer().GetStore()
+	pod := &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod-internal-0", Namespace: "higress-system"},
+	}
+	push := newTestPushContext([]config.Config{
+		newTestGateway("public", map[string]string{"higress": "public-gateway"}),
+		newTestGateway("internal", map[string]string{"higress": "internal-gateway"}),
+	}, nil)
+
+	// The proxy whose pod is not known yet receives all the gateways until the pod is found.
+	internal := newTestProxy("istiod-internal-0", nil)
+	assert.Equal(t, 2, len(scopes.Gateways(internal, push)))
+	assert.NoError(t, pods.Add(pod))
+	scopes.onPod(pod)
+	assert.Equal(t, 0, pushes)
+	assert.Equal(t, 2, len(scopes.Gateways(internal, push)))
+
+	// The annotation added later takes effect with a push.
+	pod = pod.DeepCopy()
+	pod.Annotations = map[string]string{GatewaySelectorAnnotation: "higress=internal-gateway"}
+	assert.NoError(t, pods.Update(pod))
+	scopes.onPod(pod)
+	assert.Equal(t, 1, pushes)
+	assert.Equal(t, []string{"internal"}, names(scopes.Gateways(internal, push)))
+	scopes.onPod(pod)
+	assert.Equal(t, 1, pushes)
+
+	// The node metadata takes precedence over the pod.
+	public := newTestProxy("istiod-internal-0", map[string]string{GatewaySelectorAnnotation: "higress=public-gateway"})
+	public.ID = "istiod-public-0.higress-system"
+	assert.Equal(t, []string{"public"}, names(scopes.Gateways(public, push)))
+	assert.Equal(t, []string{"istiod-internal-0.higress-system", "istiod-public-0.higress-system"}, sortedScopes(scopes))
+	publicPod := &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod-public-0", Namespace: "higress-system"},
+	}
+	scopes.onPod(publicPod)
+	assert.Equal(t, 1, pushes)
+
+	// The scopes of the disconnected proxies are pruned once another proxy connects.
+	connected = sets.NewSet(public.ID)
+	assert.Equal(t, 2, len(scopes.Gateways(newTestProxy("missing", nil), push)))
+	assert.Equal(t, []string{"istiod-public-0.higress-system", "missing.higress-system"}, sortedScopes(scopes))
+
+	// Only the pods in the namespace of the controller are looked up.
+	otherPod := &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "istiod-other-0",
+			Namespace:   "default",
+			Annotations: map[string]string{GatewaySelectorAnnotation: "higress=internal-gateway"},
+		},
+	}
+	assert.NoError(t, pods.Add(otherPod))
+	other := &model.Proxy{ID: "istiod-other-0.default", ConfigNamespace: "default", Metadata: &model.NodeMetadata{}}
+	assert.Equal(t, 2, len(scopes.Gateways(other, push)))
+}
+
+func sortedScopes(scopes *GatewayScopes) []string {
+	scopes.mutex.Lock()
+	defer scopes.mutex.Unlock()
+	ids := sets.NewSet()
+	for id := range scopes.scopes {
+		ids.Insert(id)
+	}
+	return ids.SortedList()
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	ingress "k8s.io/api/networking/v1"
+	ingressv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// ValidatePath is the path of the validating webhook of the ingresses.
+const ValidatePath = "/validate-ingress"
+
+// maxRequestBytes limits the size of the admission review.
+const maxRequestBytes = 8 << 20
+
+// Validator validates the ingress to be admitted, the warnings are returned to the client even if
+// the ingress is valid.
+type Validator interface {
+	ValidateIngress(obj runtime.Object) (warnings []string, err error)
+}
+
+type Options struct {
+	// Address is the address the https server listens on.
+	Address string
+	// CertFile and KeyFile are the serving certificate trusted by the api server.
+	CertFile string
+	KeyFile  string
+	// WarnOnly admits the invalid ingresses, the validation errors are returned as the warnings.
+	WarnOnly bool
+}
+
+// Server serves the validating admission webhook of the ingresses, the ValidatingWebhookConfiguration
+// should select the ingresses of the version served by the ingress controller.
+type Server struct {
+	options   Options
+	validator Validator
+	server    *http.Server
+}
+
+func NewServer(options Options, validator Validator) *Server {
+	s := &Server{
+		options:   options,
+		validator: validator,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidatePath, s.serveValidate)
+	s.server = &http.Server{
+		Addr:        options.Address,
+		Handler:     mux,
+		ReadTimeout: 30 * time.Second,
+	}
+	return s
+}
+
+// Run starts the https server in the background, which is shut down when the stop is closed.
+func (s *Server) Run(stop <-chan struct{}) error {
+	go func() {
+		IngressLog.Infof("Starting ingress validating webhook at %s", s.options.Address)
+		if err := s.server.ListenAndServeTLS(s.options.CertFile, s.options.KeyFile); err != nil && err != http.ErrServerClosed {
+			IngressLog.Errorf("Serve ingress validating webhook error: %v", err)
+		}
+	}()
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.server.Shutdown(ctx)
+	}()
+	return nil
+}
+
+func (s *Server) serveValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("read body error: %v", err), http.StatusBadRequest)
+		return
+	}
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+
+	response := s.validate(review.Request)
+	response.UID = review.Request.UID
+	out, err := json.Marshal(&admissionv1.AdmissionReview{
+		TypeMeta: review.TypeMeta,
+		Response: response,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("marshal admission review error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+func (s *Server) validate(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if request.Operation == admissionv1.Delete {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	obj, err := decodeIngress(request)
+	if err != nil {
+		IngressLog.Errorf("Ingress %s/%s can't be validated: %v", request.Namespace, request.Name, err)
+		if s.options.WarnOnly {
+			return &admissionv1.AdmissionResponse{Allowed: true, Warnings: []string{err.Error()}}
+		}
+		return &admissionv1.AdmissionResponse{
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  metav1.StatusReasonBadRequest,
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			},
+		}
+	}
+	if obj == nil {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	warnings, err := s.validator.ValidateIngress(obj)
+	if err == nil {
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: warnings}
+	}
+	IngressLog.Infof("Ingress %s/%s is invalid: %v", request.Namespace, request.Name, err)
+	if s.options.WarnOnly {
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: append(warnings, err.Error())}
+	}
+	return &admissionv1.AdmissionResponse{
+		Warnings: warnings,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		},
+	}
+}
+
+// decodeIngress returns nil if the request is not of an ingress. The ingresses of
+// extensions/v1beta1 are decoded as the same ones of networking.k8s.io/v1beta1.
+func decodeIngress(request *admissionv1.AdmissionRequest) (runtime.Object, error) {
+	if request.Kind.Kind != "Ingress" {
+		return nil, nil
+	}
+
+	var obj runtime.Object
+	switch {
+	case request.Kind.Group == ingress.GroupName && request.Kind.Version == "v1":
+		obj = &ingress.Ingress{}
+	case (request.Kind.Group == ingressv1beta1.GroupName || request.Kind.Group == "extensions") &&
+		request.Kind.Version == "v1beta1":
+		obj = &ingressv1beta1.Ingress{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+		return nil, fmt.Errorf("decode ingress error: %v", err)
+	}
+	return obj, nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	ingress "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type fakeValidator struct {
+	validated []runtime.Object
+}
+
+// ValidateIngress rejects the ingresses named invalid, and warns all.
+func (f *fakeValidator) ValidateIngress(obj runtime.Object) ([]string, error) {
+	f.validated = append(f.validated, obj)
+	if obj.(metav1.Object).GetName() == "invalid" {
+		return []string{"conflict"}, errors.New("duplicated route")
+	}
+	return []string{"conflict"}, nil
+}
+
+func TestServeValidate(t *testing.T) {
+	ingressKind := metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	newReview := func(operation admissionv1.Operation, kind metav1.GroupVersionKind, name string) *admissionv1.AdmissionReview {
+		raw, _ := json.Marshal(&ingress.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})
+		return &admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:       types.UID("uid"),
+				Kind:      kind,
+				Name:      name,
+				Namespace: "default",
+				Operation: operation,
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		}
+	}
+
+	undecodable := func(review *admissionv1.AdmissionReview) *admissionv1.AdmissionReview {
+		review.Request.Object.Raw = []byte(`{"spec": []}`)
+		return review
+	}
+	decodeError := "decode ingress error: json: cannot unmarshal array into Go struct field Ingress.spec of type v1.IngressSpec"
+
+	testCases := []struct {
+		name      string
+		warnOnly  bool
+		review    *admissionv1.AdmissionReview
+		allowed   bool
+		warnings  []string
+		message   string
+		validated int
+	}{
+		{
+			name:      "valid",
+			review:    newReview(admissionv1.Create, ingressKind, "valid"),
+			allowed:   true,
+			warnings:  []string{"conflict"},
+			validated: 1,
+		},
+		{
+			name:      "invalid",
+			review:    newReview(admissionv1.Update, ingressKind, "invalid"),
+			warnings:  []string{"conflict"},
+			message:   "duplicated route",
+			validated: 1,
+		},
+		{
+			name:      "invalid in warn only mode",
+			warnOnly:  true,
+			review:    newReview(admissionv1.Create, ingressKind, "invalid"),
+			allowed:   true,
+			warnings:  []string{"conflict", "duplicated route"},
+			validated: 1,
+		},
+		{
+			name:    "delete",
+			review:  newReview(admissionv1.Delete, ingressKind, "invalid"),
+			allowed: true,
+		},
+		{
+			name:      "extensions v1beta1",
+			review:    newReview(admissionv1.Create, metav1.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}, "invalid"),
+			warnings:  []string{"conflict"},
+			message:   "duplicated route",
+			validated: 1,
+		},
+		{
+			name:    "undecodable",
+			review:  undecodable(newReview(admissionv1.Create, ingressKind, "valid")),
+			message: decodeError,
+		},
+		{
+			name:     "undecodable in warn only mode",
+			warnOnly: true,
+			review:   undecodable(newReview(admissionv1.Create, ingressKind, "valid")),
+			allowed:  true,
+			warnings: []string{decodeError},
+		},
+		{
+			name:    "not an ingress",
+			review:  newReview(admissionv1.Create, metav1.GroupVersionKind{Version: "v1", Kind: "Service"}, "invalid"),
+			allowed: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			validator := &fakeValidator{}
+			server := NewServer(Options{WarnOnly: testCase.warnOnly}, validator)
+			body, _ := json.Marshal(testCase.review)
+			recorder := httptest.NewRecorder()
+			server.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, ValidatePath, bytes.NewReader(body)))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+
+			review := &admissionv1.AdmissionReview{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), review))
+			assert.Equal(t, testCase.review.TypeMeta, review.TypeMeta)
+			assert.Equal(t, types.UID("uid"), review.Response.UID)
+			assert.Equal(t, testCase.allowed, review.Response.Allowed)
+			assert.Equal(t, testCase.warnings, review.Response.Warnings)
+			if !testCase.allowed {
+				assert.Equal(t, testCase.message, review.Response.Result.Message)
+			}
+			assert.Len(t, validator.validated, testCase.validated)
+		})
+	}
+
+	t.Run("bad request", func(t *testing.T) {
+		server := NewServer(Options{}, &fakeValidator{})
+		recorder := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, ValidatePath, bytes.NewReader([]byte("{}"))))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type serviceHealth struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		Service string            `json:"Service"`
+		Tags    []string          `json:"Tags"`
+		Address string            `json:"Address"`
+		Port    uint32            `json:"Port"`
+		Meta    map[string]string `json:"Meta"`
+		Weights struct {
+			Passing uint32 `json:"Passing"`
+		} `json:"Weights"`
+	} `json:"Service"`
+}
+
+// client calls the http api of consul, see https://www.consul.io/api-docs.
+type client struct {
+	httpClient *http.Client
+	address    string
+	namespace  string
+	datacenter string
+}
+
+// listServices returns the tags of the services, it blocks until the services change or the wait
+// time is up if index is not zero.
+func (c *client) listServices(ctx context.Context, index uint64, wait time.Duration) (map[string][]string, uint64, error) {
+	params := url.Values{}
+	if index != 0 {
+		params.Set("index", strconv.FormatUint(index, 10))
+		params.Set("wait", wait.String())
+	}
+
+	var services map[string][]string
+	newIndex, err := c.get(ctx, "/v1/catalog/services", params, &services)
+	return services, newIndex, err
+}
+
+// listInstances returns the instances of the service whose health checks are passing.
+func (c *client) listInstances(ctx context.Context, service string) ([]serviceHealth, error) {
+	params := url.Values{}
+	params.Set("passing", "true")
+
+	var instances []serviceHealth
+	_, err := c.get(ctx, "/v1/health/service/"+url.PathEscape(service), params, &instances)
+	return instances, err
+}
+
+func (c *client) get(ctx context.Context, path string, params url.Values, out interface{}) (uint64, error) {
+	if c.namespace != "" {
+		params.Set("ns", c.namespace)
+	}
+	if c.datacenter != "" {
+		params.Set("dc", c.datacenter)
+	}
+
+	u := url.URL{Scheme: "http", Host: c.address, Path: path, RawQuery: params.Encode()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request %s%s failed: %v", c.address, path, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("request %s%s failed with status %d: %s", c.address, path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err = json.Unmarshal(body, out); err != nil {
+		return 0, fmt.Errorf("invalid response of %s from consul %s: %v", path, c.address, err)
+	}
+
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return index, nil
+}
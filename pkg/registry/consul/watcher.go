@@ -0,0 +1,195 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+const (
+	defaultPort = 8500
+	hostSuffix  = "consul"
+	// blockingWait is the max wait time of the blocking query, all services are resynced after it
+	// to pick up the changes of the health checks.
+	blockingWait = 30 * time.Second
+	// The timeout of the blocking query must be longer than the wait time, consul adds a jitter up
+	// to 1/16 of the wait time.
+	requestTimeout = blockingWait + 10*time.Second
+	maxRetryDelay  = 30 * time.Second
+)
+
+var validServiceName = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+
+type watcher struct {
+	*registry.Syncer
+	key    string
+	client *client
+	tags   []string
+	wait   time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewWatcher watches the catalog with blocking queries, the host of the service entry is
+// service.consul.
+func NewWatcher(key string, config *mcpbridge.RegistryConfig, cache memory.Cache, onUpdate func()) (registry.Watcher, error) {
+	if config.Domain == "" {
+		return nil, fmt.Errorf("domain of consul is not specified")
+	}
+	port := config.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watcher{
+		Syncer: registry.NewSyncer(key, cache, onUpdate),
+		key:    key,
+		client: &client{
+			httpClient: &http.Client{Timeout: requestTimeout},
+			address:    net.JoinHostPort(config.Domain, strconv.FormatInt(port, 10)),
+			namespace:  config.ConsulNamespace,
+			datacenter: config.ConsulDatacenter,
+		},
+		tags:   config.ConsulServiceTags,
+		wait:   blockingWait,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+func (w *watcher) Run() {
+	var index uint64
+	retryDelay := time.Second
+	for {
+		newIndex, err := w.sync(index)
+		if w.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			IngressLog.Errorf("sync consul registry %s error %v", w.key, err)
+			w.Fail(err)
+			// Start over with a non-blocking query after the failure.
+			index = 0
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			if retryDelay *= 2; retryDelay > maxRetryDelay {
+				retryDelay = maxRetryDelay
+			}
+			continue
+		}
+
+		retryDelay = time.Second
+		// The index must be reset if it goes backwards, see
+		// https://www.consul.io/api-docs/features/blocking#implementation-details.
+		if newIndex < index {
+			newIndex = 0
+		}
+		index = newIndex
+	}
+}
+
+func (w *watcher) Stop() {
+	w.cancel()
+	w.Close()
+}
+
+// sync waits for the changes of the catalog since the index, and then syncs all selected services.
+func (w *watcher) sync(index uint64) (uint64, error) {
+	services, newIndex, err := w.client.listServices(w.ctx, index, w.wait)
+	if err != nil {
+		return 0, err
+	}
+
+	serviceEntries := map[string]*networking.ServiceEntry{}
+	for service, tags := range services {
+		if !validServiceName.MatchString(service) || !containsAll(tags, w.tags) {
+			continue
+		}
+		instances, err := w.client.listInstances(w.ctx, service)
+		if err != nil {
+			return 0, err
+		}
+
+		host := service + "." + hostSuffix
+		if serviceEntry := convertServiceEntry(host, instances); serviceEntry != nil {
+			serviceEntries[host] = serviceEntry
+		}
+	}
+	w.Sync(serviceEntries)
+	return newIndex, nil
+}
+
+// convertServiceEntry returns nil if there is no available instance. The protocol of the port is
+// read from the protocol meta of the service, and the address of the service defaults to the
+// address of the node.
+func convertServiceEntry(host string, instances []serviceHealth) *networking.ServiceEntry {
+	var endpoints []registry.Endpoint
+	for _, instance := range instances {
+		address := instance.Service.Address
+		if address == "" {
+			address = instance.Node.Address
+		}
+		if address == "" || instance.Service.Port == 0 {
+			continue
+		}
+
+		weight := instance.Service.Weights.Passing
+		if weight == 0 {
+			weight = 1
+		}
+		endpoints = append(endpoints, registry.Endpoint{
+			Address:  address,
+			Port:     instance.Service.Port,
+			Protocol: instance.Service.Meta["protocol"],
+			Labels:   instance.Service.Meta,
+			Weight:   weight,
+		})
+	}
+	return registry.NewServiceEntry(host, endpoints)
+}
+
+func containsAll(tags, required []string) bool {
+	for _, tag := range required {
+		found := false
+		for _, t := range tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+// fakeConsul serves the catalog, the blocking query returns once the catalog changes.
+type fakeConsul struct {
+	mutex sync.Mutex
+	// changed is closed once the catalog changes.
+	changed chan struct{}
+	index   uint64
+	// service -> instances
+	services map[string][]serviceHealth
+	tags     map[string][]string
+	// The query of the last request.
+	query url.Values
+}
+
+func newFakeConsul() *fakeConsul {
+	f := &fakeConsul{
+		changed:  make(chan struct{}),
+		index:    1,
+		services: map[string][]serviceHealth{},
+		tags:     map[string][]string{},
+	}
+	return f
+}
+
+func (f *fakeConsul) update(service string, tags []string, instances []serviceHealth) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if instances == nil {
+		delete(f.services, service)
+		delete(f.tags, service)
+	} else {
+		f.services[service] = instances
+		f.tags[service] = tags
+	}
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	query := r.URL.Query()
+	f.query = query
+	if query.Get("ns") != "team" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	switch {
+	case r.URL.Path == "/v1/catalog/services":
+		index, _ := strconv.ParseUint(query.Get("index"), 10, 64)
+		for index == f.index {
+			changed := f.changed
+			f.mutex.Unlock()
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				f.mutex.Lock()
+				return
+			}
+			f.mutex.Lock()
+		}
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+		json.NewEncoder(w).Encode(f.tags)
+	case strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		json.NewEncoder(w).Encode(f.services[strings.TrimPrefix(r.URL.Path, "/v1/health/service/")])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newInstance(nodeAddress, address string, port uint32, meta map[string]string) serviceHealth {
+	instance := serviceHealth{}
+	instance.Node.Address = nodeAddress
+	instance.Service.Address = address
+	instance.Service.Port = port
+	instance.Service.Meta = meta
+	return instance
+}
+
+func newTestWatcher(t *testing.T, server *httptest.Server, cache memory.Cache, onUpdate func()) *watcher {
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.ParseInt(u.Port(), 10, 64)
+	w, err := NewWatcher("higress-system/default/consul", &mcpbridge.RegistryConfig{
+		Type:              registry.Consul,
+		Domain:            u.Hostname(),
+		Port:              port,
+		ConsulNamespace:   "team",
+		ConsulDatacenter:  "dc1",
+		ConsulServiceTags: []string{"http"},
+	}, cache, onUpdate)
+	if err != nil {
+		t.Fatalf("new watcher error %v", err)
+	}
+	return w.(*watcher)
+}
+
+func TestSync(t *testing.T) {
+	consul := newFakeConsul()
+	consul.update("foo", []string{"http", "v1"}, []serviceHealth{
+		newInstance("10.0.0.1", "", 8080, map[string]string{"version": "v1"}),
+		newInstance("10.0.0.2", "172.16.0.2", 8080, nil),
+	})
+	consul.update("rpc", []string{"rpc"}, []serviceHealth{
+		newInstance("10.0.0.3", "", 9090, nil),
+	})
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	cache := memory.NewCache()
+	w := newTestWatcher(t, server, cache, func() {})
+	index, err := w.sync(0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), index)
+	assert.Equal(t, "dc1", consul.query.Get("dc"))
+	assert.Equal(t, registry.Status{Phase: registry.PhaseSynced}, w.Status())
+
+	serviceEntries := cache.ServiceEntries()
+	assert.Len(t, serviceEntries, 1)
+	assert.Equal(t, &networking.ServiceEntry{
+		Hosts:      []string{"foo.consul"},
+		Ports:      []*networking.Port{{Number: 8080, Protocol: "HTTP", Name: "http-8080"}},
+		Location:   networking.ServiceEntry_MESH_INTERNAL,
+		Resolution: networking.ServiceEntry_STATIC,
+		Endpoints: []*networking.WorkloadEntry{
+			{Address: "10.0.0.1", Ports: map[string]uint32{"http-8080": 8080}, Labels: map[string]string{"version": "v1"}, Weight: 1},
+			{Address: "172.16.0.2", Ports: map[string]uint32{"http-8080": 8080}, Weight: 1},
+		},
+	}, serviceEntries[0].ServiceEntry)
+}
+
+func TestRun(t *testing.T) {
+	consul := newFakeConsul()
+	consul.update("foo", []string{"http"}, []serviceHealth{newInstance("10.0.0.1", "", 8080, nil)})
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	cache := memory.NewCache()
+	updated := make(chan struct{}, 10)
+	w := newTestWatcher(t, server, cache, func() { updated <- struct{}{} })
+	done := make(chan struct{})
+	go func() {
+		w.Run()
+		close(done)
+	}()
+
+	waitForUpdate := func() {
+		select {
+		case <-updated:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for update")
+		}
+	}
+	waitForUpdate()
+	assert.Equal(t, 1, cache.ServiceCount(w.key))
+
+	// The blocking query returns once the catalog changes.
+	consul.update("foo", nil, nil)
+	waitForUpdate()
+	assert.Equal(t, 0, cache.ServiceCount(w.key))
+
+	w.Stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for stop")
+	}
+}
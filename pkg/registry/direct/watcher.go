@@ -0,0 +1,162 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package direct
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config/protocol"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+const defaultPort = 80
+
+type watcher struct {
+	*registry.Syncer
+	host         string
+	serviceEntry *networking.ServiceEntry
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher serves the addresses declared in the domain of the registry as a service entry, whose
+// host is name.static for the static registry and name.dns for the dns registry. The domain of the
+// static registry is a comma separated list of ip:port, and the domain of the dns registry is a
+// comma separated list of domains resolved by the gateway.
+func NewWatcher(key string, config *mcpbridge.RegistryConfig, cache memory.Cache, onUpdate func()) (registry.Watcher, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("name of %s registry is not specified", config.Type)
+	}
+	if errs := validation.IsDNS1123Subdomain(config.Name); len(errs) != 0 {
+		return nil, fmt.Errorf("invalid name %q of %s registry: %s", config.Name, config.Type, strings.Join(errs, ", "))
+	}
+	port := uint32(defaultPort)
+	if config.Port != 0 {
+		if config.Port < 0 || config.Port > 65535 {
+			return nil, fmt.Errorf("invalid port %d", config.Port)
+		}
+		port = uint32(config.Port)
+	}
+
+	var serviceEntry *networking.ServiceEntry
+	var err error
+	switch config.Type {
+	case registry.Static:
+		serviceEntry, err = staticServiceEntry(config.Domain, port)
+	case registry.DNS:
+		serviceEntry, err = dnsServiceEntry(config.Domain, port)
+	default:
+		err = fmt.Errorf("unexpected registry type %s", config.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	host := config.Name + "." + config.Type
+	serviceEntry.Hosts = []string{host}
+	return &watcher{
+		Syncer:       registry.NewSyncer(key, cache, onUpdate),
+		host:         host,
+		serviceEntry: serviceEntry,
+		stop:         make(chan struct{}),
+	}, nil
+}
+
+func (w *watcher) Run() {
+	w.Sync(map[string]*networking.ServiceEntry{w.host: w.serviceEntry})
+	<-w.stop
+}
+
+func (w *watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	w.Close()
+}
+
+func staticServiceEntry(domain string, port uint32) (*networking.ServiceEntry, error) {
+	serviceEntry := newServiceEntry(port, networking.ServiceEntry_STATIC)
+	portName := serviceEntry.Ports[0].Name
+	for _, address := range splitDomain(domain) {
+		ip, rawPort, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %v", address, err)
+		}
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("invalid ip %s", ip)
+		}
+		endpointPort, err := strconv.ParseUint(rawPort, 10, 16)
+		if err != nil || endpointPort == 0 {
+			return nil, fmt.Errorf("invalid port %s", rawPort)
+		}
+		serviceEntry.Endpoints = append(serviceEntry.Endpoints, &networking.WorkloadEntry{
+			Address: ip,
+			Ports:   map[string]uint32{portName: uint32(endpointPort)},
+		})
+	}
+	if len(serviceEntry.Endpoints) == 0 {
+		return nil, fmt.Errorf("no address of static registry is specified")
+	}
+	return serviceEntry, nil
+}
+
+func dnsServiceEntry(domain string, port uint32) (*networking.ServiceEntry, error) {
+	serviceEntry := newServiceEntry(port, networking.ServiceEntry_DNS)
+	serviceEntry.Location = networking.ServiceEntry_MESH_EXTERNAL
+	for _, address := range splitDomain(domain) {
+		if errs := validation.IsDNS1123Subdomain(address); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid domain %s: %s", address, strings.Join(errs, ", "))
+		}
+		serviceEntry.Endpoints = append(serviceEntry.Endpoints, &networking.WorkloadEntry{
+			Address: address,
+		})
+	}
+	if len(serviceEntry.Endpoints) == 0 {
+		return nil, fmt.Errorf("no domain of dns registry is specified")
+	}
+	return serviceEntry, nil
+}
+
+func newServiceEntry(port uint32, resolution networking.ServiceEntry_Resolution) *networking.ServiceEntry {
+	return &networking.ServiceEntry{
+		Location:   networking.ServiceEntry_MESH_INTERNAL,
+		Resolution: resolution,
+		Ports: []*networking.Port{{
+			Number:   port,
+			Protocol: string(protocol.HTTP),
+			Name:     fmt.Sprintf("http-%d", port),
+		}},
+	}
+}
+
+func splitDomain(domain string) []string {
+	var out []string
+	for _, address := range strings.Split(domain, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			out = append(out, address)
+		}
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package direct
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+func TestNewWatcher(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config *mcpbridge.RegistryConfig
+		expect *networking.ServiceEntry
+		err    bool
+	}{
+		{
+			name: "static",
+			config: &mcpbridge.RegistryConfig{
+				Type:   registry.Static,
+				Name:   "foo",
+				Domain: "1.1.1.1:8080, 1.1.1.2:8081",
+			},
+			expect: &networking.ServiceEntry{
+				Hosts:      []string{"foo.static"},
+				Location:   networking.ServiceEntry_MESH_INTERNAL,
+				Resolution: networking.ServiceEntry_STATIC,
+				Ports:      []*networking.Port{{Number: 80, Protocol: "HTTP", Name: "http-80"}},
+				Endpoints: []*networking.WorkloadEntry{
+					{Address: "1.1.1.1", Ports: map[string]uint32{"http-80": 8080}},
+					{Address: "1.1.1.2", Ports: map[string]uint32{"http-80": 8081}},
+				},
+			},
+		},
+		{
+			name: "dns",
+			config: &mcpbridge.RegistryConfig{
+				Type:   registry.DNS,
+				Name:   "bar",
+				Domain: "www.example.com",
+				Port:   8080,
+			},
+			expect: &networking.ServiceEntry{
+				Hosts:      []string{"bar.dns"},
+				Location:   networking.ServiceEntry_MESH_EXTERNAL,
+				Resolution: networking.ServiceEntry_DNS,
+				Ports:      []*networking.Port{{Number: 8080, Protocol: "HTTP", Name: "http-8080"}},
+				Endpoints:  []*networking.WorkloadEntry{{Address: "www.example.com"}},
+			},
+		},
+		{
+			name:   "without name",
+			config: &mcpbridge.RegistryConfig{Type: registry.Static, Domain: "1.1.1.1:80"},
+			err:    true,
+		},
+		{
+			name:   "invalid name",
+			config: &mcpbridge.RegistryConfig{Type: registry.Static, Name: "Foo_1", Domain: "1.1.1.1:80"},
+			err:    true,
+		},
+		{
+			name:   "static without port",
+			config: &mcpbridge.RegistryConfig{Type: registry.Static, Name: "foo", Domain: "1.1.1.1"},
+			err:    true,
+		},
+		{
+			name:   "static with domain",
+			config: &mcpbridge.RegistryConfig{Type: registry.Static, Name: "foo", Domain: "www.example.com:80"},
+			err:    true,
+		},
+		{
+			name:   "dns with ip port",
+			config: &mcpbridge.RegistryConfig{Type: registry.DNS, Name: "foo", Domain: "1.1.1.1:80"},
+			err:    true,
+		},
+		{
+			name:   "empty domain",
+			config: &mcpbridge.RegistryConfig{Type: registry.DNS, Name: "foo", Domain: " , "},
+			err:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			w, err := NewWatcher("higress-system/default/"+testCase.config.Name, testCase.config, memory.NewCache(), func() {})
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expect, w.(*watcher).serviceEntry)
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	cache := memory.NewCache()
+	updated := make(chan struct{}, 1)
+	config := &mcpbridge.RegistryConfig{Type: registry.Static, Name: "foo", Domain: "1.1.1.1:80"}
+	w, err := NewWatcher("higress-system/default/foo", config, cache, func() {
+		updated <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("new watcher error %v", err)
+	}
+	assert.Equal(t, registry.PhasePending, w.Status().Phase)
+
+	done := make(chan struct{})
+	go func() {
+		w.Run()
+		close(done)
+	}()
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("wait update timeout")
+	}
+	assert.Equal(t, registry.Status{Phase: registry.PhaseSynced}, w.Status())
+	assert.Equal(t, 1, cache.ServiceCount("higress-system/default/foo"))
+
+	w.Stop()
+	<-done
+}
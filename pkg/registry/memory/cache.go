@@ -0,0 +1,115 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// Cache holds the service entries synced from the registries.
+type Cache interface {
+	UpdateServiceEntry(registry, host string, serviceEntry *networking.ServiceEntry)
+
+	DeleteServiceEntry(registry, host string)
+
+	// DeleteRegistry deletes all service entries of the registry.
+	DeleteRegistry(registry string)
+
+	// ServiceCount returns the number of the service entries of the registry.
+	ServiceCount(registry string) int
+
+	// ServiceEntries returns the service entries of all registries ordered by host and registry.
+	ServiceEntries() []*ServiceEntryWrapper
+}
+
+type ServiceEntryWrapper struct {
+	Registry     string
+	Host         string
+	ServiceEntry *networking.ServiceEntry
+}
+
+type cache struct {
+	mutex sync.RWMutex
+	// registry -> host -> service entry
+	serviceEntries map[string]map[string]*networking.ServiceEntry
+}
+
+func NewCache() Cache {
+	return &cache{
+		serviceEntries: map[string]map[string]*networking.ServiceEntry{},
+	}
+}
+
+func (c *cache) UpdateServiceEntry(registry, host string, serviceEntry *networking.ServiceEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entries, exist := c.serviceEntries[registry]
+	if !exist {
+		entries = map[string]*networking.ServiceEntry{}
+		c.serviceEntries[registry] = entries
+	}
+	entries[host] = serviceEntry
+}
+
+func (c *cache) DeleteServiceEntry(registry, host string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entries, exist := c.serviceEntries[registry]
+	if !exist {
+		return
+	}
+	delete(entries, host)
+	if len(entries) == 0 {
+		delete(c.serviceEntries, registry)
+	}
+}
+
+func (c *cache) DeleteRegistry(registry string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.serviceEntries, registry)
+}
+
+func (c *cache) ServiceCount(registry string) int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.serviceEntries[registry])
+}
+
+func (c *cache) ServiceEntries() []*ServiceEntryWrapper {
+	c.mutex.RLock()
+	var out []*ServiceEntryWrapper
+	for registry, entries := range c.serviceEntries {
+		for host, serviceEntry := range entries {
+			out = append(out, &ServiceEntryWrapper{
+				Registry:     registry,
+				Host:         host,
+				ServiceEntry: serviceEntry,
+			})
+		}
+	}
+	c.mutex.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Host != out[j].Host {
+			return out[i].Host < out[j].Host
+		}
+		return out[i].Registry < out[j].Registry
+	})
+	return out
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+func TestCache(t *testing.T) {
+	c := NewCache()
+	a := &networking.ServiceEntry{Hosts: []string{"a.nacos"}}
+	b := &networking.ServiceEntry{Hosts: []string{"b.nacos"}}
+	c.UpdateServiceEntry("nacos-2", "a.nacos", a)
+	c.UpdateServiceEntry("nacos-1", "b.nacos", b)
+	c.UpdateServiceEntry("nacos-1", "a.nacos", a)
+
+	assert.Equal(t, []*ServiceEntryWrapper{
+		{Registry: "nacos-1", Host: "a.nacos", ServiceEntry: a},
+		{Registry: "nacos-2", Host: "a.nacos", ServiceEntry: a},
+		{Registry: "nacos-1", Host: "b.nacos", ServiceEntry: b},
+	}, c.ServiceEntries())
+	assert.Equal(t, 2, c.ServiceCount("nacos-1"))
+
+	c.DeleteServiceEntry("nacos-2", "a.nacos")
+	assert.Equal(t, 0, c.ServiceCount("nacos-2"))
+	c.DeleteRegistry("nacos-1")
+	assert.Empty(t, c.ServiceEntries())
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nacos
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAddressServerPort = "8080"
+	defaultServerPort        = "8848"
+	servicePageSize          = 100
+)
+
+type instance struct {
+	IP       string            `json:"ip"`
+	Port     uint32            `json:"port"`
+	Weight   float64           `json:"weight"`
+	Healthy  bool              `json:"healthy"`
+	Enabled  bool              `json:"enabled"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// client calls the open api of nacos, see https://nacos.io/en-us/docs/open-api.html.
+type client struct {
+	httpClient *http.Client
+	// The servers are fetched from the address server if it is set.
+	addressServer string
+	servers       []string
+	namespaceId   string
+	accessKey     string
+	secretKey     string
+}
+
+func (c *client) listServices(group string) ([]string, error) {
+	var services []string
+	for pageNo := 1; ; pageNo++ {
+		var result struct {
+			Count int      `json:"count"`
+			Doms  []string `json:"doms"`
+		}
+		params := url.Values{}
+		params.Set("pageNo", strconv.Itoa(pageNo))
+		params.Set("pageSize", strconv.Itoa(servicePageSize))
+		params.Set("groupName", group)
+		if err := c.get("/nacos/v1/ns/service/list", params, "", &result); err != nil {
+			return nil, err
+		}
+		services = append(services, result.Doms...)
+		if len(result.Doms) == 0 || len(services) >= result.Count {
+			return services, nil
+		}
+	}
+}
+
+func (c *client) listInstances(service, group string) ([]instance, error) {
+	var result struct {
+		Hosts []instance `json:"hosts"`
+	}
+	params := url.Values{}
+	params.Set("serviceName", service)
+	params.Set("groupName", group)
+	params.Set("healthyOnly", "true")
+	if err := c.get("/nacos/v1/ns/instance/list", params, group+"@@"+service, &result); err != nil {
+		return nil, err
+	}
+	return result.Hosts, nil
+}
+
+// get requests the servers in order until one of them succeeds.
+func (c *client) get(path string, params url.Values, signedService string, out interface{}) error {
+	servers := c.servers
+	if c.addressServer != "" {
+		var err error
+		if servers, err = c.fetchServers(); err != nil {
+			return err
+		}
+	}
+
+	if c.namespaceId != "" {
+		params.Set("namespaceId", c.namespaceId)
+	}
+	if c.accessKey != "" {
+		c.sign(params, signedService)
+	}
+
+	var lastErr error
+	for _, server := range servers {
+		body, err := c.request(server, path, params)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err = json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("invalid response of %s from nacos server %s: %v", path, server, err)
+		}
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no nacos server")
+	}
+	return lastErr
+}
+
+// sign adds the signature of the access key and the secret key, which is required by the nacos of
+// aliyun.
+func (c *client) sign(params url.Values, signedService string) {
+	data := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	if signedService != "" {
+		data += "@@" + signedService
+	}
+	mac := hmac.New(sha1.New, []byte(c.secretKey))
+	mac.Write([]byte(data))
+	params.Set("ak", c.accessKey)
+	params.Set("data", data)
+	params.Set("signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// fetchServers fetches the addresses of the nacos servers, one per line.
+func (c *client) fetchServers() ([]string, error) {
+	body, err := c.request(withDefaultPort(c.addressServer, defaultAddressServerPort), "/nacos/serverlist", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var servers []string
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			servers = append(servers, withDefaultPort(line, defaultServerPort))
+		}
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no nacos server from address server %s", c.addressServer)
+	}
+	return servers, nil
+}
+
+// request gets the path from the server, the params are kept out of the errors since they may
+// contain the signature.
+func (c *client) request(server, path string, params url.Values) ([]byte, error) {
+	u := url.URL{Scheme: "http", Host: server, Path: path, RawQuery: params.Encode()}
+	resp, err := c.httpClient.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("request %s%s failed: %v", server, path, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request %s%s failed with status %d: %s", server, path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+func withDefaultPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(address, port)
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nacos
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+const (
+	DefaultGroup           = "DEFAULT_GROUP"
+	DefaultRefreshInterval = 30 * time.Second
+	// publicNamespaceId is used in the hosts for the public namespace whose id is empty, which is
+	// the same as NacosCluster of the wasm-go sdk.
+	publicNamespaceId = "public"
+	hostSuffix        = "nacos"
+	requestTimeout    = 10 * time.Second
+)
+
+var validServiceName = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+
+type watcher struct {
+	*registry.Syncer
+	key    string
+	client *client
+	groups []string
+	// namespaceId is used in the hosts, and it's empty for the public namespace.
+	namespaceId string
+	interval    time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher polls the healthy instances of the services in the groups of the namespace, the host
+// of the service entry is service.group.namespaceId.nacos, the underscores of the group are
+// replaced with dashes.
+func NewWatcher(key string, config *mcpbridge.RegistryConfig, cache memory.Cache, onUpdate func()) (registry.Watcher, error) {
+	if config.NacosAddressServer == "" && config.Domain == "" {
+		return nil, fmt.Errorf("neither domain nor address server of nacos is specified")
+	}
+	if (config.NacosAccessKey == "") != (config.NacosSecretKey == "") {
+		return nil, fmt.Errorf("access key and secret key of nacos must be specified together")
+	}
+
+	c := &client{
+		httpClient:    &http.Client{Timeout: requestTimeout},
+		addressServer: config.NacosAddressServer,
+		namespaceId:   config.NacosNamespaceId,
+		accessKey:     config.NacosAccessKey,
+		secretKey:     config.NacosSecretKey,
+	}
+	if c.addressServer == "" {
+		port := defaultServerPort
+		if config.Port != 0 {
+			port = strconv.FormatInt(config.Port, 10)
+		}
+		c.servers = []string{net.JoinHostPort(config.Domain, port)}
+	}
+
+	w := &watcher{
+		Syncer:      registry.NewSyncer(key, cache, onUpdate),
+		key:         key,
+		client:      c,
+		groups:      config.NacosGroups,
+		namespaceId: config.NacosNamespaceId,
+		interval:    config.NacosRefreshInterval,
+		stop:        make(chan struct{}),
+	}
+	if len(w.groups) == 0 {
+		w.groups = []string{DefaultGroup}
+	}
+	if w.interval <= 0 {
+		w.interval = DefaultRefreshInterval
+	}
+	return w, nil
+}
+
+func (w *watcher) Run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.sync()
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	w.Close()
+}
+
+func (w *watcher) sync() {
+	serviceEntries := map[string]*networking.ServiceEntry{}
+	for _, group := range w.groups {
+		services, err := w.client.listServices(group)
+		if err != nil {
+			IngressLog.Errorf("list services of group %s from nacos registry %s error %v", group, w.key, err)
+			w.Fail(err)
+			return
+		}
+
+		for _, service := range services {
+			if !validServiceName.MatchString(service) {
+				IngressLog.Debugf("skip service %s of nacos registry %s whose name is invalid in host", service, w.key)
+				continue
+			}
+			instances, err := w.client.listInstances(service, group)
+			if err != nil {
+				IngressLog.Errorf("list instances of service %s from nacos registry %s error %v", service, w.key, err)
+				w.Fail(err)
+				return
+			}
+
+			host := serviceHost(service, group, w.namespaceId)
+			if serviceEntry := convertServiceEntry(host, instances); serviceEntry != nil {
+				serviceEntries[host] = serviceEntry
+			}
+		}
+	}
+	w.Sync(serviceEntries)
+}
+
+// serviceHost returns the host of the service, which must be kept the same as the cluster name of
+// NacosCluster in the wasm-go sdk.
+func serviceHost(service, group, namespaceId string) string {
+	if namespaceId == "" {
+		namespaceId = publicNamespaceId
+	}
+	return strings.Join([]string{service, strings.ReplaceAll(group, "_", "-"), namespaceId, hostSuffix}, ".")
+}
+
+// convertServiceEntry returns nil if there is no available instance. The protocol of the port is
+// read from the protocol metadata of the instance.
+func convertServiceEntry(host string, instances []instance) *networking.ServiceEntry {
+	var endpoints []registry.Endpoint
+	for _, instance := range instances {
+		if !instance.Enabled || !instance.Healthy || instance.Weight <= 0 || instance.Port == 0 {
+			continue
+		}
+		endpoints = append(endpoints, registry.Endpoint{
+			Address:  instance.IP,
+			Port:     instance.Port,
+			Protocol: instance.Metadata["protocol"],
+			Labels:   instance.Metadata,
+			Weight:   uint32(math.Max(1, math.Round(instance.Weight))),
+		})
+	}
+	return registry.NewServiceEntry(host, endpoints)
+}
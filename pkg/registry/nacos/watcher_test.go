@@ -0,0 +1,226 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nacos
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+// fakeNacos serves the services and instances of the namespace.
+type fakeNacos struct {
+	namespaceId string
+	// group -> service -> instances
+	services map[string]map[string][]instance
+	// The query of the last request.
+	query url.Values
+}
+
+func (f *fakeNacos) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	f.query = query
+	if query.Get("namespaceId") != f.namespaceId {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	services := f.services[query.Get("groupName")]
+	switch r.URL.Path {
+	case "/nacos/v1/ns/service/list":
+		var doms []string
+		for service := range services {
+			doms = append(doms, service)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"count": len(doms), "doms": doms})
+	case "/nacos/v1/ns/instance/list":
+		json.NewEncoder(w).Encode(map[string]interface{}{"hosts": services[query.Get("serviceName")]})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestWatcher(t *testing.T, config *mcpbridge.RegistryConfig, cache memory.Cache) *watcher {
+	w, err := NewWatcher("higress-system/default/nacos", config, cache, func() {})
+	if err != nil {
+		t.Fatalf("new watcher error %v", err)
+	}
+	return w.(*watcher)
+}
+
+func serverAddress(server *httptest.Server) string {
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestWatcher(t *testing.T) {
+	nacos := &fakeNacos{
+		namespaceId: "dev",
+		services: map[string]map[string][]instance{
+			"DEFAULT_GROUP": {
+				"foo": {
+					{IP: "10.0.0.2", Port: 8080, Weight: 2, Healthy: true, Enabled: true, Metadata: map[string]string{"version": "v1"}},
+					{IP: "10.0.0.1", Port: 8080, Weight: 1, Healthy: true, Enabled: true, Metadata: map[string]string{"invalid key": "v"}},
+					{IP: "10.0.0.3", Port: 9090, Weight: 1, Healthy: true, Enabled: true, Metadata: map[string]string{"protocol": "grpc"}},
+					{IP: "10.0.0.4", Port: 8080, Weight: 1, Healthy: true, Enabled: false},
+				},
+				"down": {
+					{IP: "10.0.0.5", Port: 8080, Weight: 1, Healthy: true, Enabled: false},
+				},
+				"providers:com.foo.Bar::": {
+					{IP: "10.0.0.6", Port: 20880, Weight: 1, Healthy: true, Enabled: true},
+				},
+			},
+			"BAR_GROUP": {
+				"bar": {
+					{IP: "10.0.1.1", Port: 80, Weight: 1, Healthy: true, Enabled: true},
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(nacos)
+	defer server.Close()
+
+	cache := memory.NewCache()
+	w := newTestWatcher(t, &mcpbridge.RegistryConfig{
+		Type:             registry.Nacos,
+		Domain:           "127.0.0.1",
+		NacosNamespaceId: "dev",
+		NacosGroups:      []string{"DEFAULT_GROUP", "BAR_GROUP"},
+	}, cache)
+	w.client.servers = []string{serverAddress(server)}
+	w.sync()
+
+	assert.Equal(t, registry.Status{Phase: registry.PhaseSynced}, w.Status())
+	serviceEntries := cache.ServiceEntries()
+	assert.Len(t, serviceEntries, 2)
+	assert.Equal(t, "bar.BAR-GROUP.dev.nacos", serviceEntries[0].Host)
+	assert.Equal(t, "foo.DEFAULT-GROUP.dev.nacos", serviceEntries[1].Host)
+	assert.Equal(t, &networking.ServiceEntry{
+		Hosts: []string{"foo.DEFAULT-GROUP.dev.nacos"},
+		Ports: []*networking.Port{
+			{Number: 8080, Protocol: "HTTP", Name: "http-8080"},
+			{Number: 9090, Protocol: "GRPC", Name: "grpc-9090"},
+		},
+		Location:   networking.ServiceEntry_MESH_INTERNAL,
+		Resolution: networking.ServiceEntry_STATIC,
+		Endpoints: []*networking.WorkloadEntry{
+			{Address: "10.0.0.1", Ports: map[string]uint32{"http-8080": 8080}, Weight: 1},
+			{Address: "10.0.0.2", Ports: map[string]uint32{"http-8080": 8080}, Labels: map[string]string{"version": "v1"}, Weight: 2},
+			{Address: "10.0.0.3", Ports: map[string]uint32{"grpc-9090": 9090}, Labels: map[string]string{"protocol": "grpc"}, Weight: 1},
+		},
+	}, serviceEntries[1].ServiceEntry)
+
+	// The removed services are deleted, the service entries are kept if the sync fails.
+	delete(nacos.services, "BAR_GROUP")
+	w.sync()
+	assert.Len(t, cache.ServiceEntries(), 1)
+	nacos.namespaceId = "prod"
+	w.sync()
+	assert.Equal(t, registry.PhaseFailed, w.Status().Phase)
+	assert.Contains(t, w.Status().Message, "status 403")
+	assert.Len(t, cache.ServiceEntries(), 1)
+}
+
+func TestWatcherWithAddressServer(t *testing.T) {
+	nacos := &fakeNacos{
+		services: map[string]map[string][]instance{
+			DefaultGroup: {
+				"foo": {{IP: "10.0.0.1", Port: 8080, Weight: 1, Healthy: true, Enabled: true}},
+			},
+		},
+	}
+	server := httptest.NewServer(nacos)
+	defer server.Close()
+	addressServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/nacos/serverlist", r.URL.Path)
+		w.Write([]byte("127.0.0.1:1\n" + serverAddress(server) + "\n"))
+	}))
+	defer addressServer.Close()
+
+	cache := memory.NewCache()
+	w := newTestWatcher(t, &mcpbridge.RegistryConfig{
+		Type:                 registry.Nacos,
+		NacosAddressServer:   serverAddress(addressServer),
+		NacosAccessKey:       "ak",
+		NacosSecretKey:       "sk",
+		NacosRefreshInterval: time.Second,
+	}, cache)
+	assert.Equal(t, time.Second, w.interval)
+	w.sync()
+
+	assert.Equal(t, registry.Status{Phase: registry.PhaseSynced}, w.Status())
+	serviceEntries := cache.ServiceEntries()
+	assert.Len(t, serviceEntries, 1)
+	assert.Equal(t, "foo.DEFAULT-GROUP.public.nacos", serviceEntries[0].Host)
+	assert.Equal(t, "ak", nacos.query.Get("ak"))
+	assert.True(t, strings.HasSuffix(nacos.query.Get("data"), "@@DEFAULT_GROUP@@foo"))
+	assert.NotEmpty(t, nacos.query.Get("signature"))
+}
+
+func TestNewWatcher(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config *mcpbridge.RegistryConfig
+	}{
+		{
+			name:   "no address",
+			config: &mcpbridge.RegistryConfig{Type: registry.Nacos},
+		},
+		{
+			name:   "no secret key",
+			config: &mcpbridge.RegistryConfig{Type: registry.Nacos, Domain: "127.0.0.1", NacosAccessKey: "ak"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewWatcher("nacos", testCase.config, memory.NewCache(), func() {})
+			assert.Error(t, err)
+		})
+	}
+}
+
+// The hosts are shared with NacosCluster of the wasm-go sdk, so that the plugins can call the
+// services by the cluster names.
+func TestServiceHost(t *testing.T) {
+	data, err := os.ReadFile("../../../plugins/wasm-go/pkg/wrapper/testdata/nacos_hosts.json")
+	if err != nil {
+		t.Fatalf("read nacos hosts error %v", err)
+	}
+	var testCases []struct {
+		ServiceName string `json:"serviceName"`
+		Group       string `json:"group"`
+		NamespaceId string `json:"namespaceId"`
+		Host        string `json:"host"`
+	}
+	if err := json.Unmarshal(data, &testCases); err != nil {
+		t.Fatalf("unmarshal nacos hosts error %v", err)
+	}
+	assert.NotEmpty(t, testCases)
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.Host, serviceHost(testCase.ServiceName, testCase.Group, testCase.NamespaceId))
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/consul"
+	"github.com/alibaba/higress/pkg/registry/direct"
+	"github.com/alibaba/higress/pkg/registry/memory"
+	"github.com/alibaba/higress/pkg/registry/nacos"
+	"github.com/alibaba/higress/pkg/registry/zookeeper"
+)
+
+// watcherFactories holds the factories of the supported registry types.
+var watcherFactories = map[string]registry.WatcherFactory{
+	registry.Nacos:     nacos.NewWatcher,
+	registry.Consul:    consul.NewWatcher,
+	registry.Zookeeper: zookeeper.NewWatcher,
+	registry.Static:    direct.NewWatcher,
+	registry.DNS:       direct.NewWatcher,
+}
+
+type watcherItem struct {
+	config  *mcpbridge.RegistryConfig
+	watcher registry.Watcher
+	// err is the reason why the watcher can't be created.
+	err error
+}
+
+func (w *watcherItem) status() registry.Status {
+	if w.err != nil {
+		return registry.Status{
+			Phase:   registry.PhaseFailed,
+			Message: w.err.Error(),
+		}
+	}
+	return w.watcher.Status()
+}
+
+// Reconciler keeps the registry watchers consistent with the McpBridges.
+type Reconciler struct {
+	cache     memory.Cache
+	onUpdate  func()
+	factories map[string]registry.WatcherFactory
+
+	mutex sync.Mutex
+	// registry key -> watcher
+	watchers map[string]*watcherItem
+	// McpBridge -> registry keys in the order of the spec
+	bridges map[types.NamespacedName][]string
+}
+
+// NewReconciler returns the reconciler which writes the service entries to the cache, onUpdate is
+// called when the service entries or the status of any registry change.
+func NewReconciler(cache memory.Cache, onUpdate func()) *Reconciler {
+	return &Reconciler{
+		cache:     cache,
+		onUpdate:  onUpdate,
+		factories: watcherFactories,
+		watchers:  map[string]*watcherItem{},
+		bridges:   map[types.NamespacedName][]string{},
+	}
+}
+
+func registryKey(name types.NamespacedName, config *mcpbridge.RegistryConfig) string {
+	return name.String() + "/" + config.RegistryName()
+}
+
+// Reconcile starts the watchers of the new or changed registries of the McpBridge, and stops the
+// watchers of the removed ones. All watchers of the McpBridge are stopped if bridge is nil.
+func (r *Reconciler) Reconcile(name types.NamespacedName, bridge *mcpbridge.McpBridge) {
+	// onUpdate may read the statuses, so it is called without the lock.
+	if r.reconcile(name, bridge) {
+		r.onUpdate()
+	}
+}
+
+func (r *Reconciler) reconcile(name types.NamespacedName, bridge *mcpbridge.McpBridge) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var keys []string
+	desired := map[string]*mcpbridge.RegistryConfig{}
+	if bridge != nil {
+		for _, config := range bridge.Spec.Registries {
+			if config == nil {
+				continue
+			}
+			key := registryKey(name, config)
+			if _, exist := desired[key]; exist {
+				IngressLog.Warnf("duplicated registry %s in mcpbridge %s, ignore it", config.RegistryName(), name)
+				continue
+			}
+			desired[key] = config
+			keys = append(keys, key)
+		}
+	}
+
+	changed := false
+	for _, key := range r.bridges[name] {
+		if config, exist := desired[key]; exist && reflect.DeepEqual(config, r.watchers[key].config) {
+			continue
+		}
+		r.stopWatcher(key)
+		changed = true
+	}
+
+	for _, key := range keys {
+		if _, exist := r.watchers[key]; exist {
+			continue
+		}
+		r.watchers[key] = r.startWatcher(key, desired[key])
+	}
+
+	if len(keys) == 0 {
+		delete(r.bridges, name)
+	} else {
+		r.bridges[name] = keys
+	}
+	return changed
+}
+
+func (r *Reconciler) startWatcher(key string, config *mcpbridge.RegistryConfig) *watcherItem {
+	item := &watcherItem{config: config}
+	factory, exist := r.factories[config.Type]
+	if !exist {
+		item.err = fmt.Errorf("unsupported registry type %s", config.Type)
+	} else {
+		item.watcher, item.err = factory(key, config, r.cache, r.onUpdate)
+	}
+	if item.err != nil {
+		IngressLog.Errorf("create watcher of registry %s error %v", key, item.err)
+		return item
+	}
+
+	IngressLog.Infof("start watcher of registry %s", key)
+	go item.watcher.Run()
+	return item
+}
+
+func (r *Reconciler) stopWatcher(key string) {
+	item, exist := r.watchers[key]
+	if !exist {
+		return
+	}
+	// The stopped watcher doesn't write to the cache anymore, so the service entries of the registry
+	// are not written back after they are deleted.
+	if item.watcher != nil {
+		IngressLog.Infof("stop watcher of registry %s", key)
+		item.watcher.Stop()
+	}
+	delete(r.watchers, key)
+	r.cache.DeleteRegistry(key)
+}
+
+// Bridges returns the McpBridges which have registries.
+func (r *Reconciler) Bridges() []types.NamespacedName {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var out []types.NamespacedName
+	for name := range r.bridges {
+		out = append(out, name)
+	}
+	return out
+}
+
+// Statuses returns the sync status of the registries of the McpBridge in the order of the spec.
+func (r *Reconciler) Statuses(name types.NamespacedName) []mcpbridge.RegistryStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var out []mcpbridge.RegistryStatus
+	for _, key := range r.bridges[name] {
+		item := r.watchers[key]
+		status := item.status()
+		out = append(out, mcpbridge.RegistryStatus{
+			Name:         item.config.RegistryName(),
+			Type:         item.config.Type,
+			Phase:        string(status.Phase),
+			Message:      status.Message,
+			ServiceCount: r.cache.ServiceCount(key),
+		})
+	}
+	return out
+}
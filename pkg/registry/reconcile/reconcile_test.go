@@ -0,0 +1,111 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+type fakeWatcher struct {
+	stopped bool
+}
+
+func (w *fakeWatcher) Run() {}
+
+func (w *fakeWatcher) Stop() {
+	w.stopped = true
+}
+
+func (w *fakeWatcher) Status() registry.Status {
+	return registry.Status{Phase: registry.PhaseSynced}
+}
+
+func newTestReconciler() (*Reconciler, memory.Cache, map[string]*fakeWatcher, *int) {
+	cache := memory.NewCache()
+	updates := 0
+	r := NewReconciler(cache, func() { updates++ })
+	watchers := map[string]*fakeWatcher{}
+	r.factories = map[string]registry.WatcherFactory{
+		"fake": func(key string, config *mcpbridge.RegistryConfig, cache memory.Cache, onUpdate func()) (registry.Watcher, error) {
+			// Sync the services before running to make the result deterministic.
+			cache.UpdateServiceEntry(key, "foo.static", &networking.ServiceEntry{Hosts: []string{"foo.static"}})
+			w := &fakeWatcher{}
+			watchers[key] = w
+			return w, nil
+		},
+		"broken": func(string, *mcpbridge.RegistryConfig, memory.Cache, func()) (registry.Watcher, error) {
+			return nil, errors.New("invalid address")
+		},
+	}
+	return r, cache, watchers, &updates
+}
+
+func TestReconcile(t *testing.T) {
+	r, cache, watchers, updates := newTestReconciler()
+	name := types.NamespacedName{Namespace: "higress-system", Name: "default"}
+	bridge := &mcpbridge.McpBridge{
+		Spec: mcpbridge.McpBridgeSpec{
+			Registries: []*mcpbridge.RegistryConfig{
+				{Type: "fake", Name: "a", Domain: "127.0.0.1", Port: 80},
+				{Type: "fake", Domain: "127.0.0.2", Port: 80},
+				{Type: "broken", Name: "b"},
+				{Type: "unknown", Name: "c"},
+				{Type: "fake", Name: "a", Domain: "127.0.0.3", Port: 80},
+			},
+		},
+	}
+	r.Reconcile(name, bridge)
+
+	assert.Len(t, watchers, 2)
+	assert.Len(t, cache.ServiceEntries(), 2)
+	assert.Equal(t, []mcpbridge.RegistryStatus{
+		{Name: "a", Type: "fake", Phase: "Synced", ServiceCount: 1},
+		{Name: "fake-127.0.0.2-80", Type: "fake", Phase: "Synced", ServiceCount: 1},
+		{Name: "b", Type: "broken", Phase: "Failed", Message: "invalid address"},
+		{Name: "c", Type: "unknown", Phase: "Failed", Message: "unsupported registry type unknown"},
+	}, r.Statuses(name))
+	assert.Equal(t, []types.NamespacedName{name}, r.Bridges())
+	assert.Equal(t, 0, *updates)
+
+	// Only the changed registry is restarted.
+	first := watchers["higress-system/default/a"]
+	second := watchers["higress-system/default/fake-127.0.0.2-80"]
+	bridge.Spec.Registries = []*mcpbridge.RegistryConfig{
+		{Type: "fake", Name: "a", Domain: "127.0.0.1", Port: 80},
+		{Type: "fake", Domain: "127.0.0.2", Port: 80, ZkServicesPath: []string{"/dubbo"}},
+	}
+	r.Reconcile(name, bridge)
+	assert.False(t, first.stopped)
+	assert.True(t, second.stopped)
+	assert.NotSame(t, second, watchers["higress-system/default/fake-127.0.0.2-80"])
+	assert.Len(t, r.Statuses(name), 2)
+	assert.Equal(t, 1, *updates)
+
+	r.Reconcile(name, nil)
+	assert.True(t, first.stopped)
+	assert.Empty(t, cache.ServiceEntries())
+	assert.Empty(t, r.Statuses(name))
+	assert.Empty(t, r.Bridges())
+	assert.Equal(t, 2, *updates)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config/protocol"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+// VersionLabel is the label of the endpoints whose values are the subsets of the service, the
+// name of the subset is the version converted by SubsetName.
+const VersionLabel = "version"
+
+var invalidSubsetNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Endpoint is an instance of the service in the registry.
+type Endpoint struct {
+	Address string
+	Port    uint32
+	// Protocol defaults to http.
+	Protocol string
+	Labels   map[string]string
+	Weight   uint32
+}
+
+// NewServiceEntry returns the statically resolved service entry of the endpoints, one port per
+// distinct endpoint port. It returns nil if there is no endpoint.
+func NewServiceEntry(host string, endpoints []Endpoint) *networking.ServiceEntry {
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	// Keep the order of the endpoints stable to avoid unnecessary pushes.
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].Address != endpoints[j].Address {
+			return endpoints[i].Address < endpoints[j].Address
+		}
+		return endpoints[i].Port < endpoints[j].Port
+	})
+
+	serviceEntry := &networking.ServiceEntry{
+		Hosts:      []string{host},
+		Location:   networking.ServiceEntry_MESH_INTERNAL,
+		Resolution: networking.ServiceEntry_STATIC,
+	}
+	ports := map[uint32]*networking.Port{}
+	for _, endpoint := range endpoints {
+		port, exist := ports[endpoint.Port]
+		if !exist {
+			port = newPort(endpoint.Port, endpoint.Protocol)
+			ports[endpoint.Port] = port
+			serviceEntry.Ports = append(serviceEntry.Ports, port)
+		}
+
+		serviceEntry.Endpoints = append(serviceEntry.Endpoints, &networking.WorkloadEntry{
+			Address: endpoint.Address,
+			Ports:   map[string]uint32{port.Name: endpoint.Port},
+			Labels:  ValidLabels(endpoint.Labels),
+			Weight:  endpoint.Weight,
+		})
+	}
+	sort.Slice(serviceEntry.Ports, func(i, j int) bool {
+		return serviceEntry.Ports[i].Number < serviceEntry.Ports[j].Number
+	})
+	return serviceEntry
+}
+
+// SubsetName converts the version to a DNS-1123 label as the name of the subset, e.g. 1.0.0 is
+// converted to 1-0-0. It must be kept the same as the subset names of the clusters in the wasm-go
+// sdk, and it returns empty if nothing is left of the version.
+func SubsetName(version string) string {
+	name := invalidSubsetNameChars.ReplaceAllString(strings.ToLower(version), "-")
+	if len(name) > validation.DNS1123LabelMaxLength {
+		name = name[:validation.DNS1123LabelMaxLength]
+	}
+	return strings.Trim(name, "-")
+}
+
+// NewDestinationRule returns the destination rule with a subset per version of the endpoints of
+// the service entry. It returns nil if there is no versioned endpoint.
+func NewDestinationRule(host string, serviceEntry *networking.ServiceEntry) *networking.DestinationRule {
+	versionSet := map[string]bool{}
+	var versions []string
+	for _, endpoint := range serviceEntry.Endpoints {
+		version := endpoint.Labels[VersionLabel]
+		if version == "" || versionSet[version] {
+			continue
+		}
+		versionSet[version] = true
+		versions = append(versions, version)
+	}
+
+	// The first of the versions with the same subset name wins.
+	sort.Strings(versions)
+	names := map[string]string{}
+	var subsets []*networking.Subset
+	for _, version := range versions {
+		name := SubsetName(version)
+		if name == "" {
+			IngressLog.Warnf("version %s of host %s is not a valid subset name, ignore it", version, host)
+			continue
+		}
+		if other, exist := names[name]; exist {
+			IngressLog.Warnf("version %s of host %s has the same subset name as version %s, ignore it", version, host, other)
+			continue
+		}
+		names[name] = version
+		subsets = append(subsets, &networking.Subset{
+			Name:   name,
+			Labels: map[string]string{VersionLabel: version},
+		})
+	}
+	if len(subsets) == 0 {
+		return nil
+	}
+
+	sort.Slice(subsets, func(i, j int) bool {
+		return subsets[i].Name < subsets[j].Name
+	})
+	return &networking.DestinationRule{
+		Host:    host,
+		Subsets: subsets,
+	}
+}
+
+func newPort(number uint32, rawProtocol string) *networking.Port {
+	proto := protocol.Parse(rawProtocol)
+	if proto.IsUnsupported() {
+		proto = protocol.HTTP
+	}
+	return &networking.Port{
+		Number:   number,
+		Protocol: string(proto),
+		Name:     fmt.Sprintf("%s-%d", strings.ToLower(string(proto)), number),
+	}
+}
+
+// ValidLabels returns the metadata which are valid labels.
+func ValidLabels(metadata map[string]string) map[string]string {
+	var labels map[string]string
+	for key, value := range metadata {
+		if len(validation.IsQualifiedName(key)) != 0 || len(validation.IsValidLabelValue(value)) != 0 {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+	}
+	return labels
+}
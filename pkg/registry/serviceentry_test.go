@@ -0,0 +1,67 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// The subset names are shared with the clusters of the wasm-go sdk, so that the plugins can call
+// the versions of the services by the cluster names.
+func TestSubsetName(t *testing.T) {
+	data, err := os.ReadFile("../../plugins/wasm-go/pkg/wrapper/testdata/subset_names.json")
+	if err != nil {
+		t.Fatalf("read subset names error %v", err)
+	}
+	var testCases []struct {
+		Version string `json:"version"`
+		Subset  string `json:"subset"`
+	}
+	if err := json.Unmarshal(data, &testCases); err != nil {
+		t.Fatalf("unmarshal subset names error %v", err)
+	}
+	assert.NotEmpty(t, testCases)
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.Subset, SubsetName(testCase.Version))
+	}
+}
+
+func TestNewDestinationRule(t *testing.T) {
+	serviceEntry := NewServiceEntry("com.foo.DemoService.DEFAULT-GROUP.zookeeper", []Endpoint{
+		{Address: "1.1.1.1", Port: 20880, Protocol: "TCP", Labels: map[string]string{VersionLabel: "1.0.0"}},
+		{Address: "1.1.1.2", Port: 20880, Protocol: "TCP", Labels: map[string]string{VersionLabel: "1.0.0"}},
+		{Address: "1.1.1.3", Port: 20880, Protocol: "TCP", Labels: map[string]string{VersionLabel: "1-0-0"}},
+		{Address: "1.1.1.4", Port: 20880, Protocol: "TCP", Labels: map[string]string{VersionLabel: "2.0.0"}},
+		{Address: "1.1.1.5", Port: 20880, Protocol: "TCP"},
+	})
+
+	// The subset names are DNS-1123 labels, and the raw versions are kept in the labels.
+	assert.Equal(t, &networking.DestinationRule{
+		Host: "com.foo.DemoService.DEFAULT-GROUP.zookeeper",
+		Subsets: []*networking.Subset{
+			{Name: "1-0-0", Labels: map[string]string{VersionLabel: "1-0-0"}},
+			{Name: "2-0-0", Labels: map[string]string{VersionLabel: "2.0.0"}},
+		},
+	}, NewDestinationRule("com.foo.DemoService.DEFAULT-GROUP.zookeeper", serviceEntry))
+
+	assert.Nil(t, NewDestinationRule("a.static", NewServiceEntry("a.static", []Endpoint{
+		{Address: "1.1.1.1", Port: 80},
+	})))
+}
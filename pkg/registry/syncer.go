@@ -0,0 +1,115 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+// Syncer writes the service entries of a registry to the cache and records the sync status, it
+// is meant to be embedded in the watchers.
+type Syncer struct {
+	key      string
+	cache    memory.Cache
+	onUpdate func()
+
+	mutex  sync.Mutex
+	closed bool
+	status Status
+	// host -> service entry
+	synced map[string]*networking.ServiceEntry
+}
+
+func NewSyncer(key string, cache memory.Cache, onUpdate func()) *Syncer {
+	return &Syncer{
+		key:      key,
+		cache:    cache,
+		onUpdate: onUpdate,
+		status:   Status{Phase: PhasePending},
+		synced:   map[string]*networking.ServiceEntry{},
+	}
+}
+
+func (s *Syncer) Status() Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.status
+}
+
+// Sync replaces the service entries of the registry, and calls onUpdate if the service entries or
+// the status change.
+func (s *Syncer) Sync(serviceEntries map[string]*networking.ServiceEntry) {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	changed := s.setStatus(Status{Phase: PhaseSynced})
+	for host := range s.synced {
+		if _, exist := serviceEntries[host]; !exist {
+			s.cache.DeleteServiceEntry(s.key, host)
+			changed = true
+		}
+	}
+	for host, serviceEntry := range serviceEntries {
+		if old, exist := s.synced[host]; exist && proto.Equal(old, serviceEntry) {
+			continue
+		}
+		s.cache.UpdateServiceEntry(s.key, host, serviceEntry)
+		changed = true
+	}
+	s.synced = serviceEntries
+	s.mutex.Unlock()
+
+	if changed {
+		s.onUpdate()
+	}
+}
+
+// Fail records the failure of the sync, the service entries synced before are kept.
+func (s *Syncer) Fail(err error) {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	changed := s.setStatus(Status{Phase: PhaseFailed, Message: err.Error()})
+	s.mutex.Unlock()
+
+	if changed {
+		s.onUpdate()
+	}
+}
+
+// Close drops the syncs after it returns, it waits for the sync in progress, so that the service
+// entries of the registry can be deleted from the cache safely.
+func (s *Syncer) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+}
+
+func (s *Syncer) setStatus(status Status) bool {
+	if s.status == status {
+		return false
+	}
+	s.status = status
+	return true
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+func TestSyncer(t *testing.T) {
+	cache := memory.NewCache()
+	updates := 0
+	s := NewSyncer("static", cache, func() { updates++ })
+	assert.Equal(t, PhasePending, s.Status().Phase)
+
+	a := &networking.ServiceEntry{Hosts: []string{"a.static"}}
+	b := &networking.ServiceEntry{Hosts: []string{"b.static"}}
+	s.Sync(map[string]*networking.ServiceEntry{"a.static": a, "b.static": b})
+	assert.Equal(t, Status{Phase: PhaseSynced}, s.Status())
+	assert.Equal(t, 2, cache.ServiceCount("static"))
+	assert.Equal(t, 1, updates)
+
+	// Nothing changes.
+	s.Sync(map[string]*networking.ServiceEntry{
+		"a.static": {Hosts: []string{"a.static"}},
+		"b.static": b,
+	})
+	assert.Equal(t, 1, updates)
+
+	s.Fail(errors.New("connection refused"))
+	assert.Equal(t, Status{Phase: PhaseFailed, Message: "connection refused"}, s.Status())
+	assert.Equal(t, 2, cache.ServiceCount("static"))
+	assert.Equal(t, 2, updates)
+
+	s.Sync(map[string]*networking.ServiceEntry{"a.static": a})
+	assert.Equal(t, 1, cache.ServiceCount("static"))
+	assert.Equal(t, 3, updates)
+}
+
+// blockingCache blocks the updates until it is released.
+type blockingCache struct {
+	memory.Cache
+	updating chan struct{}
+	release  chan struct{}
+}
+
+func (c *blockingCache) UpdateServiceEntry(registry, host string, serviceEntry *networking.ServiceEntry) {
+	c.updating <- struct{}{}
+	<-c.release
+	c.Cache.UpdateServiceEntry(registry, host, serviceEntry)
+}
+
+func TestSyncerClose(t *testing.T) {
+	cache := &blockingCache{
+		Cache:    memory.NewCache(),
+		updating: make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+	s := NewSyncer("static", cache, func() {})
+	a := &networking.ServiceEntry{Hosts: []string{"a.static"}}
+	go s.Sync(map[string]*networking.ServiceEntry{"a.static": a})
+	<-cache.updating
+
+	// Close waits for the sync in progress.
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		cache.DeleteRegistry("static")
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("close returns before the sync in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(cache.release)
+	<-closed
+	assert.Equal(t, 0, cache.ServiceCount("static"))
+
+	// The syncs after close are dropped.
+	s.Sync(map[string]*networking.ServiceEntry{"a.static": a})
+	s.Fail(errors.New("connection refused"))
+	assert.Equal(t, 0, cache.ServiceCount("static"))
+	assert.Equal(t, Status{Phase: PhaseSynced}, s.Status())
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+// The types of the registries.
+const (
+	Nacos     = "nacos"
+	Consul    = "consul"
+	Zookeeper = "zookeeper"
+	// Static and DNS registries serve the addresses declared in the McpBridge.
+	Static = "static"
+	DNS    = "dns"
+)
+
+// Phase is the sync phase of a registry.
+type Phase string
+
+const (
+	PhasePending Phase = "Pending"
+	PhaseSynced  Phase = "Synced"
+	PhaseFailed  Phase = "Failed"
+)
+
+// Status is the sync status of a registry.
+type Status struct {
+	Phase Phase
+	// Message is the reason of the failure.
+	Message string
+}
+
+// Watcher watches the services of an external registry and writes them to the service entry cache.
+type Watcher interface {
+	// Run watches the registry until Stop is called.
+	Run()
+
+	// Stop stops watching the registry, the service entries are not written to the cache after it
+	// returns even if Run is still syncing.
+	Stop()
+
+	Status() Status
+}
+
+// WatcherFactory creates the watcher of the registry. The watcher writes the service entries to the
+// cache with the key of the registry, and calls onUpdate when the service entries or the status
+// change.
+type WatcherFactory func(key string, config *mcpbridge.RegistryConfig, cache memory.Cache, onUpdate func()) (Watcher, error)
@@ -0,0 +1,67 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zookeeper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+
+	. "github.com/alibaba/higress/pkg/ingress/log"
+)
+
+const sessionTimeout = 10 * time.Second
+
+// conn is the subset of the zookeeper connection used by the watcher.
+type conn interface {
+	Children(path string) ([]string, error)
+
+	// ChildrenW returns the children and a channel which receives an event when the children
+	// change. No watch is set if the error is not nil.
+	ChildrenW(path string) ([]string, <-chan zk.Event, error)
+
+	Close()
+}
+
+type zkConn struct {
+	*zk.Conn
+}
+
+// connect returns immediately, the session is established in the background.
+func connect(servers []string) (conn, error) {
+	c, _, err := zk.Connect(servers, sessionTimeout, zk.WithLogger(zkLogger{}))
+	if err != nil {
+		return nil, err
+	}
+	return &zkConn{Conn: c}, nil
+}
+
+func (c *zkConn) Children(path string) ([]string, error) {
+	children, _, err := c.Conn.Children(path)
+	return children, err
+}
+
+func (c *zkConn) ChildrenW(path string) ([]string, <-chan zk.Event, error) {
+	children, _, events, err := c.Conn.ChildrenW(path)
+	return children, events, err
+}
+
+// zkLogger writes the connection logs of the zookeeper client.
+type zkLogger struct{}
+
+func (zkLogger) Printf(format string, args ...interface{}) {
+	IngressLog.Debug(fmt.Sprintf(format, args...))
+}
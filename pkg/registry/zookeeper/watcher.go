@@ -0,0 +1,274 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zookeeper
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pkg/config/protocol"
+
+	"github.com/alibaba/higress/pkg/ingress/kube/mcpbridge"
+	. "github.com/alibaba/higress/pkg/ingress/log"
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+const (
+	DefaultRootPath = "/dubbo"
+	// DefaultGroup is used in the hosts for the providers without group.
+	DefaultGroup  = "DEFAULT-GROUP"
+	defaultPort   = 2181
+	hostSuffix    = "zookeeper"
+	providersNode = "providers"
+	defaultWeight = 100
+	// resyncInterval is the interval to retry the failures and to check the nodes which don't
+	// exist, no watch can be set on them.
+	resyncInterval = 30 * time.Second
+)
+
+var validInterfaceName = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+
+type watcher struct {
+	*registry.Syncer
+	key       string
+	conn      conn
+	rootPaths []string
+
+	// watched is the paths whose children are being watched, it's only accessed by Run.
+	watched map[string]bool
+	// fired receives the paths whose watches are fired.
+	fired chan string
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher watches the dubbo providers registered under the root paths, the host of the service
+// entry is interface.group.zookeeper, and the endpoints are labeled with the versions of the
+// providers, whose subsets are named by registry.SubsetName. The providers of the dubbo protocol
+// are published as tcp ports, and converting http requests to dubbo calls is out of scope, so the
+// clients must speak dubbo to them through tcp routes.
+func NewWatcher(key string, config *mcpbridge.RegistryConfig, cache memory.Cache, onUpdate func()) (registry.Watcher, error) {
+	if config.Domain == "" {
+		return nil, fmt.Errorf("domain of zookeeper is not specified")
+	}
+	port := config.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	c, err := connect([]string{net.JoinHostPort(config.Domain, strconv.FormatInt(port, 10))})
+	if err != nil {
+		return nil, err
+	}
+	return newWatcher(key, c, config.ZkServicesPath, cache, onUpdate), nil
+}
+
+func newWatcher(key string, c conn, rootPaths []string, cache memory.Cache, onUpdate func()) *watcher {
+	if len(rootPaths) == 0 {
+		rootPaths = []string{DefaultRootPath}
+	}
+	return &watcher{
+		Syncer:    registry.NewSyncer(key, cache, onUpdate),
+		key:       key,
+		conn:      c,
+		rootPaths: rootPaths,
+		watched:   map[string]bool{},
+		fired:     make(chan string, 64),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (w *watcher) Run() {
+	defer w.conn.Close()
+	for {
+		complete, err := w.sync()
+		if err != nil {
+			IngressLog.Errorf("sync zookeeper registry %s error %v", w.key, err)
+			w.Fail(err)
+		}
+		var resync <-chan time.Time
+		if err != nil || !complete {
+			resync = time.After(resyncInterval)
+		}
+
+		select {
+		case <-w.stop:
+			return
+		case p := <-w.fired:
+			delete(w.watched, p)
+		case <-resync:
+		}
+		// Resync the changes of the other paths together.
+		for drained := false; !drained; {
+			select {
+			case p := <-w.fired:
+				delete(w.watched, p)
+			default:
+				drained = true
+			}
+		}
+	}
+}
+
+func (w *watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	w.Close()
+}
+
+// sync reads the providers of all interfaces, it returns false if some of the nodes don't exist.
+func (w *watcher) sync() (bool, error) {
+	complete := true
+	endpoints := map[string][]registry.Endpoint{}
+	for _, root := range w.rootPaths {
+		interfaces, err := w.children(root)
+		if err == zk.ErrNoNode {
+			complete = false
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		for _, iface := range interfaces {
+			if !validInterfaceName.MatchString(iface) {
+				IngressLog.Debugf("skip interface %s of zookeeper registry %s whose name is invalid in host", iface, w.key)
+				continue
+			}
+			providers, err := w.children(path.Join(root, iface, providersNode))
+			if err == zk.ErrNoNode {
+				// The interface may have consumers only.
+				complete = false
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+
+			for _, provider := range providers {
+				host, endpoint, err := parseProvider(iface, provider)
+				if err != nil {
+					IngressLog.Debugf("skip provider %s of zookeeper registry %s: %v", provider, w.key, err)
+					continue
+				}
+				if endpoint != nil {
+					endpoints[host] = append(endpoints[host], *endpoint)
+				}
+			}
+		}
+	}
+
+	serviceEntries := map[string]*networking.ServiceEntry{}
+	for host, hostEndpoints := range endpoints {
+		if serviceEntry := registry.NewServiceEntry(host, hostEndpoints); serviceEntry != nil {
+			serviceEntries[host] = serviceEntry
+		}
+	}
+	w.Sync(serviceEntries)
+	return complete, nil
+}
+
+// children watches the children of the path if it's not being watched.
+func (w *watcher) children(p string) ([]string, error) {
+	if w.watched[p] {
+		return w.conn.Children(p)
+	}
+
+	children, events, err := w.conn.ChildrenW(p)
+	if err != nil {
+		return nil, err
+	}
+	w.watched[p] = true
+	go func() {
+		select {
+		case <-events:
+		case <-w.stop:
+			return
+		}
+		select {
+		case w.fired <- p:
+		case <-w.stop:
+		}
+	}()
+	return children, nil
+}
+
+// parseProvider parses the escaped provider url such as
+// dubbo://1.1.1.1:20880/com.foo.DemoService?group=g&version=1.0.0&weight=100, the endpoint is nil
+// if the provider is disabled.
+func parseProvider(iface, provider string) (string, *registry.Endpoint, error) {
+	raw, err := url.QueryUnescape(provider)
+	if err != nil {
+		return "", nil, err
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", nil, err
+	}
+	port, err := strconv.ParseUint(u.Port(), 10, 16)
+	if err != nil || port == 0 || u.Hostname() == "" {
+		return "", nil, fmt.Errorf("invalid address %s", u.Host)
+	}
+
+	query := u.Query()
+	group := query.Get("group")
+	if group == "" {
+		group = DefaultGroup
+	}
+	host := strings.Join([]string{iface, strings.ReplaceAll(group, "_", "-"), hostSuffix}, ".")
+	if query.Get("enabled") == "false" || query.Get("disabled") == "true" {
+		return host, nil, nil
+	}
+
+	weight := uint64(defaultWeight)
+	if value := query.Get("weight"); value != "" {
+		if weight, err = strconv.ParseUint(value, 10, 32); err != nil {
+			return "", nil, fmt.Errorf("invalid weight %s", value)
+		}
+	}
+	endpoint := &registry.Endpoint{
+		Address:  u.Hostname(),
+		Port:     uint32(port),
+		Protocol: providerProtocol(u.Scheme),
+		Weight:   uint32(weight),
+	}
+	if version := query.Get("version"); version != "" {
+		endpoint.Labels = map[string]string{registry.VersionLabel: version}
+	}
+	return host, endpoint, nil
+}
+
+// providerProtocol returns the protocol of the port, the dubbo protocol is proxied as tcp.
+func providerProtocol(scheme string) string {
+	switch scheme {
+	case "tri", "grpc":
+		return string(protocol.GRPC)
+	case "rest", "http":
+		return string(protocol.HTTP)
+	default:
+		return string(protocol.TCP)
+	}
+}
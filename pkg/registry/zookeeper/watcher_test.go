@@ -0,0 +1,206 @@
+// Copyright (c) 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zookeeper
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"github.com/alibaba/higress/pkg/registry"
+	"github.com/alibaba/higress/pkg/registry/memory"
+)
+
+// fakeConn holds the children of the paths, and fires the watches when the children are set.
+type fakeConn struct {
+	mutex    sync.Mutex
+	children map[string][]string
+	watches  map[string][]chan zk.Event
+	// The number of the watches set on the paths.
+	watchCount map[string]int
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{
+		children:   map[string][]string{},
+		watches:    map[string][]chan zk.Event{},
+		watchCount: map[string]int{},
+	}
+}
+
+func (f *fakeConn) Children(path string) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	children, exist := f.children[path]
+	if !exist {
+		return nil, zk.ErrNoNode
+	}
+	return children, nil
+}
+
+func (f *fakeConn) ChildrenW(path string) ([]string, <-chan zk.Event, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	children, exist := f.children[path]
+	if !exist {
+		return nil, nil, zk.ErrNoNode
+	}
+	ch := make(chan zk.Event, 1)
+	f.watches[path] = append(f.watches[path], ch)
+	f.watchCount[path]++
+	return children, ch, nil
+}
+
+func (f *fakeConn) Close() {}
+
+func (f *fakeConn) set(path string, children ...string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.children[path] = children
+	for _, ch := range f.watches[path] {
+		ch <- zk.Event{Type: zk.EventNodeChildrenChanged, Path: path}
+	}
+	delete(f.watches, path)
+}
+
+func (f *fakeConn) count(path string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.watchCount[path]
+}
+
+func provider(raw string) string {
+	return url.QueryEscape(raw)
+}
+
+func TestParseProvider(t *testing.T) {
+	testCases := []struct {
+		name     string
+		provider string
+		host     string
+		endpoint *registry.Endpoint
+		err      bool
+	}{
+		{
+			name:     "dubbo",
+			provider: "dubbo://1.1.1.1:20880/com.foo.DemoService?group=g_1&version=1.0.0&weight=10",
+			host:     "com.foo.DemoService.g-1.zookeeper",
+			endpoint: &registry.Endpoint{
+				Address:  "1.1.1.1",
+				Port:     20880,
+				Protocol: "TCP",
+				Labels:   map[string]string{"version": "1.0.0"},
+				Weight:   10,
+			},
+		},
+		{
+			name:     "triple without group",
+			provider: "tri://1.1.1.1:50051/com.foo.DemoService",
+			host:     "com.foo.DemoService.DEFAULT-GROUP.zookeeper",
+			endpoint: &registry.Endpoint{
+				Address:  "1.1.1.1",
+				Port:     50051,
+				Protocol: "GRPC",
+				Weight:   100,
+			},
+		},
+		{
+			name:     "disabled",
+			provider: "dubbo://1.1.1.1:20880/com.foo.DemoService?enabled=false",
+			host:     "com.foo.DemoService.DEFAULT-GROUP.zookeeper",
+		},
+		{
+			name:     "invalid port",
+			provider: "dubbo://1.1.1.1/com.foo.DemoService",
+			err:      true,
+		},
+		{
+			name:     "invalid weight",
+			provider: "dubbo://1.1.1.1:20880/com.foo.DemoService?weight=-1",
+			err:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			host, endpoint, err := parseProvider("com.foo.DemoService", provider(testCase.provider))
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.host, host)
+			assert.Equal(t, testCase.endpoint, endpoint)
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	conn := newFakeConn()
+	conn.set("/dubbo", "com.foo.DemoService", "com.foo.ConsumerOnly")
+	conn.set("/dubbo/com.foo.DemoService/providers",
+		provider("dubbo://1.1.1.2:20880/com.foo.DemoService?version=2.0.0"),
+		provider("dubbo://1.1.1.1:20880/com.foo.DemoService?version=1.0.0"),
+	)
+
+	cache := memory.NewCache()
+	updated := make(chan struct{}, 10)
+	w := newWatcher("higress-system/default/zookeeper", conn, nil, cache, func() {
+		updated <- struct{}{}
+	})
+	go w.Run()
+	defer w.Stop()
+
+	waitUpdate := func() {
+		select {
+		case <-updated:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("wait update timeout")
+		}
+	}
+	waitUpdate()
+	assert.Equal(t, registry.Status{Phase: registry.PhaseSynced}, w.Status())
+	serviceEntries := cache.ServiceEntries()
+	assert.Len(t, serviceEntries, 1)
+	assert.Equal(t, "com.foo.DemoService.DEFAULT-GROUP.zookeeper", serviceEntries[0].Host)
+	serviceEntry := serviceEntries[0].ServiceEntry
+	assert.Equal(t, []*networking.Port{{Number: 20880, Protocol: "TCP", Name: "tcp-20880"}}, serviceEntry.Ports)
+	assert.Len(t, serviceEntry.Endpoints, 2)
+	assert.Equal(t, "1.1.1.1", serviceEntry.Endpoints[0].Address)
+	assert.Equal(t, map[string]string{"version": "1.0.0"}, serviceEntry.Endpoints[0].Labels)
+	assert.Equal(t, map[string]string{"version": "2.0.0"}, serviceEntry.Endpoints[1].Labels)
+
+	// The provider goes offline.
+	conn.set("/dubbo/com.foo.DemoService/providers",
+		provider("dubbo://1.1.1.1:20880/com.foo.DemoService?version=1.0.0"),
+	)
+	waitUpdate()
+	serviceEntries = cache.ServiceEntries()
+	assert.Len(t, serviceEntries, 1)
+	assert.Len(t, serviceEntries[0].ServiceEntry.Endpoints, 1)
+	// The unchanged path is not watched again.
+	assert.Equal(t, 1, conn.count("/dubbo"))
+	assert.Equal(t, 2, conn.count("/dubbo/com.foo.DemoService/providers"))
+
+	// All providers go offline.
+	conn.set("/dubbo/com.foo.DemoService/providers")
+	waitUpdate()
+	assert.Empty(t, cache.ServiceEntries())
+}
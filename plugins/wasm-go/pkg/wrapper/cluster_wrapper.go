@@ -51,7 +51,8 @@ func (c K8sCluster) HostName() string {
 type NacosCluster struct {
 	ServiceName string
 	// use DEFAULT-GROUP by default
-	Group       string
+	Group string
+	// use public by default
 	NamespaceID string
 	Port        int64
 	// set true if use edas/sae registry
@@ -65,12 +66,16 @@ func (c NacosCluster) ClusterName() string {
 	if c.Group != "" {
 		group = strings.ReplaceAll(c.Group, "_", "-")
 	}
+	namespaceID := "public"
+	if c.NamespaceID != "" {
+		namespaceID = c.NamespaceID
+	}
 	tail := "nacos"
 	if c.IsExtRegistry {
 		tail += "-ext"
 	}
 	return fmt.Sprintf("outbound|%d|%s|%s.%s.%s.%s",
-		c.Port, c.Version, c.ServiceName, group, c.NamespaceID, tail)
+		c.Port, subsetName(c.Version), c.ServiceName, group, namespaceID, tail)
 }
 
 func (c NacosCluster) HostName() string {
@@ -80,6 +85,56 @@ func (c NacosCluster) HostName() string {
 	return c.ServiceName
 }
 
+// DubboCluster is the dubbo service registered in zookeeper
+type DubboCluster struct {
+	// the interface of the service
+	ServiceName string
+	// use DEFAULT-GROUP by default
+	Group   string
+	Port    int64
+	Version string
+	Host    string
+}
+
+func (c DubboCluster) ClusterName() string {
+	group := "DEFAULT-GROUP"
+	if c.Group != "" {
+		group = strings.ReplaceAll(c.Group, "_", "-")
+	}
+	return fmt.Sprintf("outbound|%d|%s|%s.%s.zookeeper",
+		c.Port, subsetName(c.Version), c.ServiceName, group)
+}
+
+func (c DubboCluster) HostName() string {
+	if c.Host != "" {
+		return c.Host
+	}
+	return c.ServiceName
+}
+
+// subsetName converts the version of the registry to the subset name of the cluster, which is a
+// DNS-1123 label, e.g. 1.0.0 is converted to 1-0-0.
+func subsetName(version string) string {
+	var name strings.Builder
+	replaced := false
+	for _, r := range strings.ToLower(version) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' {
+			name.WriteRune(r)
+			replaced = false
+			continue
+		}
+		if !replaced {
+			name.WriteByte('-')
+			replaced = true
+		}
+	}
+	result := name.String()
+	if len(result) > 63 {
+		result = result[:63]
+	}
+	return strings.Trim(result, "-")
+}
+
 type StaticIpCluster struct {
 	ServiceName string
 	Port        int64
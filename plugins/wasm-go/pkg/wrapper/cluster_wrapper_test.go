@@ -15,6 +15,8 @@
 package wrapper
 
 import (
+	"encoding/json"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,7 +59,16 @@ func TestClusterAndHost(t *testing.T) {
 				Port:        8080,
 				Version:     "1.0",
 			},
-			expectCluster: "outbound|8080|1.0|foo.DEFAULT-GROUP.xxxx.nacos",
+			expectCluster: "outbound|8080|1-0|foo.DEFAULT-GROUP.xxxx.nacos",
+			expectHost:    "foo",
+		},
+		{
+			name: "nacos public",
+			cluster: NacosCluster{
+				ServiceName: "foo",
+				Port:        8080,
+			},
+			expectCluster: "outbound|8080||foo.DEFAULT-GROUP.public.nacos",
 			expectHost:    "foo",
 		},
 		{
@@ -72,6 +83,17 @@ func TestClusterAndHost(t *testing.T) {
 			expectCluster: "outbound|8080||foo.DEFAULT-GROUP.xxxx.nacos-ext",
 			expectHost:    "www.test.com",
 		},
+		{
+			name: "dubbo",
+			cluster: DubboCluster{
+				ServiceName: "com.foo.DemoService",
+				Group:       "g_1",
+				Port:        20880,
+				Version:     "1.0.0",
+			},
+			expectCluster: "outbound|20880|1-0-0|com.foo.DemoService.g-1.zookeeper",
+			expectHost:    "com.foo.DemoService",
+		},
 		{
 			name: "static",
 			cluster: StaticIpCluster{
@@ -100,3 +122,43 @@ func TestClusterAndHost(t *testing.T) {
 		})
 	}
 }
+
+// The hosts are shared with the nacos registry of higress, which serves the services with them.
+func TestNacosClusterHost(t *testing.T) {
+	data, err := os.ReadFile("testdata/nacos_hosts.json")
+	if err != nil {
+		t.Fatalf("read nacos hosts error %v", err)
+	}
+	var cases []struct {
+		ServiceName string `json:"serviceName"`
+		Group       string `json:"group"`
+		NamespaceID string `json:"namespaceId"`
+		Host        string `json:"host"`
+	}
+	if err := json.Unmarshal(data, &cases); err != nil {
+		t.Fatalf("unmarshal nacos hosts error %v", err)
+	}
+	for _, c := range cases {
+		cluster := NacosCluster{ServiceName: c.ServiceName, Group: c.Group, NamespaceID: c.NamespaceID, Port: 8080}
+		assert.Equal(t, "outbound|8080||"+c.Host, cluster.ClusterName())
+	}
+}
+
+// The subset names are shared with the registries of higress, which name the subsets of the
+// versions with them.
+func TestSubsetName(t *testing.T) {
+	data, err := os.ReadFile("testdata/subset_names.json")
+	if err != nil {
+		t.Fatalf("read subset names error %v", err)
+	}
+	var cases []struct {
+		Version string `json:"version"`
+		Subset  string `json:"subset"`
+	}
+	if err := json.Unmarshal(data, &cases); err != nil {
+		t.Fatalf("unmarshal subset names error %v", err)
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.Subset, subsetName(c.Version))
+	}
+}